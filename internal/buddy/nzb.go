@@ -0,0 +1,44 @@
+package buddy
+
+import (
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/nzb_info"
+	"github.com/rodezfranco/stremthru/internal/peer"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+// BulkTrackNews records the store's usenet downloads in the local nzb index,
+// and pushes them to the peer.
+func BulkTrackNews(s store.Store, items []store.ListNewsDataItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	source := string(s.GetName().Code())
+	nInfos := make([]nzb_info.NZBInfoInsertData, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		if item.Hash == "" {
+			continue
+		}
+		nInfos = append(nInfos, nzb_info.NZBInfoInsertData{
+			Hash:   item.Hash,
+			Name:   item.Name,
+			Size:   item.Size,
+			Source: source,
+		})
+	}
+	nzb_info.Upsert(nInfos)
+
+	if config.HasPeer && config.PeerAuthToken != "" {
+		start := time.Now()
+		if _, err := Peer.PushNZBs(&peer.PushNZBsParams{Items: nInfos}); err != nil {
+			peerLog.Error("failed to push nzbs", "store", s.GetName(), "error", core.PackError(err), "duration", time.Since(start))
+		} else {
+			peerLog.Info("pushed nzbs", "store", s.GetName(), "count", len(nInfos), "duration", time.Since(start))
+		}
+	}
+}
@@ -226,15 +226,19 @@ func CheckMagnet(s store.Store, hashes []string, storeToken string, clientIp str
 
 	if config.HasPeer {
 		if config.LazyPeer {
-			storeCode := string(s.GetName().Code())
-			for _, hash := range staleOrMissingHashes {
-				worker_queue.MagnetCachePullerQueue.Queue(worker_queue.MagnetCachePullerQueueItem{
-					ClientIP:   clientIp,
-					Hash:       hash,
-					SId:        sid,
-					StoreCode:  storeCode,
-					StoreToken: storeToken,
-				})
+			// store token is not persisted in the queue, only the configured
+			// user's can be resolved by the worker. Others are not pulled.
+			if storeUser := config.StoreAuthToken.GetUser(string(s.GetName()), storeToken); storeUser != "" {
+				storeCode := string(s.GetName().Code())
+				for _, hash := range staleOrMissingHashes {
+					worker_queue.MagnetCachePullerQueue.Queue(worker_queue.MagnetCachePullerQueueItem{
+						ClientIP:  clientIp,
+						Hash:      hash,
+						SId:       sid,
+						StoreCode: storeCode,
+						StoreUser: storeUser,
+					})
+				}
 			}
 			return data, nil
 		}
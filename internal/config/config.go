@@ -75,6 +75,10 @@ var defaultValueByEnv = map[string]map[string]string{
 		"STREMTHRU_STREMIO_TORZ_PUBLIC_MAX_STORE_COUNT":    "3",
 		"STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_UPSTREAM_COUNT": "5",
 		"STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_STORE_COUNT":    "3",
+		"STREMTHRU_STREMIO_WRAP_STREAM_CACHE_TTL":          "5m",
+		"STREMTHRU_STREMIO_WRAP_STREAM_CACHE_STALE_TTL":    "1h",
+		"STREMTHRU_STREMIO_WRAP_STREAM_CACHE_ERROR_TTL":    "1m",
+		"STREMTHRU_STREMIO_WRAP_UPSTREAM_TIMEOUT":          "10s",
 	},
 }
 
@@ -125,6 +129,20 @@ func (m StoreAuthTokenMap) GetToken(user, store string) string {
 	return ""
 }
 
+// GetUser returns the user configured with `token` for `store`, it is empty
+// if the token is not configured.
+func (m StoreAuthTokenMap) GetUser(store, token string) string {
+	if token == "" {
+		return ""
+	}
+	for user, um := range m {
+		if um[store] == token {
+			return user
+		}
+	}
+	return ""
+}
+
 func (m StoreAuthTokenMap) setToken(user, store, token string) {
 	if _, ok := m[user]; !ok {
 		m[user] = make(map[string]string)
@@ -575,6 +593,14 @@ var StoreContentProxy = config.StoreContentProxy
 var StoreContentCachedStaleTime = config.StoreContentCachedStaleTime
 var StoreClientUserAgent = config.StoreClientUserAgent
 var ContentProxyConnectionLimit = config.ContentProxyConnectionLimit
+
+// ContentProxyRevocableLink makes every proxy link for authorized users
+// revocable, including the ones created by the addons.
+var ContentProxyRevocableLink = func() bool {
+	revocableLink := strings.ToLower(getEnv("STREMTHRU_CONTENT_PROXY_REVOCABLE_LINK"))
+	return revocableLink == "1" || revocableLink == "true"
+}()
+
 var InstanceId = strings.ReplaceAll(uuid.NewString(), "-", "")
 var IP = config.IP
 
@@ -612,6 +638,15 @@ func PrintConfig(state *AppState) {
 		tunnelIpByProxyHost = ipMap
 	}
 
+	var tunnelPoolStatusByStore map[string][]TunnelPoolProxyStatus
+	if len(StoreTunnelPool) > 0 {
+		statusByStore, err := IP.GetTunnelPoolIP()
+		if err != nil {
+			log.Printf("Failed to resolve Tunnel Pool IP: %v\n\n", err)
+		}
+		tunnelPoolStatusByStore = statusByStore
+	}
+
 	l := log.New(os.Stderr, "=", 0)
 	l.Println("====== StremThru =======")
 	l.Printf(" Time: %v\n", ServerStartTime.Format(time.RFC3339))
@@ -667,6 +702,21 @@ func PrintConfig(state *AppState) {
 	}
 	l.Println()
 
+	if len(StoreTunnelPool) > 0 {
+		l.Println(" Tunnel Pool:")
+		for store, pool := range StoreTunnelPool {
+			l.Println("   " + store + " (" + string(pool.Strategy) + "):")
+			for _, status := range tunnelPoolStatusByStore[store] {
+				ip := status.IP
+				if ip == "" {
+					ip = "(unresolved)"
+				}
+				l.Println("     [" + status.Host + "]: " + ip)
+			}
+		}
+		l.Println()
+	}
+
 	l.Printf("   Base URL: %s\n", BaseURL.String())
 	l.Println()
 
@@ -689,6 +739,12 @@ func PrintConfig(state *AppState) {
 			if cpcl := ContentProxyConnectionLimit.Get(user); cpcl > 0 {
 				l.Println("       content_proxy_connection_limit: " + strconv.FormatUint(uint64(cpcl), 10))
 			}
+			if quota := ContentProxyQuota.GetMonthlyQuota(user); quota > 0 {
+				l.Println("       content_proxy_monthly_quota: " + util.ToSize(quota))
+			}
+			if rateLimit := ContentProxyQuota.GetRateLimit(user); rateLimit > 0 {
+				l.Println("       content_proxy_rate_limit: " + util.ToSize(rateLimit) + "/s")
+			}
 		}
 		l.Println()
 	}
@@ -710,6 +766,12 @@ func PrintConfig(state *AppState) {
 				}
 			}
 		}
+		if StoreTunnelPool.Get(string(store)) != nil {
+			if storeConfig != "" {
+				storeConfig += ","
+			}
+			storeConfig += "tunnel_pool"
+		}
 		if storeConfig != "" {
 			storeConfig = " (" + storeConfig + ")"
 		}
@@ -886,5 +948,18 @@ func PrintConfig(state *AppState) {
 	l.Println("   " + DataDir)
 	l.Println()
 
+	if ContentProxyCache.IsEnabled() {
+		l.Println(" Content Proxy Cache:")
+		l.Println("   " + ContentProxyCache.Dir + " (" + util.ToSize(ContentProxyCache.MaxSize) + ")")
+		l.Println()
+	}
+
+	if ContentProxyHLS.IsEnabled() {
+		l.Println(" Content Proxy HLS:")
+		l.Println("   ffmpeg: " + ContentProxyHLS.FFmpegPath)
+		l.Println("   max sessions: " + strconv.Itoa(ContentProxyHLS.MaxSessions))
+		l.Println()
+	}
+
 	l.Print("========================\n\n")
 }
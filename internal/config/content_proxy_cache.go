@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/rodezfranco/stremthru/internal/util"
+)
+
+type contentProxyCacheConfig struct {
+	Dir     string
+	MaxSize int64
+}
+
+func (c contentProxyCacheConfig) IsEnabled() bool {
+	return c.MaxSize > 0
+}
+
+func parseContentProxyCache() contentProxyCacheConfig {
+	conf := contentProxyCacheConfig{
+		Dir: filepath.Join(DataDir, "content_proxy_cache"),
+	}
+	if size := getEnv("STREMTHRU_CONTENT_PROXY_CACHE_SIZE"); size != "" {
+		conf.MaxSize = util.ToBytes(size)
+		if conf.MaxSize < 0 {
+			log.Fatalf("Invalid content proxy cache size: %s", size)
+		}
+	}
+	return conf
+}
+
+var ContentProxyCache = parseContentProxyCache()
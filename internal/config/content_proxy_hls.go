@@ -0,0 +1,45 @@
+package config
+
+import (
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+type contentProxyHLSConfig struct {
+	FFmpegPath  string
+	Dir         string
+	MaxSessions int
+	IdleTimeout time.Duration
+}
+
+func (c contentProxyHLSConfig) IsEnabled() bool {
+	return c.FFmpegPath != ""
+}
+
+func parseContentProxyHLS() contentProxyHLSConfig {
+	conf := contentProxyHLSConfig{
+		Dir:         filepath.Join(DataDir, "content_proxy_hls"),
+		MaxSessions: 2,
+		IdleTimeout: 2 * time.Minute,
+	}
+	if ffmpeg := getEnv("STREMTHRU_CONTENT_PROXY_HLS_FFMPEG"); ffmpeg != "" {
+		path, err := exec.LookPath(ffmpeg)
+		if err != nil {
+			log.Fatalf("Invalid content proxy hls ffmpeg: %v", err)
+		}
+		conf.FFmpegPath = path
+	}
+	if maxSessions := getEnv("STREMTHRU_CONTENT_PROXY_HLS_MAX_SESSIONS"); maxSessions != "" {
+		count, err := strconv.Atoi(maxSessions)
+		if err != nil || count < 1 {
+			log.Fatalf("Invalid content proxy hls max sessions: %s", maxSessions)
+		}
+		conf.MaxSessions = count
+	}
+	return conf
+}
+
+var ContentProxyHLS = parseContentProxyHLS()
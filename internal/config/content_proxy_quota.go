@@ -0,0 +1,65 @@
+package config
+
+import (
+	"log"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/util"
+)
+
+type contentProxyQuotaConfig struct {
+	monthlyQuotaByUser map[string]int64
+	rateLimitByUser    map[string]int64
+}
+
+func (c contentProxyQuotaConfig) get(m map[string]int64, user string) int64 {
+	if v, ok := m[user]; ok {
+		return v
+	}
+	if user != "*" {
+		return m["*"]
+	}
+	return 0
+}
+
+// GetMonthlyQuota returns the monthly transfer quota in bytes, 0 means no quota.
+func (c contentProxyQuotaConfig) GetMonthlyQuota(user string) int64 {
+	return c.get(c.monthlyQuotaByUser, user)
+}
+
+// GetRateLimit returns the per connection rate limit in bytes per second,
+// 0 means no rate limit.
+func (c contentProxyQuotaConfig) GetRateLimit(user string) int64 {
+	return c.get(c.rateLimitByUser, user)
+}
+
+func parseContentProxySizeByUser(name, value string) map[string]int64 {
+	sizeByUser := map[string]int64{}
+	list := strings.FieldsFunc(value, func(c rune) bool {
+		return c == ','
+	})
+	for _, item := range list {
+		user, sizeStr, ok := strings.Cut(item, ":")
+		if !ok {
+			log.Fatalf("Invalid %s: %s", name, item)
+		}
+		size := int64(0)
+		if sizeStr != "0" {
+			size = util.ToBytes(sizeStr)
+			if size < 0 {
+				log.Fatalf("Invalid %s for user %s: %s", name, user, sizeStr)
+			}
+		}
+		sizeByUser[user] = size
+	}
+	return sizeByUser
+}
+
+func parseContentProxyQuota() contentProxyQuotaConfig {
+	return contentProxyQuotaConfig{
+		monthlyQuotaByUser: parseContentProxySizeByUser("content proxy monthly quota", getEnv("STREMTHRU_CONTENT_PROXY_MONTHLY_QUOTA")),
+		rateLimitByUser:    parseContentProxySizeByUser("content proxy rate limit", getEnv("STREMTHRU_CONTENT_PROXY_RATE_LIMIT")),
+	}
+}
+
+var ContentProxyQuota = parseContentProxyQuota()
@@ -210,19 +210,19 @@ func (stc StoreTunnelConfigMap) GetTypeForStream(name string) TunnelType {
 	return TUNNEL_TYPE_NONE
 }
 
+var contentHostnameByStore = map[string]string{
+	"alldebrid":  "debrid.it",
+	"debridlink": "debrid.link",
+	"premiumize": "energycdn.com",
+	"realdebrid": "download.real-debrid.com",
+	"torbox":     "tb-cdn.st",
+}
+
 func parseStoreTunnel(storeTunnel string, tunnelMap TunnelMap) StoreTunnelConfigMap {
 	storeTunnelList := strings.FieldsFunc(storeTunnel, func(c rune) bool {
 		return c == ','
 	})
 
-	contentHostnameByStore := map[string]string{
-		"alldebrid":  "debrid.it",
-		"debridlink": "debrid.link",
-		"premiumize": "energycdn.com",
-		"realdebrid": "download.real-debrid.com",
-		"torbox":     "tb-cdn.st",
-	}
-
 	storeTunnelMap := make(StoreTunnelConfigMap)
 	for _, storeTunnel := range storeTunnelList {
 		if store, tunnel, ok := strings.Cut(storeTunnel, ":"); ok {
@@ -396,3 +396,19 @@ func (ipr *IPResolver) GetTunnelIPByHostname() (map[string]string, error) {
 	err := ipr.resolveTunnelIPMap()
 	return ipr.proxyIpByHostname, err
 }
+
+// GetTunnelPoolIP returns the status of each proxy, including the resolved
+// ip, by store for the configured tunnel pools.
+func (ipr *IPResolver) GetTunnelPoolIP() (map[string][]TunnelPoolProxyStatus, error) {
+	errs := []error{}
+	statusByStore := map[string][]TunnelPoolProxyStatus{}
+	for store, pool := range StoreTunnelPool {
+		if !pool.isChecked() {
+			if err := pool.CheckHealth(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		statusByStore[store] = pool.GetStatus()
+	}
+	return statusByStore, errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+type storeQBittorrentConfig struct {
+	url               *url.URL
+	downloadDir       string
+	clientDownloadDir string
+}
+
+// GetURL returns the Web UI URL, it is nil if not configured.
+func (c storeQBittorrentConfig) GetURL() *url.URL {
+	return c.url
+}
+
+// IsEnabled reports whether the qBittorrent download directory is
+// accessible to StremThru, which is needed to serve the files.
+func (c storeQBittorrentConfig) IsEnabled() bool {
+	return c.downloadDir != ""
+}
+
+func (c storeQBittorrentConfig) GetDownloadDir() string {
+	return c.downloadDir
+}
+
+// GetRelativePath converts the path reported by qBittorrent to the path
+// relative to the download directory.
+func (c storeQBittorrentConfig) GetRelativePath(clientPath string) (string, error) {
+	if !c.IsEnabled() {
+		return "", errors.New("download directory not configured")
+	}
+	relPath, err := filepath.Rel(c.clientDownloadDir, filepath.Clean(clientPath))
+	if err != nil {
+		return "", err
+	}
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", errors.New("path outside download directory: " + clientPath)
+	}
+	return relPath, nil
+}
+
+func parseStoreQBittorrent() storeQBittorrentConfig {
+	conf := storeQBittorrentConfig{}
+	if webUIUrl := getEnv("STREMTHRU_STORE_QBITTORRENT_URL"); webUIUrl != "" {
+		u, err := url.Parse(webUIUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+			log.Fatalf("Invalid qbittorrent url: %s", webUIUrl)
+		}
+		conf.url = u
+	}
+	downloadDir := getEnv("STREMTHRU_STORE_QBITTORRENT_DOWNLOAD_DIR")
+	if downloadDir == "" {
+		return conf
+	}
+	dir, err := filepath.Abs(downloadDir)
+	if err != nil {
+		log.Fatalf("failed to resolve qbittorrent download directory: %v", err)
+	}
+	conf.downloadDir = dir
+	conf.clientDownloadDir = dir
+	if clientDir := getEnv("STREMTHRU_STORE_QBITTORRENT_CLIENT_DOWNLOAD_DIR"); clientDir != "" {
+		conf.clientDownloadDir = filepath.Clean(clientDir)
+	}
+	return conf
+}
+
+var StoreQBittorrent = parseStoreQBittorrent()
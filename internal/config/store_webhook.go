@@ -0,0 +1,57 @@
+package config
+
+import (
+	"log"
+	"net/url"
+	"strings"
+)
+
+type storeWebhookConfig struct {
+	urlByUser map[string]string
+	secret    string
+}
+
+func (c storeWebhookConfig) IsEnabled() bool {
+	return len(c.urlByUser) > 0
+}
+
+func (c storeWebhookConfig) GetURL(user string) string {
+	if url, ok := c.urlByUser[user]; ok {
+		return url
+	}
+	if user != "*" {
+		return c.urlByUser["*"]
+	}
+	return ""
+}
+
+func (c storeWebhookConfig) GetSecret() string {
+	return c.secret
+}
+
+func parseStoreWebhook() storeWebhookConfig {
+	conf := storeWebhookConfig{
+		urlByUser: map[string]string{},
+		secret:    getEnv("STREMTHRU_STORE_WEBHOOK_SECRET"),
+	}
+	webhookList := strings.FieldsFunc(getEnv("STREMTHRU_STORE_WEBHOOK"), func(c rune) bool {
+		return c == ','
+	})
+	for _, webhook := range webhookList {
+		user, webhookUrl, ok := strings.Cut(webhook, ":")
+		if !ok {
+			log.Fatalf("Invalid store webhook: %s", webhook)
+		}
+		u, err := url.Parse(webhookUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatalf("Invalid store webhook url for user %s", user)
+		}
+		conf.urlByUser[user] = u.String()
+	}
+	if conf.IsEnabled() && conf.secret == "" {
+		log.Fatal("STREMTHRU_STORE_WEBHOOK_SECRET is required with STREMTHRU_STORE_WEBHOOK")
+	}
+	return conf
+}
+
+var StoreWebhook = parseStoreWebhook()
@@ -2,6 +2,7 @@ package config
 
 import (
 	"strings"
+	"time"
 
 	"github.com/rodezfranco/stremthru/internal/util"
 )
@@ -10,6 +11,10 @@ type stremioConfigList struct {
 	PublicMaxListCount int
 }
 
+type stremioConfigStore struct {
+	SubtitleDir string
+}
+
 type stremioConfigTorz struct {
 	LazyPull            bool
 	PublicMaxStoreCount int
@@ -18,12 +23,17 @@ type stremioConfigTorz struct {
 type stremioConfigWrap struct {
 	PublicMaxUpstreamCount int
 	PublicMaxStoreCount    int
+	StreamCacheTTL         time.Duration
+	StreamCacheStaleTTL    time.Duration
+	StreamCacheErrorTTL    time.Duration
+	UpstreamTimeout        time.Duration
 }
 
 type StremioConfig struct {
-	List stremioConfigList
-	Torz stremioConfigTorz
-	Wrap stremioConfigWrap
+	List  stremioConfigList
+	Store stremioConfigStore
+	Torz  stremioConfigTorz
+	Wrap  stremioConfigWrap
 }
 
 func parseStremio() StremioConfig {
@@ -31,6 +41,9 @@ func parseStremio() StremioConfig {
 		List: stremioConfigList{
 			PublicMaxListCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_LIST_PUBLIC_MAX_LIST_COUNT")),
 		},
+		Store: stremioConfigStore{
+			SubtitleDir: getEnv("STREMTHRU_STREMIO_STORE_SUBTITLE_DIR"),
+		},
 		Torz: stremioConfigTorz{
 			LazyPull:            strings.ToLower(getEnv("STREMTHRU_STREMIO_TORZ_LAZY_PULL")) == "true",
 			PublicMaxStoreCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_TORZ_PUBLIC_MAX_STORE_COUNT")),
@@ -38,6 +51,10 @@ func parseStremio() StremioConfig {
 		Wrap: stremioConfigWrap{
 			PublicMaxUpstreamCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_UPSTREAM_COUNT")),
 			PublicMaxStoreCount:    util.MustParseInt(getEnv("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_STORE_COUNT")),
+			StreamCacheTTL:         mustParseDuration("stremio wrap stream cache ttl", getEnv("STREMTHRU_STREMIO_WRAP_STREAM_CACHE_TTL"), 0),
+			StreamCacheStaleTTL:    mustParseDuration("stremio wrap stream cache stale ttl", getEnv("STREMTHRU_STREMIO_WRAP_STREAM_CACHE_STALE_TTL"), 0),
+			StreamCacheErrorTTL:    mustParseDuration("stremio wrap stream cache error ttl", getEnv("STREMTHRU_STREMIO_WRAP_STREAM_CACHE_ERROR_TTL"), 0),
+			UpstreamTimeout:        mustParseDuration("stremio wrap upstream timeout", getEnv("STREMTHRU_STREMIO_WRAP_UPSTREAM_TIMEOUT"), time.Second),
 		},
 	}
 	return stremio
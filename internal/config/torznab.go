@@ -0,0 +1,47 @@
+package config
+
+import (
+	"log"
+	"net/url"
+	"strings"
+)
+
+type TorznabIndexerConfig struct {
+	Name string
+	URL  *url.URL
+}
+
+type torznabConfig struct {
+	Indexers []TorznabIndexerConfig
+}
+
+func parseTorznab() torznabConfig {
+	conf := torznabConfig{
+		Indexers: []TorznabIndexerConfig{},
+	}
+	indexerList := strings.FieldsFunc(getEnv("STREMTHRU_TORZNAB_INDEXER"), func(c rune) bool {
+		return c == ','
+	})
+	seenName := map[string]struct{}{}
+	for _, indexer := range indexerList {
+		name, indexerUrl, ok := strings.Cut(indexer, ":")
+		if !ok || name == "" {
+			log.Fatalf("Invalid torznab indexer: %s, expected: name:url", indexer)
+		}
+		if _, seen := seenName[name]; seen {
+			log.Fatalf("Duplicate torznab indexer: %s", name)
+		}
+		seenName[name] = struct{}{}
+		u, err := url.Parse(indexerUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatalf("Invalid torznab indexer url for %s", name)
+		}
+		conf.Indexers = append(conf.Indexers, TorznabIndexerConfig{
+			Name: name,
+			URL:  u,
+		})
+	}
+	return conf
+}
+
+var Torznab = parseTorznab()
@@ -0,0 +1,422 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TunnelPoolStrategy string
+
+const (
+	TunnelPoolStrategyRoundRobin TunnelPoolStrategy = "round_robin"
+	TunnelPoolStrategySticky     TunnelPoolStrategy = "sticky"
+)
+
+type tunnelPoolProxy struct {
+	url       url.URL
+	ip        string
+	failedAt  time.Time
+	checkedAt time.Time
+}
+
+func (p *tunnelPoolProxy) isHealthy() bool {
+	return p.failedAt.IsZero()
+}
+
+type TunnelPool struct {
+	Store    string
+	Strategy TunnelPoolStrategy
+	proxies  []*tunnelPoolProxy
+	next     atomic.Uint64
+	m        sync.RWMutex
+}
+
+type tunnelPoolContextKey struct{}
+type tunnelPoolProxyContextKey struct{}
+
+// WithTunnelPoolKey sets the key used by sticky tunnel pool to select the
+// proxy for requests made with the returned context.
+func WithTunnelPoolKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, tunnelPoolContextKey{}, key)
+}
+
+func getTunnelPoolKey(ctx context.Context) string {
+	if key, ok := ctx.Value(tunnelPoolContextKey{}).(string); ok {
+		return key
+	}
+	return ""
+}
+
+func getStickyScore(key string, proxy *tunnelPoolProxy) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(proxy.url.Host))
+	return h.Sum64()
+}
+
+// pick selects a proxy, skipping the ones in `exclude`. Unhealthy proxies
+// are only used if there are no healthy ones left.
+func (p *TunnelPool) pick(key string, exclude []*tunnelPoolProxy) *tunnelPoolProxy {
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	healthy := []*tunnelPoolProxy{}
+	unhealthy := []*tunnelPoolProxy{}
+	for _, proxy := range p.proxies {
+		excluded := false
+		for _, e := range exclude {
+			if e == proxy {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+		if proxy.isHealthy() {
+			healthy = append(healthy, proxy)
+		} else {
+			unhealthy = append(unhealthy, proxy)
+		}
+	}
+
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = unhealthy
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if p.Strategy == TunnelPoolStrategySticky {
+		// rendezvous hashing, so that a key sticks to the same proxy as
+		// long as it is healthy, regardless of other proxies' health. Empty
+		// key sticks to a proxy too, so that the link generated for the
+		// store is streamed through the same proxy.
+		var picked *tunnelPoolProxy
+		var pickedScore uint64
+		for _, proxy := range candidates {
+			if score := getStickyScore(key, proxy); picked == nil || score > pickedScore {
+				picked, pickedScore = proxy, score
+			}
+		}
+		return picked
+	}
+
+	return candidates[(p.next.Add(1)-1)%uint64(len(candidates))]
+}
+
+func (p *TunnelPool) markFailed(proxy *tunnelPoolProxy, err error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if proxy.isHealthy() {
+		log.Printf("[tunnel_pool] %s: proxy %s failed: %v\n", p.Store, proxy.url.Redacted(), err)
+	}
+	proxy.failedAt = time.Now()
+}
+
+// CheckHealth resolves the ip for every proxy in the pool, proxies that
+// fail are skipped for selection until they pass a later check.
+func (p *TunnelPool) CheckHealth() error {
+	errs := []error{}
+	for _, proxy := range p.proxies {
+		client := getHTTPClientWithProxy(&proxy.url)
+		client.Timeout = 15 * time.Second
+		ip, err := getIp(client)
+
+		p.m.Lock()
+		proxy.checkedAt = time.Now()
+		if err != nil {
+			if proxy.isHealthy() {
+				proxy.failedAt = proxy.checkedAt
+			}
+			errs = append(errs, errors.New(p.Store+": "+proxy.url.Redacted()+": "+err.Error()))
+		} else {
+			proxy.ip = ip
+			proxy.failedAt = time.Time{}
+		}
+		p.m.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (p *TunnelPool) isChecked() bool {
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	for _, proxy := range p.proxies {
+		if proxy.checkedAt.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// GetIP returns the ip of the proxy that would be used for `key`, it is
+// empty for round robin pool as the proxy changes for every request.
+func (p *TunnelPool) GetIP(key string) string {
+	if p.Strategy != TunnelPoolStrategySticky {
+		return ""
+	}
+	proxy := p.pick(key, nil)
+	if proxy == nil {
+		return ""
+	}
+	p.m.RLock()
+	defer p.m.RUnlock()
+	return proxy.ip
+}
+
+type TunnelPoolProxyStatus struct {
+	Host    string `json:"host"`
+	IP      string `json:"ip"`
+	Healthy bool   `json:"healthy"`
+}
+
+func (p *TunnelPool) GetStatus() []TunnelPoolProxyStatus {
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	status := make([]TunnelPoolProxyStatus, len(p.proxies))
+	for i, proxy := range p.proxies {
+		status[i] = TunnelPoolProxyStatus{
+			Host:    proxy.url.Host,
+			IP:      proxy.ip,
+			Healthy: proxy.isHealthy(),
+		}
+	}
+	return status
+}
+
+// RoundTripper uses the pool's proxies for every request made through
+// `transport`, and retries with the next proxy when one fails.
+func (p *TunnelPool) RoundTripper(transport *http.Transport) http.RoundTripper {
+	return newTunnelPoolTransport(transport, func(r *http.Request) *TunnelPool {
+		return p
+	})
+}
+
+type tunnelPoolTransport struct {
+	base    *http.Transport
+	getPool func(r *http.Request) *TunnelPool
+}
+
+type tunnelPoolConnectError struct {
+	statusCode int
+}
+
+func (e *tunnelPoolConnectError) Error() string {
+	return "tunnel pool: proxy responded to CONNECT with status " + strconv.Itoa(e.statusCode)
+}
+
+// isTunnelPoolProxyError reports if err is caused by the proxy itself, i.e.
+// connecting to it or the CONNECT request failed. Errors from the
+// destination do not mean the proxy is unhealthy.
+func isTunnelPoolProxyError(err error) bool {
+	var connectErr *tunnelPoolConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks")
+	}
+	return false
+}
+
+func newTunnelPoolTransport(transport *http.Transport, getPool func(r *http.Request) *TunnelPool) *tunnelPoolTransport {
+	onProxyConnectResponse := transport.OnProxyConnectResponse
+	transport.OnProxyConnectResponse = func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+		if connectRes.StatusCode != http.StatusOK {
+			return &tunnelPoolConnectError{statusCode: connectRes.StatusCode}
+		}
+		if onProxyConnectResponse != nil {
+			return onProxyConnectResponse(ctx, proxyURL, connectReq, connectRes)
+		}
+		return nil
+	}
+	fallbackProxy := transport.Proxy
+	transport.Proxy = func(r *http.Request) (*url.URL, error) {
+		if proxy, ok := r.Context().Value(tunnelPoolProxyContextKey{}).(*url.URL); ok {
+			return proxy, nil
+		}
+		if fallbackProxy == nil {
+			return nil, nil
+		}
+		return fallbackProxy(r)
+	}
+	return &tunnelPoolTransport{base: transport, getPool: getPool}
+}
+
+func (t *tunnelPoolTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	pool := t.getPool(r)
+	if pool == nil {
+		return t.base.RoundTrip(r)
+	}
+
+	ctx := r.Context()
+	key := getTunnelPoolKey(ctx)
+	tried := []*tunnelPoolProxy{}
+	req := r
+	for {
+		proxy := pool.pick(key, tried)
+		if proxy == nil {
+			return nil, errors.New("tunnel pool: no proxy available")
+		}
+
+		res, err := t.base.RoundTrip(req.WithContext(context.WithValue(ctx, tunnelPoolProxyContextKey{}, &proxy.url)))
+		if err == nil {
+			return res, nil
+		}
+		if ctx.Err() != nil || !isTunnelPoolProxyError(err) {
+			return nil, err
+		}
+
+		pool.markFailed(proxy, err)
+		tried = append(tried, proxy)
+
+		if len(tried) == len(pool.proxies) {
+			return nil, err
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			if r.GetBody == nil {
+				return nil, err
+			}
+			body, bodyErr := r.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req = r.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+type TunnelPoolMap map[string]*TunnelPool
+
+func (tpm TunnelPoolMap) Get(store string) *TunnelPool {
+	if pool, ok := tpm[store]; ok {
+		return pool
+	}
+	return nil
+}
+
+// IsEnabledForStream reports if the store's pool is used for streams, it
+// requires the store's content hostname to be known. Round robin pool is
+// used only for API, as the links locked to the ip they are generated from
+// would be streamed through a different proxy.
+func (tpm TunnelPoolMap) IsEnabledForStream(store string) bool {
+	_, hasHostname := contentHostnameByStore[store]
+	pool := tpm.Get(store)
+	return hasHostname && pool != nil && pool.Strategy == TunnelPoolStrategySticky
+}
+
+func (tpm TunnelPoolMap) getByHostname(hostname string) *TunnelPool {
+	for store, pool := range tpm {
+		contentHostname, ok := contentHostnameByStore[store]
+		if !ok {
+			continue
+		}
+		if pool.Strategy != TunnelPoolStrategySticky {
+			continue
+		}
+		if hostname == contentHostname || strings.HasSuffix(hostname, "."+contentHostname) {
+			return pool
+		}
+	}
+	return nil
+}
+
+// RoundTripper uses the pool of the store for requests to the store's
+// content hostname, other requests go through `transport` as is.
+func (tpm TunnelPoolMap) RoundTripper(transport *http.Transport) http.RoundTripper {
+	if len(tpm) == 0 {
+		return transport
+	}
+	return newTunnelPoolTransport(transport, func(r *http.Request) *TunnelPool {
+		return tpm.getByHostname(r.URL.Hostname())
+	})
+}
+
+func (tpm TunnelPoolMap) CheckHealth() error {
+	errs := []error{}
+	for _, pool := range tpm {
+		if err := pool.CheckHealth(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseStoreTunnelPool(storeTunnelPool string) TunnelPoolMap {
+	tunnelPoolMap := make(TunnelPoolMap)
+
+	storeTunnelPoolList := strings.FieldsFunc(storeTunnelPool, func(c rune) bool {
+		return c == ','
+	})
+
+	for _, storeTunnelPool := range storeTunnelPoolList {
+		store, rest, ok := strings.Cut(storeTunnelPool, ":")
+		if !ok {
+			log.Fatalf("Invalid store tunnel pool: %s", storeTunnelPool)
+		}
+		strategy, proxies, ok := strings.Cut(rest, ":")
+		if !ok {
+			log.Fatalf("Invalid store tunnel pool: %s", storeTunnelPool)
+		}
+
+		pool := &TunnelPool{
+			Store:    store,
+			Strategy: TunnelPoolStrategy(strategy),
+		}
+		switch pool.Strategy {
+		case TunnelPoolStrategyRoundRobin, TunnelPoolStrategySticky:
+		default:
+			log.Fatalf("Invalid store tunnel pool strategy: %s", strategy)
+		}
+
+		for proxy := range strings.SplitSeq(proxies, "|") {
+			u, err := url.Parse(proxy)
+			if err != nil || u.Host == "" {
+				log.Fatalf("Invalid store tunnel pool proxy: %s", proxy)
+			}
+			pool.proxies = append(pool.proxies, &tunnelPoolProxy{url: *u})
+		}
+
+		tunnelPoolMap[store] = pool
+	}
+
+	return tunnelPoolMap
+}
+
+var StoreTunnelPool = func() TunnelPoolMap {
+	return parseStoreTunnelPool(getEnv("STREMTHRU_STORE_TUNNEL_POOL"))
+}()
+
+// GetStoreHTTPClient returns the client for store's API, it uses the store's
+// tunnel pool if configured and tunnel is enabled for API.
+func GetStoreHTTPClient(store string) *http.Client {
+	tunnelType := StoreTunnel.GetTypeForAPI(store)
+	pool := StoreTunnelPool.Get(store)
+	if pool == nil || tunnelType != TUNNEL_TYPE_FORCED {
+		return GetHTTPClient(tunnelType)
+	}
+	transport := DefaultHTTPTransport.Clone()
+	transport.Proxy = Tunnel.GetProxy(tunnelType)
+	return &http.Client{
+		Transport: pool.RoundTripper(transport),
+		Timeout:   90 * time.Second,
+	}
+}
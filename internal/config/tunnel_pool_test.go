@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TunnelPoolTestSuite struct {
+	suite.Suite
+}
+
+func (s *TunnelPoolTestSuite) TestParse() {
+	pool := parseStoreTunnelPool("realdebrid:sticky:http://a:1080|http://user:pass@b:1080,torbox:round_robin:http://c:1080")
+	s.Len(pool, 2)
+	s.Equal(TunnelPoolStrategySticky, pool.Get("realdebrid").Strategy)
+	s.Len(pool.Get("realdebrid").proxies, 2)
+	s.Equal("b:1080", pool.Get("realdebrid").proxies[1].url.Host)
+	s.Nil(pool.Get("alldebrid"))
+
+	s.Equal(pool.Get("realdebrid"), pool.getByHostname("download.real-debrid.com"))
+	s.Equal(pool.Get("realdebrid"), pool.getByHostname("x.download.real-debrid.com"))
+	s.Nil(pool.getByHostname("real-debrid.com"))
+
+	s.True(pool.IsEnabledForStream("realdebrid"))
+	s.False(pool.IsEnabledForStream("torbox"), "round robin is only used for api")
+	s.Nil(pool.getByHostname("store-1.tb-cdn.st"))
+}
+
+func (s *TunnelPoolTestSuite) TestIsProxyError() {
+	s.True(isTunnelPoolProxyError(&net.OpError{Op: "proxyconnect", Err: io.EOF}))
+	s.True(isTunnelPoolProxyError(&net.OpError{Op: "socks connect", Err: io.EOF}))
+	s.True(isTunnelPoolProxyError(fmt.Errorf("wrapped: %w", &tunnelPoolConnectError{statusCode: 407})))
+	s.False(isTunnelPoolProxyError(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	s.False(isTunnelPoolProxyError(io.ErrUnexpectedEOF))
+}
+
+func (s *TunnelPoolTestSuite) TestPick() {
+	pool := parseStoreTunnelPool("realdebrid:sticky:http://a:1080|http://b:1080|http://c:1080").Get("realdebrid")
+
+	picked := pool.pick("user", nil)
+	for range 5 {
+		s.Equal(picked, pool.pick("user", nil))
+	}
+
+	pool.markFailed(picked, io.EOF)
+	failover := pool.pick("user", nil)
+	s.NotEqual(picked, failover)
+	s.Equal(failover, pool.pick("user", nil))
+
+	picked.failedAt = time.Time{}
+	s.Equal(picked, pool.pick("user", nil))
+
+	pool.Strategy = TunnelPoolStrategyRoundRobin
+	hosts := map[string]bool{}
+	for range 3 {
+		hosts[pool.pick("user", nil).url.Host] = true
+	}
+	s.Len(hosts, 3)
+
+	for _, proxy := range pool.proxies {
+		pool.markFailed(proxy, io.EOF)
+	}
+	s.NotNil(pool.pick("", nil))
+	pool.Strategy = TunnelPoolStrategySticky
+	s.Equal(pool.pick("", nil), pool.pick("", nil), "empty key sticks too")
+	s.Nil(pool.pick("", pool.proxies))
+}
+
+func (s *TunnelPoolTestSuite) TestRoundTripFailover() {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.String()))
+	}))
+	defer up.Close()
+
+	pool := parseStoreTunnelPool("realdebrid:round_robin:" + down.URL + "|" + up.URL).Get("realdebrid")
+	client := &http.Client{Transport: pool.RoundTripper(DefaultHTTPTransport.Clone())}
+
+	for range 2 {
+		res, err := client.Get("http://api.real-debrid.com/rest")
+		s.NoError(err)
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		s.Equal("http://api.real-debrid.com/rest", string(body))
+	}
+
+	status := pool.GetStatus()
+	s.False(status[0].Healthy)
+	s.True(status[1].Healthy)
+}
+
+func TestTunnelPool(t *testing.T) {
+	suite.Run(t, new(TunnelPoolTestSuite))
+}
@@ -0,0 +1,343 @@
+package content_proxy_cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("content_proxy_cache")
+
+const DefaultChunkSize = 4 * 1024 * 1024
+
+const metaFileName = "meta.json"
+
+type Meta struct {
+	Size               int64  `json:"size"`
+	ETag               string `json:"etag,omitempty"`
+	LastModified       string `json:"last_modified,omitempty"`
+	ContentType        string `json:"content_type,omitempty"`
+	ContentDisposition string `json:"content_disposition,omitempty"`
+}
+
+type chunkRef struct {
+	key  string
+	idx  int64
+	size int64
+}
+
+type entry struct {
+	meta   *Meta
+	chunks map[int64]*list.Element
+}
+
+// Cache stores the content in fixed size chunks on disk, under
+// `<dir>/<key>/<chunk_index>`. Chunks are evicted in LRU order when the
+// total size goes over the limit.
+type Cache struct {
+	dir       string
+	maxSize   int64
+	chunkSize int64
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	lru     *list.List
+	size    int64
+}
+
+func New(dir string, maxSize int64, chunkSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:       dir,
+		maxSize:   maxSize,
+		chunkSize: chunkSize,
+		entries:   map[string]*entry{},
+		lru:       list.New(),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Default is nil when the cache is disabled.
+var Default = func() *Cache {
+	if !config.ContentProxyCache.IsEnabled() {
+		return nil
+	}
+	c, err := New(config.ContentProxyCache.Dir, config.ContentProxyCache.MaxSize, DefaultChunkSize)
+	if err != nil {
+		log.Error("failed to initialize content proxy cache", "error", err)
+		return nil
+	}
+	return c
+}()
+
+func GetKey(link string) string {
+	hash := sha256.Sum256([]byte(link))
+	return hex.EncodeToString(hash[:16])
+}
+
+func (c *Cache) getEntryDir(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+func (c *Cache) getChunkPath(key string, idx int64) string {
+	return filepath.Join(c.dir, key, strconv.FormatInt(idx, 10))
+}
+
+// load rebuilds the index from disk, the least recently modified chunks
+// end up at the back of LRU.
+func (c *Cache) load() error {
+	dirs, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type loadedChunk struct {
+		chunkRef
+		modTime time.Time
+	}
+	chunks := []loadedChunk{}
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		key := d.Name()
+		meta := &Meta{}
+		blob, err := os.ReadFile(filepath.Join(c.dir, key, metaFileName))
+		if err == nil {
+			err = json.Unmarshal(blob, meta)
+		}
+		if err != nil {
+			log.Warn("dropping cache entry without meta", "key", key, "error", err)
+			os.RemoveAll(c.getEntryDir(key))
+			continue
+		}
+		c.entries[key] = &entry{meta: meta, chunks: map[int64]*list.Element{}}
+
+		files, err := os.ReadDir(c.getEntryDir(key))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.IsDir() || f.Name() == metaFileName {
+				continue
+			}
+			idx, err := strconv.ParseInt(f.Name(), 10, 64)
+			info, ierr := f.Info()
+			if err != nil || ierr != nil {
+				// left over temp file
+				os.Remove(filepath.Join(c.dir, key, f.Name()))
+				continue
+			}
+			chunks = append(chunks, loadedChunk{
+				chunkRef: chunkRef{key: key, idx: idx, size: info.Size()},
+				modTime:  info.ModTime(),
+			})
+		}
+	}
+
+	slices.SortFunc(chunks, func(a, b loadedChunk) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range chunks {
+		ref := chunks[i].chunkRef
+		c.entries[ref.key].chunks[ref.idx] = c.lru.PushFront(&ref)
+		c.size += ref.size
+	}
+	c.evict()
+
+	return nil
+}
+
+func (c *Cache) GetMeta(key string) *Meta {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		meta := *e.meta
+		return &meta
+	}
+	return nil
+}
+
+func (c *Cache) SetMeta(key string, meta *Meta) error {
+	blob, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.getEntryDir(key), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(c.getEntryDir(key), metaFileName), blob); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.meta = meta
+	} else {
+		c.entries[key] = &entry{meta: meta, chunks: map[int64]*list.Element{}}
+	}
+	return nil
+}
+
+// Purge drops the entry, used when the upstream content changed.
+func (c *Cache) Purge(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeEntry(key)
+}
+
+func (c *Cache) removeEntry(key string) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	for _, elem := range e.chunks {
+		c.size -= elem.Value.(*chunkRef).size
+		c.lru.Remove(elem)
+	}
+	delete(c.entries, key)
+	if err := os.RemoveAll(c.getEntryDir(key)); err != nil {
+		log.Error("failed to remove cache entry", "error", err, "key", key)
+	}
+}
+
+func (c *Cache) HasChunk(key string, idx int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	_, ok = e.chunks[idx]
+	return ok
+}
+
+func (c *Cache) ReadChunk(key string, idx int64) ([]byte, bool) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok {
+		var elem *list.Element
+		if elem, ok = e.chunks[idx]; ok {
+			c.lru.MoveToFront(elem)
+		}
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.getChunkPath(key, idx))
+	if err != nil {
+		log.Warn("failed to read chunk", "error", err, "key", key, "idx", idx)
+		c.mu.Lock()
+		c.removeChunk(key, idx)
+		c.mu.Unlock()
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *Cache) WriteChunk(key string, idx int64, data []byte) error {
+	if int64(len(data)) > c.maxSize {
+		return nil
+	}
+	if err := writeFileAtomic(c.getChunkPath(key, idx), data); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		// purged while writing
+		os.Remove(c.getChunkPath(key, idx))
+		return nil
+	}
+	if elem, ok := e.chunks[idx]; ok {
+		ref := elem.Value.(*chunkRef)
+		c.size += int64(len(data)) - ref.size
+		ref.size = int64(len(data))
+		c.lru.MoveToFront(elem)
+		c.evict()
+		return nil
+	}
+	e.chunks[idx] = c.lru.PushFront(&chunkRef{key: key, idx: idx, size: int64(len(data))})
+	c.size += int64(len(data))
+	c.evict()
+	return nil
+}
+
+func (c *Cache) removeChunk(key string, idx int64) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	elem, ok := e.chunks[idx]
+	if !ok {
+		return
+	}
+	c.size -= elem.Value.(*chunkRef).size
+	c.lru.Remove(elem)
+	delete(e.chunks, idx)
+	if err := os.Remove(c.getChunkPath(key, idx)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error("failed to remove chunk", "error", err, "key", key, "idx", idx)
+	}
+	if len(e.chunks) == 0 {
+		c.removeEntry(key)
+	}
+}
+
+func (c *Cache) evict() {
+	for c.size > c.maxSize {
+		elem := c.lru.Back()
+		if elem == nil {
+			return
+		}
+		ref := elem.Value.(*chunkRef)
+		c.removeChunk(ref.key, ref.idx)
+	}
+}
+
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
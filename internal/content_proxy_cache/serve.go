@@ -0,0 +1,316 @@
+package content_proxy_cache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+// parseRange parses single range `Range` header, nil is returned for missing,
+// invalid and multiple ranges, i.e. full content should be served.
+func parseRange(header string, size int64) (*byteRange, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, errRangeNotSatisfiable
+		}
+		return &byteRange{start: max(0, size-suffix), end: size - 1}, nil
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return nil, errRangeNotSatisfiable
+	}
+	return &byteRange{start: start, end: end}, nil
+}
+
+// parseContentRange returns the start and total size from `Content-Range`
+// header, size is -1 if unknown.
+func parseContentRange(header string) (start int64, size int64, ok bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	rng, sizeStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	startStr, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if sizeStr == "*" {
+		return start, -1, true
+	}
+	size, err = strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// Fetch requests the upstream content, rangeHeader is empty for full content.
+type Fetch func(method string, rangeHeader string) (*http.Response, error)
+
+func passthrough(w http.ResponseWriter, res *http.Response) (int64, error) {
+	defer res.Body.Close()
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	return io.Copy(w, res.Body)
+}
+
+func (m *Meta) getETag(key string) string {
+	if m.ETag != "" {
+		return m.ETag
+	}
+	return `"` + key + "-" + strconv.FormatInt(m.Size, 16) + `"`
+}
+
+func getMetaFromResponse(res *http.Response, size int64) *Meta {
+	return &Meta{
+		Size:               size,
+		ETag:               res.Header.Get("ETag"),
+		LastModified:       res.Header.Get("Last-Modified"),
+		ContentType:        res.Header.Get("Content-Type"),
+		ContentDisposition: res.Header.Get("Content-Disposition"),
+	}
+}
+
+func isSameContent(a, b *Meta) bool {
+	if a.Size != b.Size {
+		return false
+	}
+	return a.ETag == "" || b.ETag == "" || a.ETag == b.ETag
+}
+
+type upstream struct {
+	body io.ReadCloser
+	pos  int64
+}
+
+func (u *upstream) close() {
+	if u.body != nil {
+		u.body.Close()
+		u.body = nil
+	}
+}
+
+// Serve serves the content for link, from the cached chunks when available
+// and fetching the missing chunks from upstream. wroteHeader is false when
+// the error happened before anything was sent.
+func (c *Cache) Serve(w http.ResponseWriter, r *http.Request, link string, fetch Fetch) (bytesWritten int64, wroteHeader bool, err error) {
+	key := GetKey(link)
+	meta := c.GetMeta(key)
+
+	up := &upstream{}
+	defer up.close()
+
+	if meta == nil {
+		rangeHeader := r.Header.Get("Range")
+		alignedStart := int64(0)
+		if rangeHeader != "" {
+			rng, err := parseRange(rangeHeader, 1<<62)
+			if err != nil || rng == nil || strings.HasPrefix(rangeHeader, "bytes=-") {
+				// can't align without knowing the size
+				res, err := fetch(r.Method, rangeHeader)
+				if err != nil {
+					return 0, false, err
+				}
+				n, err := passthrough(w, res)
+				return n, true, err
+			}
+			alignedStart = rng.start / c.chunkSize * c.chunkSize
+		}
+		res, err := fetch(r.Method, "bytes="+strconv.FormatInt(alignedStart, 10)+"-")
+		if err != nil {
+			return 0, false, err
+		}
+		start, size, ok := parseContentRange(res.Header.Get("Content-Range"))
+		if res.StatusCode != http.StatusPartialContent || !ok || size < 0 || start != alignedStart {
+			n, err := passthrough(w, res)
+			return n, true, err
+		}
+		meta = getMetaFromResponse(res, size)
+		if err := c.SetMeta(key, meta); err != nil {
+			log.Error("failed to save meta", "error", err, "key", key)
+		}
+		if r.Method == http.MethodHead {
+			res.Body.Close()
+		} else {
+			up.body = res.Body
+			up.pos = start
+		}
+	}
+
+	etag := meta.getETag(key)
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && (inm == etag || inm == "*") {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return 0, true, nil
+	}
+
+	rng, err := parseRange(r.Header.Get("Range"), meta.Size)
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != etag && ifRange != meta.LastModified {
+		rng, err = nil, nil
+	}
+	if err != nil {
+		w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(meta.Size, 10))
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return 0, true, nil
+	}
+	statusCode := http.StatusPartialContent
+	if rng == nil {
+		rng = &byteRange{start: 0, end: meta.Size - 1}
+		statusCode = http.StatusOK
+	}
+
+	header := w.Header()
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", etag)
+	if meta.LastModified != "" {
+		header.Set("Last-Modified", meta.LastModified)
+	}
+	if meta.ContentType != "" {
+		header.Set("Content-Type", meta.ContentType)
+	}
+	if meta.ContentDisposition != "" {
+		header.Set("Content-Disposition", meta.ContentDisposition)
+	}
+	header.Set("Content-Length", strconv.FormatInt(rng.end-rng.start+1, 10))
+	if statusCode == http.StatusPartialContent {
+		header.Set("Content-Range", "bytes "+strconv.FormatInt(rng.start, 10)+"-"+strconv.FormatInt(rng.end, 10)+"/"+strconv.FormatInt(meta.Size, 10))
+	}
+
+	if r.Method == http.MethodHead || meta.Size == 0 {
+		w.WriteHeader(statusCode)
+		return 0, true, nil
+	}
+
+	wroteHeader = false
+	writeHeader := func() {
+		if !wroteHeader {
+			w.WriteHeader(statusCode)
+			wroteHeader = true
+		}
+	}
+
+	lastIdx := rng.end / c.chunkSize
+	pos := rng.start
+	for pos <= rng.end {
+		idx := pos / c.chunkSize
+		chunkStart := idx * c.chunkSize
+		chunkLen := min(c.chunkSize, meta.Size-chunkStart)
+		writeEnd := min(chunkStart+chunkLen, rng.end+1)
+
+		if data, ok := c.ReadChunk(key, idx); ok && int64(len(data)) == chunkLen {
+			up.close()
+			writeHeader()
+			n, err := w.Write(data[pos-chunkStart : writeEnd-chunkStart])
+			bytesWritten += int64(n)
+			if err != nil {
+				return bytesWritten, true, err
+			}
+			pos = writeEnd
+			continue
+		}
+
+		if up.body == nil || up.pos != chunkStart {
+			up.close()
+			fetchEndIdx := idx
+			for fetchEndIdx < lastIdx && !c.HasChunk(key, fetchEndIdx+1) {
+				fetchEndIdx++
+			}
+			fetchEnd := min((fetchEndIdx+1)*c.chunkSize, meta.Size) - 1
+			res, err := fetch(http.MethodGet, "bytes="+strconv.FormatInt(chunkStart, 10)+"-"+strconv.FormatInt(fetchEnd, 10))
+			if err != nil {
+				return bytesWritten, wroteHeader, err
+			}
+			start, size, ok := parseContentRange(res.Header.Get("Content-Range"))
+			if res.StatusCode != http.StatusPartialContent || !ok || start != chunkStart {
+				res.Body.Close()
+				return bytesWritten, wroteHeader, errors.New("unexpected upstream response: " + res.Status)
+			}
+			if !isSameContent(meta, getMetaFromResponse(res, size)) {
+				res.Body.Close()
+				c.Purge(key)
+				return bytesWritten, wroteHeader, errors.New("upstream content changed")
+			}
+			up.body = res.Body
+			up.pos = chunkStart
+		}
+
+		writeHeader()
+		buf := make([]byte, chunkLen)
+		filled := int64(0)
+		for filled < chunkLen {
+			n, err := up.body.Read(buf[filled:])
+			if n > 0 {
+				from, to := max(pos, chunkStart+filled), min(chunkStart+filled+int64(n), writeEnd)
+				if from < to {
+					wn, werr := w.Write(buf[from-chunkStart : to-chunkStart])
+					bytesWritten += int64(wn)
+					if werr != nil {
+						return bytesWritten, true, werr
+					}
+				}
+				filled += int64(n)
+			}
+			if err != nil {
+				if filled == chunkLen {
+					break
+				}
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return bytesWritten, true, err
+			}
+		}
+		up.pos += chunkLen
+
+		if err := c.WriteChunk(key, idx, buf); err != nil {
+			log.Error("failed to write chunk", "error", err, "key", key, "idx", idx)
+		}
+		pos = writeEnd
+	}
+
+	return bytesWritten, true, nil
+}
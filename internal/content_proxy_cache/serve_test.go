@@ -0,0 +1,132 @@
+package content_proxy_cache
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUpstream struct {
+	content  []byte
+	requests atomic.Int32
+}
+
+func (u *testUpstream) fetch(method string, rangeHeader string) (*http.Response, error) {
+	u.requests.Add(1)
+	req := httptest.NewRequest(method, "/file.mkv", nil)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	w := httptest.NewRecorder()
+	w.Header().Set("ETag", `"v1"`)
+	http.ServeContent(w, req, "file.mkv", time.Time{}, bytes.NewReader(u.content))
+	return w.Result(), nil
+}
+
+func serve(c *Cache, u *testUpstream, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/proxy", nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	c.Serve(w, r, "https://example.com/file.mkv", u.fetch)
+	return w
+}
+
+func TestServe(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789abcdef", 10)[:150])
+	u := &testUpstream{content: content}
+	c, err := New(t.TempDir(), 1024, 16)
+	assert.NoError(t, err)
+
+	w := serve(c, u, map[string]string{"Range": "bytes=20-40"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 20-40/150", w.Header().Get("Content-Range"))
+	assert.Equal(t, "21", w.Header().Get("Content-Length"))
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	assert.Equal(t, content[20:41], w.Body.Bytes())
+
+	requests := u.requests.Load()
+	w = serve(c, u, map[string]string{"Range": "bytes=16-47"})
+	assert.Equal(t, content[16:48], w.Body.Bytes())
+	assert.Equal(t, requests, u.requests.Load(), "served from cache")
+
+	// stitches cached chunks with the fetched ones
+	w = serve(c, u, map[string]string{"Range": "bytes=5-70"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, content[5:71], w.Body.Bytes())
+
+	w = serve(c, u, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "150", w.Header().Get("Content-Length"))
+	assert.Equal(t, content, w.Body.Bytes())
+
+	requests = u.requests.Load()
+	w = serve(c, u, map[string]string{"Range": "bytes=-10"})
+	assert.Equal(t, "bytes 140-149/150", w.Header().Get("Content-Range"))
+	assert.Equal(t, content[140:], w.Body.Bytes())
+	assert.Equal(t, requests, u.requests.Load(), "served from cache")
+
+	w = serve(c, u, map[string]string{"Range": "bytes=200-"})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	assert.Equal(t, "bytes */150", w.Header().Get("Content-Range"))
+
+	w = serve(c, u, map[string]string{"If-None-Match": `"v1"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serve(c, u, map[string]string{"Range": "bytes=10-19", "If-Range": `"v0"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, content, w.Body.Bytes())
+}
+
+func TestServeContentChanged(t *testing.T) {
+	u := &testUpstream{content: []byte(strings.Repeat("a", 64))}
+	c, err := New(t.TempDir(), 1024, 16)
+	assert.NoError(t, err)
+
+	serve(c, u, map[string]string{"Range": "bytes=0-15"})
+	assert.NotNil(t, c.GetMeta(GetKey("https://example.com/file.mkv")))
+
+	u.content = []byte(strings.Repeat("b", 80))
+	serve(c, u, map[string]string{"Range": "bytes=32-47"})
+	assert.Nil(t, c.GetMeta(GetKey("https://example.com/file.mkv")), "purged")
+
+	w := serve(c, u, map[string]string{"Range": "bytes=32-47"})
+	assert.Equal(t, "bytes 32-47/80", w.Header().Get("Content-Range"))
+	assert.Equal(t, u.content[32:48], w.Body.Bytes())
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 64, 16)
+	assert.NoError(t, err)
+
+	key := GetKey("link")
+	assert.NoError(t, c.SetMeta(key, &Meta{Size: 160}))
+	for idx := range int64(10) {
+		assert.NoError(t, c.WriteChunk(key, idx, bytes.Repeat([]byte{byte(idx)}, 16)))
+		assert.LessOrEqual(t, c.Size(), int64(64))
+	}
+	assert.False(t, c.HasChunk(key, 0))
+	assert.True(t, c.HasChunk(key, 9))
+
+	_, ok := c.ReadChunk(key, 6)
+	assert.True(t, ok)
+	assert.NoError(t, c.WriteChunk(key, 10, bytes.Repeat([]byte{10}, 16)))
+	assert.True(t, c.HasChunk(key, 6), "recently read chunk is kept")
+	assert.False(t, c.HasChunk(key, 7))
+
+	reloaded, err := New(dir, 64, 16)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Size(), reloaded.Size())
+	data, ok := reloaded.ReadChunk(key, 10)
+	assert.True(t, ok)
+	assert.Equal(t, bytes.Repeat([]byte{10}, 16), data)
+	assert.Equal(t, int64(160), reloaded.GetMeta(key).Size)
+}
@@ -0,0 +1,547 @@
+package content_proxy_hls
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("content_proxy_hls")
+
+type Variant string
+
+const (
+	// VariantRemux copies the video and audio streams as is.
+	VariantRemux Variant = "remux"
+	// VariantAAC copies the video stream, and transcodes the audio to stereo AAC.
+	VariantAAC Variant = "aac"
+)
+
+func (v Variant) IsValid() bool {
+	return v == VariantRemux || v == VariantAAC
+}
+
+const PlaylistFileName = "index.m3u8"
+
+// ffmpeg writes its own playlist, the one served is generated from the
+// duration of the source.
+const ffmpegPlaylistFileName = "ffmpeg.m3u8"
+
+const segmentDuration = 6
+
+// ffmpeg is restarted at the requested segment if it is farther than this
+// from the last produced segment.
+const maxSegmentLookahead = 5
+
+var segmentFileNameRegex = regexp.MustCompile(`^(init\.mp4|seg_\d{5,}\.m4s)$`)
+
+func IsValidFileName(name string) bool {
+	return name == PlaylistFileName || segmentFileNameRegex.MatchString(name)
+}
+
+func getSegmentFileName(idx int) string {
+	return fmt.Sprintf("seg_%05d.m4s", idx)
+}
+
+// getSegmentIndex returns -1 if name is not a segment.
+func getSegmentIndex(name string) int {
+	if !strings.HasPrefix(name, "seg_") || !strings.HasSuffix(name, ".m4s") {
+		return -1
+	}
+	idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "seg_"), ".m4s"))
+	if err != nil {
+		return -1
+	}
+	return idx
+}
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrTimeout         = errors.New("timed out")
+	ErrTooManySessions = errors.New("too many sessions")
+)
+
+func GetKey(token string, variant Variant) string {
+	hash := sha256.Sum256([]byte(token + ":" + string(variant)))
+	return hex.EncodeToString(hash[:16])
+}
+
+// getFFmpegArgs returns the args to segment the input starting from the
+// segment at index start. The timestamps are kept as is, so that the
+// segments produced after a restart line up with the playlist.
+func getFFmpegArgs(input string, dir string, variant Variant, start int) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-nostdin"}
+	if start > 0 {
+		args = append(args, "-ss", strconv.Itoa(start*segmentDuration))
+	}
+	args = append(args,
+		"-i", input,
+		"-copyts", "-avoid_negative_ts", "disabled",
+		"-map", "0:v:0", "-map", "0:a:0?", "-sn", "-dn",
+		"-c:v", "copy",
+	)
+	switch variant {
+	case VariantAAC:
+		args = append(args, "-c:a", "aac", "-b:a", "192k", "-ac", "2")
+	default:
+		args = append(args, "-c:a", "copy")
+	}
+	return append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(segmentDuration),
+		"-hls_list_size", "0",
+		"-hls_segment_type", "fmp4",
+		"-hls_fmp4_init_filename", "init.mp4",
+		"-hls_flags", "temp_file+independent_segments",
+		"-start_number", strconv.Itoa(start),
+		"-hls_segment_filename", filepath.Join(dir, "seg_%05d.m4s"),
+		filepath.Join(dir, ffmpegPlaylistFileName),
+	)
+}
+
+var durationRegex = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+// parseDuration parses the duration, in seconds, from the input info
+// printed by ffmpeg.
+func parseDuration(info string) (float64, error) {
+	match := durationRegex.FindStringSubmatch(info)
+	if match == nil {
+		return 0, errors.New("missing duration")
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.ParseFloat(match[3], 64)
+	duration := float64(hours*3600+minutes*60) + seconds
+	if duration <= 0 {
+		return 0, errors.New("missing duration")
+	}
+	return duration, nil
+}
+
+// getPlaylist returns the VOD playlist for the duration, with segments of
+// fixed length. Video is not transcoded, so the segments are cut at the
+// nearest keyframe and can be slightly off from the listed durations.
+func getPlaylist(duration float64) []byte {
+	count := int(math.Ceil(duration / segmentDuration))
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:7\n")
+	b.WriteString("#EXT-X-TARGETDURATION:" + strconv.Itoa(segmentDuration) + "\n")
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	b.WriteString("#EXT-X-MAP:URI=\"init.mp4\"\n")
+	for i := range count {
+		d := min(float64(segmentDuration), duration-float64(i*segmentDuration))
+		b.WriteString("#EXTINF:" + strconv.FormatFloat(d, 'f', 6, 64) + ",\n")
+		b.WriteString(getSegmentFileName(i) + "\n")
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return []byte(b.String())
+}
+
+// process is an ffmpeg process segmenting from the segment at index start.
+type process struct {
+	cmd     *exec.Cmd
+	start   int
+	done    chan struct{}
+	err     error
+	stopped atomic.Bool
+}
+
+func (p *process) isDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// failed is false if the process was stopped for a restart.
+func (p *process) failed() bool {
+	return p.isDone() && p.err != nil && !p.stopped.Load()
+}
+
+func (p *process) stop() {
+	if p.isDone() {
+		return
+	}
+	p.stopped.Store(true)
+	if err := p.cmd.Process.Kill(); err != nil {
+		log.Warn("failed to kill ffmpeg", "error", err)
+	}
+	<-p.done
+}
+
+type session struct {
+	key          string
+	dir          string
+	variant      Variant
+	input        string
+	source       http.HandlerFunc
+	lastAccessAt atomic.Int64
+
+	probeOnce sync.Once
+	duration  float64
+	probeErr  error
+
+	mu   sync.Mutex
+	proc *process
+}
+
+func (s *session) touch() {
+	s.lastAccessAt.Store(time.Now().UnixNano())
+}
+
+func (s *session) isIdle(timeout time.Duration) bool {
+	return time.Since(time.Unix(0, s.lastAccessAt.Load())) > timeout
+}
+
+func (s *session) getProcess() *process {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.proc
+}
+
+func (s *session) hasFile(name string) bool {
+	_, err := os.Stat(filepath.Join(s.dir, name))
+	return err == nil
+}
+
+func (s *session) getSegmentCount() int {
+	return int(math.Ceil(s.duration / segmentDuration))
+}
+
+// lastSegmentIndex returns the index of the last segment produced by p.
+func (s *session) lastSegmentIndex(p *process) int {
+	idx := p.start
+	for s.hasFile(getSegmentFileName(idx)) {
+		idx++
+	}
+	return idx - 1
+}
+
+// probe reads the duration of the source, which is needed for the playlist.
+func (s *session) probe(ffmpegPath string) error {
+	s.probeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		stderr := &bytes.Buffer{}
+		cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostdin", "-i", s.input)
+		cmd.Stderr = stderr
+		// exits with error as no output is specified
+		cmd.Run()
+		s.duration, s.probeErr = parseDuration(stderr.String())
+	})
+	return s.probeErr
+}
+
+// ensureProcess makes sure that an ffmpeg process is running, which will
+// produce the segment at index idx. A running process is restarted if the
+// segment is behind it, or too far ahead of it.
+func (s *session) ensureProcess(ffmpegPath string, idx int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p := s.proc; p != nil {
+		if p.failed() {
+			return p.err
+		}
+		if !p.isDone() && idx >= p.start && idx <= s.lastSegmentIndex(p)+maxSegmentLookahead {
+			return nil
+		}
+		p.stop()
+	}
+
+	stderr := &bytes.Buffer{}
+	p := &process{
+		cmd:   exec.Command(ffmpegPath, getFFmpegArgs(s.input, s.dir, s.variant, idx)...),
+		start: idx,
+		done:  make(chan struct{}),
+	}
+	p.cmd.Stderr = stderr
+	if err := p.cmd.Start(); err != nil {
+		return err
+	}
+	log.Debug("started ffmpeg", "key", s.key, "variant", s.variant, "start", idx)
+
+	go func() {
+		if err := p.cmd.Wait(); err != nil && !p.stopped.Load() {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = errors.New(err.Error() + ": " + msg)
+			}
+			p.err = err
+			log.Warn("ffmpeg exited", "error", err, "key", s.key)
+		}
+		close(p.done)
+	}()
+
+	s.proc = p
+	return nil
+}
+
+func (s *session) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proc != nil {
+		s.proc.stop()
+	}
+}
+
+func (s *session) waitForFile(ctx context.Context, name string, timeout time.Duration) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		if s.hasFile(name) {
+			return nil
+		}
+		// the process can be replaced by a concurrent seek
+		if p := s.getProcess(); p != nil && p.isDone() && !p.stopped.Load() {
+			if s.hasFile(name) {
+				return nil
+			}
+			if p.err != nil {
+				return p.err
+			}
+			return ErrNotFound
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return ErrTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+// Manager runs an ffmpeg process per session, which segments the source
+// into HLS segments under `<dir>/<key>`. The playlist is generated up front
+// from the duration of the source, and ffmpeg is restarted at the requested
+// segment on seek. The source is served to ffmpeg from a loopback listener,
+// so that it goes through the content proxy.
+type Manager struct {
+	ffmpegPath  string
+	dir         string
+	maxSessions int
+	idleTimeout time.Duration
+	sourceAddr  string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewManager(ffmpegPath string, dir string, maxSessions int, idleTimeout time.Duration) (*Manager, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		ffmpegPath:  ffmpegPath,
+		dir:         dir,
+		maxSessions: maxSessions,
+		idleTimeout: idleTimeout,
+		sourceAddr:  listener.Addr().String(),
+		sessions:    map[string]*session{},
+	}
+
+	go func() {
+		if err := http.Serve(listener, http.HandlerFunc(m.serveSource)); err != nil {
+			log.Error("source server stopped", "error", err)
+		}
+	}()
+	go m.reap()
+
+	return m, nil
+}
+
+// Default is nil when HLS is disabled.
+var Default = func() *Manager {
+	if !config.ContentProxyHLS.IsEnabled() {
+		return nil
+	}
+	conf := config.ContentProxyHLS
+	m, err := NewManager(conf.FFmpegPath, conf.Dir, conf.MaxSessions, conf.IdleTimeout)
+	if err != nil {
+		log.Error("failed to initialize content proxy hls", "error", err)
+		return nil
+	}
+	return m
+}()
+
+func (m *Manager) serveSource(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	s, ok := m.sessions[strings.TrimPrefix(r.URL.Path, "/")]
+	m.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.source(w, r)
+}
+
+func (m *Manager) getSession(key string, variant Variant, source http.HandlerFunc) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[key]; ok {
+		if p := s.getProcess(); p != nil && p.failed() {
+			// failed session is dropped, next request starts afresh
+			delete(m.sessions, key)
+			go m.stop(s)
+			return nil, p.err
+		}
+		s.touch()
+		return s, nil
+	}
+
+	if len(m.sessions) >= m.maxSessions {
+		return nil, ErrTooManySessions
+	}
+
+	s := &session{
+		key:     key,
+		dir:     filepath.Join(m.dir, key),
+		variant: variant,
+		input:   "http://" + m.sourceAddr + "/" + key,
+		source:  source,
+	}
+	s.touch()
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	log.Debug("started session", "key", key, "variant", variant)
+
+	m.sessions[key] = s
+	return s, nil
+}
+
+func (m *Manager) stop(s *session) {
+	s.stop()
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Error("failed to remove session dir", "error", err, "key", s.key)
+	}
+	log.Debug("stopped session", "key", s.key)
+}
+
+func (m *Manager) reap() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		idle := []*session{}
+		m.mu.Lock()
+		for key, s := range m.sessions {
+			if s.isIdle(m.idleTimeout) {
+				idle = append(idle, s)
+				delete(m.sessions, key)
+			}
+		}
+		m.mu.Unlock()
+		for _, s := range idle {
+			m.stop(s)
+		}
+	}
+}
+
+// Serve serves the playlist or segment file for the session identified by
+// key, the session is started if not running. source serves the content
+// to be segmented, with support for range requests.
+func (m *Manager) Serve(w http.ResponseWriter, r *http.Request, key string, variant Variant, fileName string, source http.HandlerFunc) error {
+	if !variant.IsValid() || !IsValidFileName(fileName) {
+		return ErrNotFound
+	}
+
+	s, err := m.getSession(key, variant, source)
+	if err != nil {
+		return err
+	}
+
+	if err := s.probe(m.ffmpegPath); err != nil {
+		m.mu.Lock()
+		if m.sessions[key] == s {
+			delete(m.sessions, key)
+			go m.stop(s)
+		}
+		m.mu.Unlock()
+		return err
+	}
+
+	if fileName == PlaylistFileName {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeContent(w, r, fileName, time.Time{}, bytes.NewReader(getPlaylist(s.duration)))
+		return nil
+	}
+
+	if !s.hasFile(fileName) {
+		idx := getSegmentIndex(fileName)
+		if idx >= s.getSegmentCount() {
+			return ErrNotFound
+		}
+		if idx == -1 {
+			// init is produced by any process, start from the beginning
+			// only if none is running.
+			idx = 0
+			if p := s.getProcess(); p != nil && !p.isDone() {
+				idx = p.start
+			}
+		}
+		if err := s.ensureProcess(m.ffmpegPath, idx); err != nil {
+			return err
+		}
+		if err := s.waitForFile(r.Context(), fileName, 60*time.Second); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, fileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(fileName, ".m4s") {
+		w.Header().Set("Content-Type", "video/iso.segment")
+	} else {
+		w.Header().Set("Content-Type", "video/mp4")
+	}
+	http.ServeContent(w, r, fileName, stat.ModTime(), file)
+	return nil
+}
@@ -0,0 +1,127 @@
+package content_proxy_hls
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain acts as fake ffmpeg when re-executed by the manager, it reports
+// a duration of 60s when probed, otherwise fetches the input and writes it
+// as the segment at the start number.
+func TestMain(m *testing.M) {
+	if os.Getenv("FAKE_FFMPEG") != "1" {
+		os.Exit(m.Run())
+	}
+
+	args := os.Args[1:]
+	input := args[slices.Index(args, "-i")+1]
+	if !slices.Contains(args, "-f") {
+		os.Stderr.WriteString("  Duration: 00:01:00.00, start: 0.000000, bitrate: 1 kb/s\n")
+		os.Exit(1)
+	}
+	playlist := args[len(args)-1]
+	dir := filepath.Dir(playlist)
+	start := args[slices.Index(args, "-start_number")+1]
+
+	res, err := http.Get(input)
+	if err != nil {
+		os.Exit(1)
+	}
+	defer res.Body.Close()
+	content, err := io.ReadAll(res.Body)
+	if err != nil || res.StatusCode != 200 {
+		os.Exit(1)
+	}
+	os.WriteFile(filepath.Join(dir, "init.mp4"), []byte("init"), 0644)
+	os.WriteFile(filepath.Join(dir, fmt.Sprintf("seg_%05s.m4s", start)), append([]byte(start+":"), content...), 0644)
+	os.WriteFile(playlist, []byte("#EXTM3U\n#EXT-X-ENDLIST\n"), 0644)
+	os.Exit(0)
+}
+
+func TestGetFFmpegArgs(t *testing.T) {
+	args := getFFmpegArgs("http://127.0.0.1/key", "/tmp/key", VariantRemux, 0)
+	assert.Contains(t, args, "copy")
+	assert.NotContains(t, args, "aac")
+	assert.NotContains(t, args, "-ss")
+	assert.Equal(t, "/tmp/key/ffmpeg.m3u8", args[len(args)-1])
+
+	args = getFFmpegArgs("http://127.0.0.1/key", "/tmp/key", VariantAAC, 10)
+	assert.Contains(t, args, "aac")
+	assert.Equal(t, "60", args[slices.Index(args, "-ss")+1])
+	assert.Equal(t, "10", args[slices.Index(args, "-start_number")+1])
+}
+
+func TestParseDuration(t *testing.T) {
+	duration, err := parseDuration("  Duration: 01:02:03.50, start: 0.000000")
+	assert.NoError(t, err)
+	assert.Equal(t, 3723.5, duration)
+
+	_, err = parseDuration("  Duration: N/A, bitrate: N/A")
+	assert.Error(t, err)
+}
+
+func TestGetPlaylist(t *testing.T) {
+	playlist := string(getPlaylist(10))
+	assert.Contains(t, playlist, "#EXT-X-PLAYLIST-TYPE:VOD\n")
+	assert.Contains(t, playlist, "#EXTINF:6.000000,\nseg_00000.m4s\n")
+	assert.Contains(t, playlist, "#EXTINF:4.000000,\nseg_00001.m4s\n")
+	assert.NotContains(t, playlist, "seg_00002.m4s")
+	assert.Contains(t, playlist, "#EXT-X-ENDLIST\n")
+}
+
+func TestIsValidFileName(t *testing.T) {
+	assert.True(t, IsValidFileName("index.m3u8"))
+	assert.True(t, IsValidFileName("init.mp4"))
+	assert.True(t, IsValidFileName("seg_00012.m4s"))
+	assert.False(t, IsValidFileName("../index.m3u8"))
+	assert.False(t, IsValidFileName("seg_1.m4s"))
+}
+
+func TestManagerServe(t *testing.T) {
+	t.Setenv("FAKE_FFMPEG", "1")
+
+	m, err := NewManager(os.Args[0], t.TempDir(), 1, time.Minute)
+	assert.NoError(t, err)
+
+	source := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content"))
+	}
+
+	serve := func(key string, fileName string) (*httptest.ResponseRecorder, error) {
+		w := httptest.NewRecorder()
+		err := m.Serve(w, httptest.NewRequest("GET", "/", nil), key, VariantRemux, fileName, source)
+		return w, err
+	}
+
+	w, err := serve("a", PlaylistFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/vnd.apple.mpegurl", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "seg_00009.m4s")
+
+	w, err = serve("a", "seg_00000.m4s")
+	assert.NoError(t, err)
+	assert.Equal(t, "0:content", w.Body.String())
+
+	// seek restarts ffmpeg at the segment
+	w, err = serve("a", "seg_00009.m4s")
+	assert.NoError(t, err)
+	assert.Equal(t, "9:content", w.Body.String())
+
+	_, err = serve("a", "seg_00010.m4s")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = serve("a", "../index.m3u8")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = serve("b", PlaylistFileName)
+	assert.ErrorIs(t, err, ErrTooManySessions)
+}
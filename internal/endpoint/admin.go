@@ -0,0 +1,116 @@
+package endpoint
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/rodezfranco/stremthru/internal/proxy_token"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/internal/worker/worker_queue"
+)
+
+type WorkerQueueStats struct {
+	Name string `json:"name"`
+	worker_queue.QueueStats
+}
+
+type ListWorkerQueuesData struct {
+	Items []WorkerQueueStats `json:"items"`
+}
+
+func handleAdminListWorkerQueues(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	statsByQueue, err := worker_queue.GetStats()
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	data := ListWorkerQueuesData{Items: []WorkerQueueStats{}}
+	for _, name := range worker_queue.GetQueueNames() {
+		data.Items = append(data.Items, WorkerQueueStats{
+			Name:       name,
+			QueueStats: statsByQueue[name],
+		})
+	}
+	SendResponse(w, r, 200, data, nil)
+}
+
+type ListWorkerQueueDeadItemsData struct {
+	Items []worker_queue.DeadItem `json:"items"`
+}
+
+func handleAdminListWorkerQueueDeadItems(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	name := r.PathValue("name")
+	if !slices.Contains(worker_queue.GetQueueNames(), name) {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	items, err := worker_queue.ListDead(name)
+	SendResponse(w, r, 200, ListWorkerQueueDeadItemsData{Items: items}, err)
+}
+
+type ListProxyTokensData struct {
+	Items []proxy_token.ProxyToken `json:"items"`
+}
+
+type RevokeProxyTokensData struct {
+	RevokedCount int64 `json:"revoked_count"`
+}
+
+func handleAdminProxyTokens(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	user, device := query.Get("user"), query.Get("device")
+
+	switch r.Method {
+	case http.MethodGet:
+		items, err := proxy_token.List(&proxy_token.ListParams{Username: user, Device: device})
+		SendResponse(w, r, 200, ListProxyTokensData{Items: items}, err)
+	case http.MethodDelete:
+		if user == "" {
+			shared.ErrorBadRequest(r, "missing user").Send(w, r)
+			return
+		}
+		count, err := proxy_token.RevokeAll(user, device)
+		SendResponse(w, r, 200, RevokeProxyTokensData{RevokedCount: count}, err)
+	default:
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+	}
+}
+
+func handleAdminProxyToken(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	revoked, err := proxy_token.Revoke(r.PathValue("id"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if !revoked {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func AddAdminEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/worker-queues", withAdminAuth(handleAdminListWorkerQueues))
+	mux.HandleFunc("/v0/admin/worker-queues/{name}/dead", withAdminAuth(handleAdminListWorkerQueueDeadItems))
+	mux.HandleFunc("/v0/admin/proxy-tokens", withAdminAuth(handleAdminProxyTokens))
+	mux.HandleFunc("/v0/admin/proxy-tokens/{id}", withAdminAuth(handleAdminProxyToken))
+}
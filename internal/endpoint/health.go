@@ -10,6 +10,7 @@ import (
 	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/context"
 	"github.com/rodezfranco/stremthru/internal/server"
+	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
 )
 
 type HealthData struct {
@@ -23,9 +24,10 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 type HealthDebugDataIP struct {
-	Machine string            `json:"machine"`
-	Tunnel  map[string]string `json:"tunnel"`
-	Exposed map[string]string `json:"exposed"`
+	Machine string                                    `json:"machine"`
+	Tunnel  map[string]string                         `json:"tunnel"`
+	Exposed map[string]string                         `json:"exposed"`
+	Pool    map[string][]config.TunnelPoolProxyStatus `json:"pool,omitempty"`
 }
 
 type HealthDebugDataStore struct {
@@ -39,10 +41,11 @@ type HealthDebugDataUser struct {
 }
 
 type HealthDebugData struct {
-	Time    string               `json:"time"`
-	Version string               `json:"version"`
-	User    *HealthDebugDataUser `json:"user,omitempty"`
-	IP      *HealthDebugDataIP   `json:"ip,omitempty"`
+	Time    string                         `json:"time"`
+	Version string                         `json:"version"`
+	User    *HealthDebugDataUser           `json:"user,omitempty"`
+	IP      *HealthDebugDataIP             `json:"ip,omitempty"`
+	Addons  []stremio_addon.UpstreamHealth `json:"addons,omitempty"`
 }
 
 func handleHealthDebug(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +95,14 @@ func handleHealthDebug(w http.ResponseWriter, r *http.Request) {
 			exposed["*"] = machineIp
 		}
 
+		var pool map[string][]config.TunnelPoolProxyStatus
+		if len(config.StoreTunnelPool) > 0 {
+			pool, err = config.IP.GetTunnelPoolIP()
+			if err != nil {
+				ipMapErrs = append(ipMapErrs, err)
+			}
+		}
+
 		if ipMapErr := errors.Join(ipMapErrs...); ipMapErr != nil {
 			reqCtx := server.GetReqCtx(r)
 			reqCtx.Log.Warn("Failed to get tunnel ip map", "error", ipMapErr)
@@ -113,11 +124,24 @@ func handleHealthDebug(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		for storeName, storePool := range config.StoreTunnelPool {
+			ip := storePool.GetIP(ctx.ProxyAuthUser)
+			if config.StoreTunnel.GetTypeForAPI(storeName) == config.TUNNEL_TYPE_FORCED {
+				exposed[":"+storeName+":api:"] = ip
+			}
+			if config.StoreTunnelPool.IsEnabledForStream(storeName) && config.StoreTunnel.GetTypeForStream(storeName) == config.TUNNEL_TYPE_FORCED {
+				exposed[":"+storeName+":stream:"] = ip
+			}
+		}
+
 		data.IP = &HealthDebugDataIP{
 			Machine: machineIp,
 			Tunnel:  tunnel,
 			Exposed: exposed,
+			Pool:    pool,
 		}
+
+		data.Addons = stremio_addon.ListUpstreamHealth()
 	}
 
 	SendResponse(w, r, 200, data, nil)
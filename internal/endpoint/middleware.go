@@ -66,16 +66,23 @@ func getStoreName(r *http.Request) (store.StoreName, *core.StoreError) {
 	if name == "" {
 		return "", nil
 	}
+	if store.StoreName(name) == store.StoreNameMulti {
+		return store.StoreNameMulti, nil
+	}
 	return store.StoreName(name).Validate()
 }
 
 func getStoreAuthToken(r *http.Request) string {
+	ctx := context.GetStoreContext(r)
+	if ctx.Store != nil && ctx.Store.GetName() == store.StoreNameMulti {
+		// member stores use their own tokens
+		return ""
+	}
 	authHeader := r.Header.Get("X-StremThru-Store-Authorization")
 	if authHeader == "" {
 		authHeader = r.Header.Get("Authorization")
 	}
 	if authHeader == "" {
-		ctx := context.GetStoreContext(r)
 		if ctx.IsProxyAuthorized && ctx.Store != nil {
 			if token := config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, string(ctx.Store.GetName())); token != "" {
 				return token
@@ -93,6 +100,13 @@ func getStore(r *http.Request) (store.Store, error) {
 		err.StatusCode = http.StatusBadRequest
 		return nil, err
 	}
+	if name == store.StoreNameMulti {
+		ctx := context.GetStoreContext(r)
+		if !ctx.IsProxyAuthorized {
+			return nil, shared.ErrorUnauthorized(r)
+		}
+		return shared.GetMultiStore(ctx.ProxyAuthUser), nil
+	}
 	return shared.GetStore(string(name)), nil
 }
 
@@ -124,7 +138,9 @@ func StoreRequired(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		if ctx.StoreAuthToken == "" {
+		// multi store is only available for proxy authorized user, with
+		// the tokens of its member stores
+		if ctx.StoreAuthToken == "" && ctx.Store.GetName() != store.StoreNameMulti {
 			w.Header().Add("WWW-Authenticate", "Bearer realm=\"store:"+string(ctx.Store.GetName())+"\"")
 			shared.ErrorUnauthorized(r).Send(w, r)
 			return
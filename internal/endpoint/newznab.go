@@ -0,0 +1,97 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/rodezfranco/stremthru/internal/newznab"
+	"github.com/rodezfranco/stremthru/internal/nzb_info"
+	"github.com/rodezfranco/stremthru/internal/peer_token"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/internal/torznab"
+)
+
+func handleNewznab(w http.ResponseWriter, r *http.Request) {
+	t := r.URL.Query().Get("t")
+
+	if t == "" {
+		http.Redirect(w, r, r.URL.Path+"?t=caps", http.StatusTemporaryRedirect)
+		return
+	}
+
+	switch t {
+	case "caps":
+		w.Header().Set("Cache-Control", "public, max-age=7200")
+		shared.SendXML(w, r, 200, newznab.Caps)
+	case "search", "tvsearch", "movie":
+		query, err := torznab.ParseQuery(r.URL.Query())
+		if err != nil {
+			shared.SendXML(w, r, 200, torznab.ErrorIncorrectParameter(err.Error()))
+			return
+		}
+		items, err := newznab.Search(query, shared.ExtractRequestBaseURL(r))
+		if err != nil {
+			shared.SendXML(w, r, 200, torznab.ErrorUnknownError(err.Error()))
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=600")
+		shared.SendXML(w, r, 200, newznab.ResultFeed{
+			Info:  newznab.Info,
+			Items: items,
+		})
+	case "get":
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			shared.SendXML(w, r, 200, torznab.ErrorMissingParameter("id"))
+			return
+		}
+		link, err := newznab.GetNZBLink(id)
+		if err != nil {
+			if terr, ok := err.(torznab.Error); ok {
+				shared.SendXML(w, r, 200, terr)
+			} else {
+				shared.SendXML(w, r, 200, torznab.ErrorUnknownError(err.Error()))
+			}
+			return
+		}
+		http.Redirect(w, r, link, http.StatusFound)
+	default:
+		w.Header().Set("Cache-Control", "public, max-age=7200")
+		shared.SendXML(w, r, 200, torznab.ErrorNoSuchFunction)
+	}
+}
+
+type RecordNZBsPayload struct {
+	Items []nzb_info.NZBInfoInsertData `json:"items"`
+}
+
+func handleRecordNZBs(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	peerToken := r.Header.Get("X-StremThru-Peer-Token")
+	isValidToken, err := peer_token.IsValid(peerToken)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if !isValidToken {
+		shared.ErrorUnauthorized(r).Send(w, r)
+		return
+	}
+
+	payload := &RecordNZBsPayload{}
+	if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	go nzb_info.Upsert(payload.Items)
+	w.WriteHeader(204)
+}
+
+func AddNewznabEndpoints(mux *http.ServeMux) {
+	mux.HandleFunc("/v0/newznab/api", handleNewznab)
+	mux.HandleFunc("/v0/nzbs", handleRecordNZBs)
+}
@@ -1,19 +1,72 @@
 package endpoint
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_hls"
+	"github.com/rodezfranco/stremthru/internal/proxy_token"
+	"github.com/rodezfranco/stremthru/internal/proxy_usage"
 	"github.com/rodezfranco/stremthru/internal/server"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	store_video "github.com/rodezfranco/stremthru/internal/store/video"
 	"github.com/rodezfranco/stremthru/internal/util"
 )
 
+func isContentProxyQuotaExceeded(r *http.Request, user string) bool {
+	quota := config.ContentProxyQuota.GetMonthlyQuota(user)
+	if quota <= 0 {
+		return false
+	}
+	usage, err := proxy_usage.GetMonthlyTotal(user, time.Now())
+	if err != nil {
+		server.GetReqCtx(r).Log.Error("[proxy] failed to get usage", "error", err)
+		return false
+	}
+	return usage.Bytes >= quota
+}
+
+// acquireContentProxyConnection records the user's connection for the
+// connection limit, it returns false if the limit is reached. release must
+// be called once the connection is closed.
+func acquireContentProxyConnection(log *slog.Logger, user, connId, ip, link string) (release func(), ok bool) {
+	cpStore := contentProxyConnectionStore.WithScope(user)
+
+	if limit := config.ContentProxyConnectionLimit.Get(user); limit > 0 {
+		activeConnectionCount, err := cpStore.Count()
+		if err != nil {
+			log.Error("[proxy] failed to count connections", "error", err)
+		} else if activeConnectionCount >= limit {
+			return nil, false
+		}
+	}
+
+	if err := cpStore.Set(connId, contentProxyConnection{IP: ip, Link: link}); err != nil {
+		log.Error("[proxy] failed to record connection", "error", err)
+		return func() {}, true
+	}
+	return func() { cpStore.Del(connId) }, true
+}
+
+// newContentProxyResponseWriter applies the user's rate limit and monthly
+// quota to w, and records the usage through it.
+func newContentProxyResponseWriter(log *slog.Logger, w http.ResponseWriter, r *http.Request, user string) *proxy_usage.UsageResponseWriter {
+	w = proxy_usage.NewRateLimitedResponseWriter(w, r, config.ContentProxyQuota.GetRateLimit(user))
+	uw, err := proxy_usage.NewUsageResponseWriter(w, user, config.ContentProxyQuota.GetMonthlyQuota(user))
+	if err != nil {
+		log.Error("[proxy] failed to get usage", "error", err)
+	}
+	return uw
+}
+
 func handleProxyLinkAccess(w http.ResponseWriter, r *http.Request) {
 	ctx := server.GetReqCtx(r)
 	ctx.RedactURLPathValues(r, "token")
@@ -30,7 +83,7 @@ func handleProxyLinkAccess(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, link, headers, tunnelType, err := shared.UnwrapProxyLinkToken(encodedToken)
+	user, link, headers, tunnelType, err := shared.UnwrapProxyLinkToken(r, encodedToken)
 	if err != nil {
 		SendError(w, r, err)
 		return
@@ -42,27 +95,129 @@ func handleProxyLinkAccess(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var uw *proxy_usage.UsageResponseWriter
 	if isGetReq && user != "" {
-		cpStore := contentProxyConnectionStore.WithScope(user)
-
-		if limit := config.ContentProxyConnectionLimit.Get(user); limit > 0 {
-			activeConnectionCount, err := cpStore.Count()
-			if err != nil {
-				ctx.Log.Error("[proxy] failed to count connections", "error", err)
-			} else if activeConnectionCount >= limit {
-				store_video.Redirect(store_video.StoreVideoNameContentProxyLimitReached, w, r)
+		release, ok := acquireContentProxyConnection(ctx.Log, user, ctx.RequestId, core.GetRequestIP(r), link)
+		if !ok {
+			store_video.Redirect(store_video.StoreVideoNameContentProxyLimitReached, w, r)
+			return
+		}
+		defer release()
+
+		uw = newContentProxyResponseWriter(ctx.Log, w, r, user)
+		if uw.IsQuotaExceeded() {
+			store_video.Redirect(store_video.StoreVideoNameContentProxyLimitReached, w, r)
+			return
+		}
+		w = uw
+	}
+	r = r.WithContext(config.WithTunnelPoolKey(r.Context(), user))
+	bytesWritten, err := shared.ProxyLinkResponse(w, r, user, link, tunnelType)
+	ctx.Log.Info("[proxy] connection closed", "user", user, "size", util.ToSize(bytesWritten), "error", err)
+
+	if uw != nil {
+		if err := uw.Close(); err != nil {
+			ctx.Log.Error("[proxy] failed to record usage", "error", err)
+		}
+	}
+}
+
+// hlsSourceConnectionSeq makes the connection id unique for each of the
+// source requests made by ffmpeg.
+var hlsSourceConnectionSeq atomic.Uint64
+
+func handleProxyLinkHLS(w http.ResponseWriter, r *http.Request) {
+	ctx := server.GetReqCtx(r)
+	ctx.RedactURLPathValues(r, "token")
+
+	if !shared.IsMethod(r, http.MethodGet) && !shared.IsMethod(r, http.MethodHead) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	hls := content_proxy_hls.Default
+	if hls == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	encodedToken := r.PathValue("token")
+	user, link, headers, tunnelType, err := shared.UnwrapProxyLinkToken(r, encodedToken)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	variant := content_proxy_hls.Variant(r.PathValue("variant"))
+	fileName := r.PathValue("file")
+
+	if user != "" && fileName == content_proxy_hls.PlaylistFileName && isContentProxyQuotaExceeded(r, user) {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	log := ctx.Log
+	clientIp := core.GetRequestIP(r)
+	// source is requested by ffmpeg, concurrently for range requests, it goes through the same connection
+	// limit, rate limit and quota as the user's other connections.
+	source := func(w http.ResponseWriter, r *http.Request) {
+		r = server.SetReqCtx(r, &server.ReqCtx{
+			StartTime: time.Now(),
+			RequestId: ctx.RequestId,
+			ReqPath:   r.URL.Path,
+			ReqQuery:  r.URL.Query(),
+			Log:       log,
+		})
+
+		var uw *proxy_usage.UsageResponseWriter
+		if user != "" {
+			connId := ctx.RequestId + ":hls:" + strconv.FormatUint(hlsSourceConnectionSeq.Add(1), 10)
+			release, ok := acquireContentProxyConnection(log, user, connId, clientIp, link)
+			if !ok {
+				shared.ErrorForbidden(r).Send(w, r)
+				return
+			}
+			defer release()
+
+			uw = newContentProxyResponseWriter(log, w, r, user)
+			if uw.IsQuotaExceeded() {
+				shared.ErrorForbidden(r).Send(w, r)
 				return
 			}
+			w = uw
 		}
 
-		if err := cpStore.Set(ctx.RequestId, contentProxyConnection{IP: core.GetRequestIP(r), Link: link}); err != nil {
-			ctx.Log.Error("[proxy] failed to record connection", "error", err)
-		} else {
-			defer cpStore.Del(ctx.RequestId)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		r = r.WithContext(config.WithTunnelPoolKey(r.Context(), user))
+		bytesWritten, err := shared.ProxyLinkResponse(w, r, user, link, tunnelType)
+		log.Debug("[proxy] hls source connection closed", "user", user, "size", util.ToSize(bytesWritten), "error", err)
+
+		if uw != nil {
+			if err := uw.Close(); err != nil {
+				log.Error("[proxy] failed to record usage", "error", err)
+			}
 		}
 	}
-	bytesWritten, err := shared.ProxyResponse(w, r, link, tunnelType)
-	ctx.Log.Info("[proxy] connection closed", "user", user, "size", util.ToSize(bytesWritten), "error", err)
+
+	err = hls.Serve(w, r, content_proxy_hls.GetKey(encodedToken, variant), variant, fileName, source)
+	switch {
+	case err == nil:
+	case errors.Is(err, content_proxy_hls.ErrNotFound):
+		shared.ErrorNotFound(r).Send(w, r)
+	case errors.Is(err, content_proxy_hls.ErrTooManySessions):
+		e := core.NewAPIError("too many hls sessions")
+		e.InjectReq(r)
+		e.Code = core.ErrorCodeServiceUnavailable
+		e.StatusCode = http.StatusServiceUnavailable
+		e.Send(w, r)
+	case errors.Is(err, context.Canceled):
+	default:
+		e := shared.ErrorBadGateway(r, "failed to segment content")
+		e.Cause = err
+		e.Send(w, r)
+	}
 }
 
 type proxifyLinksData struct {
@@ -132,6 +287,14 @@ func handleProxifyLinks(w http.ResponseWriter, r *http.Request) {
 		ctx.RedactURLQueryParams(r, "token")
 	}
 
+	ipRange, err := proxy_token.ParseIPRange(r.Form.Get("ip_range"), core.GetRequestIP(r))
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid ip_range").Send(w, r)
+		return
+	}
+	scope := shared.ProxyLinkScope{Device: r.Form.Get("device"), IPRange: ipRange}
+	isRevocable := r.Form.Get("revocable") != "" || scope.Device != "" || scope.IPRange != ""
+
 	proxyLinks := make([]string, count)
 	for i, link := range links {
 		idx := strconv.Itoa(i)
@@ -152,7 +315,12 @@ func handleProxifyLinks(w http.ResponseWriter, r *http.Request) {
 			reqHeadersByBlob[reqHeadersBlob] = reqHeaders
 		}
 		filename := r.Form.Get("filename[" + idx + "]")
-		proxyLink, err := shared.CreateProxyLink(r, link, reqHeaders, config.TUNNEL_TYPE_AUTO, expiresIn, user, password, shouldEncrypt, filename)
+		var proxyLink string
+		if isRevocable {
+			proxyLink, err = shared.CreateRevocableProxyLink(r, link, reqHeaders, config.TUNNEL_TYPE_AUTO, expiresIn, user, password, shouldEncrypt, filename, scope)
+		} else {
+			proxyLink, err = shared.CreateProxyLink(r, link, reqHeaders, config.TUNNEL_TYPE_AUTO, expiresIn, user, password, shouldEncrypt, filename)
+		}
 		if err != nil {
 			SendError(w, r, err)
 			return
@@ -173,10 +341,95 @@ func handleProxifyLinks(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, r, 200, data, nil)
 }
 
+type proxyUsageQuota struct {
+	MonthlyBytes   int64 `json:"monthly_bytes"`
+	RemainingBytes int64 `json:"remaining_bytes"`
+	RateLimit      int64 `json:"rate_limit"`
+}
+
+type proxyUsageDay struct {
+	Date        string `json:"date"`
+	Bytes       int64  `json:"bytes"`
+	Connections int    `json:"conns"`
+}
+
+type proxyUsageData struct {
+	User  string            `json:"user"`
+	Today proxy_usage.Total `json:"today"`
+	Month proxy_usage.Total `json:"month"`
+	Quota proxyUsageQuota   `json:"quota"`
+	Daily []proxyUsageDay   `json:"daily"`
+}
+
+func handleProxyUsage(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	isAuthorized, user, _ := getProxyAuthorization(r, false)
+	if !isAuthorized {
+		w.Header().Add(server.HEADER_STREMTHRU_AUTHENTICATE, "Basic")
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d < 1 || d > 366 {
+			shared.ErrorBadRequest(r, "invalid days").Send(w, r)
+			return
+		}
+		days = d
+	}
+
+	now := time.Now()
+	items, err := proxy_usage.GetDaily(user, now.AddDate(0, 0, -(days-1)), now)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	month, err := proxy_usage.GetMonthlyTotal(user, now)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	data := proxyUsageData{
+		User:  user,
+		Month: *month,
+		Quota: proxyUsageQuota{
+			MonthlyBytes: config.ContentProxyQuota.GetMonthlyQuota(user),
+			RateLimit:    config.ContentProxyQuota.GetRateLimit(user),
+		},
+		Daily: make([]proxyUsageDay, len(items)),
+	}
+	if data.Quota.MonthlyBytes > 0 {
+		data.Quota.RemainingBytes = max(0, data.Quota.MonthlyBytes-month.Bytes)
+	}
+	today := proxy_usage.GetDate(now).String()
+	for i := range items {
+		item := &items[i]
+		data.Daily[i] = proxyUsageDay{
+			Date:        item.Date.String(),
+			Bytes:       item.Bytes,
+			Connections: item.Connections,
+		}
+		if data.Daily[i].Date == today {
+			data.Today = proxy_usage.Total{Bytes: item.Bytes, Connections: item.Connections}
+		}
+	}
+
+	SendResponse(w, r, 200, data, nil)
+}
+
 func AddProxyEndpoints(mux *http.ServeMux) {
 	withCors := shared.Middleware(shared.EnableCORS)
 
 	mux.HandleFunc("/v0/proxy", withCors(handleProxifyLinks))
+	mux.HandleFunc("/v0/proxy/usage", withCors(handleProxyUsage))
 	mux.HandleFunc("/v0/proxy/{token}", withCors(handleProxyLinkAccess))
 	mux.HandleFunc("/v0/proxy/{token}/{filename}", withCors(handleProxyLinkAccess))
+	mux.HandleFunc("/v0/proxy/{token}/hls/{variant}/{file}", withCors(handleProxyLinkHLS))
 }
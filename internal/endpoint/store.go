@@ -13,6 +13,7 @@ import (
 	"github.com/rodezfranco/stremthru/internal/shared"
 	store_util "github.com/rodezfranco/stremthru/internal/store/util"
 	store_video "github.com/rodezfranco/stremthru/internal/store/video"
+	store_webhook "github.com/rodezfranco/stremthru/internal/store/webhook"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
 	"github.com/rodezfranco/stremthru/store"
 )
@@ -202,7 +203,9 @@ func listMagnets(ctx *context.StoreContext, r *http.Request) (*store.ListMagnets
 		if data.Items == nil {
 			data.Items = []store.ListMagnetsDataItem{}
 		}
-		go store_util.RecordTorrentInfoFromListMagnets(ctx.Store.GetName().Code(), data.Items)
+		if ctx.Store.GetName() != store.StoreNameMulti {
+			go store_util.RecordTorrentInfoFromListMagnets(ctx.Store.GetName().Code(), data.Items)
+		}
 	}
 
 	return data, err
@@ -232,7 +235,8 @@ func addMagnet(ctx *context.StoreContext, magnet string) (*store.AddMagnetData,
 		params.ClientIP = ctx.ClientIP
 	}
 	data, err := ctx.Store.AddMagnet(params)
-	if err == nil {
+	// multi store tracks magnets against its member stores
+	if err == nil && ctx.Store.GetName() != store.StoreNameMulti {
 		buddy.TrackMagnet(ctx.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, ctx.StoreAuthToken)
 	}
 	return data, err
@@ -255,6 +259,9 @@ func handleStoreMagnetAdd(w http.ResponseWriter, r *http.Request) {
 	data, err := addMagnet(ctx, payload.Magnet)
 	if err == nil && data != nil {
 		data.Hash = strings.ToLower(data.Hash)
+		if ctx.IsProxyAuthorized {
+			store_webhook.TrackMagnet(ctx.ProxyAuthUser, ctx.Store.GetName(), ctx.StoreAuthToken, data)
+		}
 	}
 	SendResponse(w, r, 201, data, err)
 }
@@ -282,11 +289,18 @@ func getMagnet(ctx *context.StoreContext, magnetId string) (*store.GetMagnetData
 	}
 	data, err := ctx.Store.GetMagnet(params)
 	if err == nil {
-		buddy.TrackMagnet(ctx.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, ctx.StoreAuthToken)
+		trackMagnet(ctx, data)
 	}
 	return data, err
 }
 
+func trackMagnet(ctx *context.StoreContext, data *store.GetMagnetData) {
+	// multi store tracks magnets against its member stores
+	if ctx.Store.GetName() != store.StoreNameMulti {
+		buddy.TrackMagnet(ctx.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, ctx.StoreAuthToken)
+	}
+}
+
 func handleStoreMagnetGet(w http.ResponseWriter, r *http.Request) {
 	if !shared.IsMethod(r, http.MethodGet) {
 		shared.ErrorMethodNotAllowed(r).Send(w, r)
@@ -397,7 +411,14 @@ func AddStoreEndpoints(mux *http.ServeMux) {
 	mux.HandleFunc("/v0/store/magnets", withStore(handleStoreMagnets))
 	mux.HandleFunc("/v0/store/magnets/check", withStore(handleStoreMagnetsCheck))
 	mux.HandleFunc("/v0/store/magnets/{magnetId}", withStore(handleStoreMagnet))
+	mux.HandleFunc("/v0/store/magnets/{magnetId}/events", withStore(handleStoreMagnetEvents))
 	mux.HandleFunc("/v0/store/link/generate", withStore(handleStoreLinkGenerate))
+	mux.HandleFunc("/v0/store/newz", withStore(handleStoreNewzs))
+	mux.HandleFunc("/v0/store/newz/{newzId}", withStore(handleStoreNewz))
+	mux.HandleFunc("/v0/store/newz/link/generate", withStore(handleStoreNewzLinkGenerate))
+	mux.HandleFunc("/v0/store/webdl", withStore(handleStoreWebDLs))
+	mux.HandleFunc("/v0/store/webdl/{webdlId}", withStore(handleStoreWebDL))
+	mux.HandleFunc("/v0/store/webdl/link/generate", withStore(handleStoreWebDLLinkGenerate))
 
 	mux.HandleFunc("/v0/store/_/static/{video}", withCors(handleStatic))
 }
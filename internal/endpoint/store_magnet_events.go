@@ -0,0 +1,132 @@
+package endpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+const (
+	magnetEventsPollInterval    = 5 * time.Second
+	magnetEventsMaxPollInterval = 1 * time.Minute
+	magnetEventsMaxDuration     = 1 * time.Hour
+	// stream is ended if the magnet does not change for this many polls
+	magnetEventsMaxUnchangedPolls = 20
+)
+
+func writeServerSentEvent(w http.ResponseWriter, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// handleStoreMagnetEvents streams the magnet as server-sent events, pushing
+// every change until it reaches a final status. The poll interval is doubled
+// while the magnet does not change, and the stream is ended if it stays
+// unchanged for too long or runs past the max duration.
+func handleStoreMagnetEvents(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	magnetId := r.PathValue("magnetId")
+	if magnetId == "" {
+		shared.ErrorBadRequest(r, "missing magnetId").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	params := &store.GetMagnetParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Id = magnetId
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+
+	data, err := ctx.Store.GetMagnet(params)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	interval := magnetEventsPollInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	deadline := time.Now().Add(magnetEventsMaxDuration)
+
+	var lastEvent []byte
+	unchangedPolls := 0
+	for {
+		if err != nil {
+			var e core.StremThruError
+			if sterr, ok := err.(core.StremThruError); ok {
+				e = sterr
+			} else {
+				e = &core.Error{Cause: err}
+			}
+			e.Pack(r)
+			event, _ := json.Marshal(e.GetError())
+			writeServerSentEvent(w, "error", event)
+			rc.Flush()
+			return
+		}
+
+		data.Hash = strings.ToLower(data.Hash)
+		event, merr := json.Marshal(data)
+		if merr != nil {
+			return
+		}
+		if bytes.Equal(event, lastEvent) {
+			unchangedPolls++
+			interval = min(2*interval, magnetEventsMaxPollInterval)
+			// keep the connection alive
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		} else {
+			unchangedPolls = 0
+			interval = magnetEventsPollInterval
+			if err := writeServerSentEvent(w, "magnet", event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		lastEvent = event
+
+		if data.Status.IsFinal() {
+			trackMagnet(ctx, data)
+			return
+		}
+
+		if unchangedPolls >= magnetEventsMaxUnchangedPolls || time.Now().Add(interval).After(deadline) {
+			writeServerSentEvent(w, "end", []byte(`{"status":"`+string(data.Status)+`"}`))
+			rc.Flush()
+			return
+		}
+
+		timer.Reset(interval)
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+
+		data, err = ctx.Store.GetMagnet(params)
+	}
+}
@@ -0,0 +1,221 @@
+package endpoint
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+func getNewsStore(ctx *context.StoreContext) (store.NewsStore, error) {
+	newsStore, ok := ctx.Store.(store.NewsStore)
+	if !ok {
+		return nil, store.ErrorNewsNotSupported(ctx.Store.GetName())
+	}
+	return newsStore, nil
+}
+
+type AddNewzPayload struct {
+	Link     string `json:"link"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func addNewz(ctx *context.StoreContext, payload *AddNewzPayload) (*store.AddNewsData, error) {
+	newsStore, err := getNewsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.AddNewsParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Link = payload.Link
+	params.Name = payload.Name
+	params.Password = payload.Password
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+	return newsStore.AddNews(params)
+}
+
+func handleStoreNewzAdd(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &AddNewzPayload{}
+	err := shared.ReadRequestBodyJSON(r, payload)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	if payload.Link == "" {
+		shared.ErrorBadRequest(r, "missing link").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := addNewz(ctx, payload)
+	if err == nil && data != nil {
+		data.Hash = strings.ToLower(data.Hash)
+	}
+	SendResponse(w, r, 201, data, err)
+}
+
+func listNewz(ctx *context.StoreContext, r *http.Request) (*store.ListNewsData, error) {
+	newsStore, err := getNewsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := r.URL.Query()
+	limit, err := GetQueryInt(queryParams, "limit", 100)
+	if err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+	limit = max(1, min(limit, 500))
+	offset, err := GetQueryInt(queryParams, "offset", 0)
+	if err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+
+	params := &store.ListNewsParams{
+		Limit:    limit,
+		Offset:   offset,
+		ClientIP: ctx.ClientIP,
+	}
+	params.APIKey = ctx.StoreAuthToken
+	data, err := newsStore.ListNews(params)
+	if err == nil && data.Items == nil {
+		data.Items = []store.ListNewsDataItem{}
+	}
+	return data, err
+}
+
+func handleStoreNewzList(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := listNewz(ctx, r)
+	if err == nil && data != nil {
+		for i := range data.Items {
+			item := &data.Items[i]
+			item.Hash = strings.ToLower(item.Hash)
+		}
+	}
+	SendResponse(w, r, 200, data, err)
+}
+
+func handleStoreNewzs(w http.ResponseWriter, r *http.Request) {
+	if shared.IsMethod(r, http.MethodGet) {
+		handleStoreNewzList(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodPost) {
+		handleStoreNewzAdd(w, r)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func getNewz(ctx *context.StoreContext, newzId string) (*store.GetNewsData, error) {
+	newsStore, err := getNewsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.GetNewsParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Id = newzId
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+	return newsStore.GetNews(params)
+}
+
+func handleStoreNewzGet(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	newzId := r.PathValue("newzId")
+	if newzId == "" {
+		shared.ErrorBadRequest(r, "missing newzId").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := getNewz(ctx, newzId)
+	if err == nil && data != nil {
+		data.Hash = strings.ToLower(data.Hash)
+	}
+	SendResponse(w, r, 200, data, err)
+}
+
+func removeNewz(ctx *context.StoreContext, newzId string) (*store.RemoveNewsData, error) {
+	newsStore, err := getNewsStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.RemoveNewsParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Id = newzId
+	return newsStore.RemoveNews(params)
+}
+
+func handleStoreNewzRemove(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	newzId := r.PathValue("newzId")
+	if newzId == "" {
+		shared.ErrorBadRequest(r, "missing newzId").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := removeNewz(ctx, newzId)
+	SendResponse(w, r, 200, data, err)
+}
+
+func handleStoreNewz(w http.ResponseWriter, r *http.Request) {
+	if shared.IsMethod(r, http.MethodGet) {
+		handleStoreNewzGet(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodDelete) {
+		handleStoreNewzRemove(w, r)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func handleStoreNewzLinkGenerate(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &GenerateLinkPayload{}
+	err := shared.ReadRequestBodyJSON(r, payload)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	link, err := shared.GenerateStremThruNewsLink(r, ctx, payload.Link)
+	SendResponse(w, r, 200, link, err)
+}
@@ -0,0 +1,221 @@
+package endpoint
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+func getWebDLStore(ctx *context.StoreContext) (store.WebDLStore, error) {
+	webdlStore, ok := ctx.Store.(store.WebDLStore)
+	if !ok {
+		return nil, store.ErrorWebDLNotSupported(ctx.Store.GetName())
+	}
+	return webdlStore, nil
+}
+
+type AddWebDLPayload struct {
+	Link     string `json:"link"`
+	Password string `json:"password"`
+}
+
+func addWebDL(ctx *context.StoreContext, payload *AddWebDLPayload) (*store.AddWebDLData, error) {
+	webdlStore, err := getWebDLStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.AddWebDLParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Link = payload.Link
+	params.Password = payload.Password
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+	return webdlStore.AddWebDL(params)
+}
+
+func handleStoreWebDLAdd(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &AddWebDLPayload{}
+	err := shared.ReadRequestBodyJSON(r, payload)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	if payload.Link == "" {
+		shared.ErrorBadRequest(r, "missing link").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := addWebDL(ctx, payload)
+	if err == nil && data != nil {
+		data.Hash = strings.ToLower(data.Hash)
+	}
+	SendResponse(w, r, 201, data, err)
+}
+
+func listWebDLs(ctx *context.StoreContext, r *http.Request) (*store.ListWebDLsData, error) {
+	webdlStore, err := getWebDLStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := r.URL.Query()
+	limit, err := GetQueryInt(queryParams, "limit", 100)
+	if err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	offset, err := GetQueryInt(queryParams, "offset", 0)
+	if err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+
+	params := &store.ListWebDLsParams{
+		Limit:    limit,
+		Offset:   offset,
+		ClientIP: ctx.ClientIP,
+	}
+	params.APIKey = ctx.StoreAuthToken
+	data, err := webdlStore.ListWebDLs(params)
+	if err == nil && data.Items == nil {
+		data.Items = []store.ListWebDLsDataItem{}
+	}
+	return data, err
+}
+
+func handleStoreWebDLList(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := listWebDLs(ctx, r)
+	if err == nil && data != nil {
+		for i := range data.Items {
+			item := &data.Items[i]
+			item.Hash = strings.ToLower(item.Hash)
+		}
+	}
+	SendResponse(w, r, 200, data, err)
+}
+
+func handleStoreWebDLs(w http.ResponseWriter, r *http.Request) {
+	if shared.IsMethod(r, http.MethodGet) {
+		handleStoreWebDLList(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodPost) {
+		handleStoreWebDLAdd(w, r)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func getWebDL(ctx *context.StoreContext, webdlId string) (*store.GetWebDLData, error) {
+	webdlStore, err := getWebDLStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.GetWebDLParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Id = webdlId
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+	return webdlStore.GetWebDL(params)
+}
+
+func handleStoreWebDLGet(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	webdlId := r.PathValue("webdlId")
+	if webdlId == "" {
+		shared.ErrorBadRequest(r, "missing webdlId").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := getWebDL(ctx, webdlId)
+	if err == nil && data != nil {
+		data.Hash = strings.ToLower(data.Hash)
+	}
+	SendResponse(w, r, 200, data, err)
+}
+
+func removeWebDL(ctx *context.StoreContext, webdlId string) (*store.RemoveWebDLData, error) {
+	webdlStore, err := getWebDLStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &store.RemoveWebDLParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Id = webdlId
+	return webdlStore.RemoveWebDL(params)
+}
+
+func handleStoreWebDLRemove(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	webdlId := r.PathValue("webdlId")
+	if webdlId == "" {
+		shared.ErrorBadRequest(r, "missing webdlId").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	data, err := removeWebDL(ctx, webdlId)
+	SendResponse(w, r, 200, data, err)
+}
+
+func handleStoreWebDL(w http.ResponseWriter, r *http.Request) {
+	if shared.IsMethod(r, http.MethodGet) {
+		handleStoreWebDLGet(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodDelete) {
+		handleStoreWebDLRemove(w, r)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func handleStoreWebDLLinkGenerate(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &GenerateLinkPayload{}
+	err := shared.ReadRequestBodyJSON(r, payload)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	link, err := shared.GenerateStremThruWebDLLink(r, ctx, payload.Link)
+	SendResponse(w, r, 200, link, err)
+}
@@ -24,6 +24,7 @@ func AddStremioEndpoints(mux *http.ServeMux) {
 	}
 	if config.Feature.IsEnabled(config.FeatureStremioWrap) {
 		stremio_wrap.AddStremioWrapEndpoints(mux)
+		AddStremioTransformerEndpoints(mux)
 	}
 	if config.Feature.IsEnabled(config.FeatureStremioSidekick) {
 		stremio_sidekick.AddStremioSidekickEndpoints(mux)
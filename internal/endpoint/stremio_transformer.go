@@ -0,0 +1,284 @@
+package endpoint
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rodezfranco/stremthru/internal/shared"
+	stremio_transformer "github.com/rodezfranco/stremthru/internal/stremio/transformer"
+	stremio_wrap "github.com/rodezfranco/stremthru/internal/stremio/wrap"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+type stremioTransformerDryRun[V any] func(value V, sType string, streams []stremio.Stream) ([]stremio_wrap.TransformerDryRunResult, error)
+
+type StremioTransformerEntityPayload[V any] struct {
+	Id            string           `json:"id"`
+	Value         V                `json:"value"`
+	Type          string           `json:"type"`
+	SampleStreams []stremio.Stream `json:"sample_streams"`
+}
+
+type StremioTransformerValidatePayload struct {
+	Type    string           `json:"type"`
+	Streams []stremio.Stream `json:"streams"`
+}
+
+type StremioTransformerDryRunPayload struct {
+	ExtractorId string                                  `json:"extractor_id"`
+	Extractor   stremio_transformer.StreamExtractorBlob `json:"extractor"`
+	TemplateId  string                                  `json:"template_id"`
+	Template    stremio_transformer.StreamTemplateBlob  `json:"template"`
+	Type        string                                  `json:"type"`
+	Streams     []stremio.Stream                        `json:"streams"`
+}
+
+type StremioTransformerDryRunData struct {
+	Items []stremio_wrap.TransformerDryRunResult `json:"items"`
+}
+
+type ListStremioTransformerEntitiesData[V any] struct {
+	Items []stremio_wrap.TransformerEntity[V] `json:"items"`
+}
+
+type ListStremioTransformerEntityVersionsData[V any] struct {
+	Items []stremio_wrap.TransformerEntityVersion[V] `json:"items"`
+}
+
+func sendStremioTransformerError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case stremio_wrap.ErrTransformerEntityNotFound:
+		shared.ErrorNotFound(r).Send(w, r)
+	case stremio_wrap.ErrTransformerEntityBuiltIn:
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+	default:
+		SendError(w, r, err)
+	}
+}
+
+// validateStremioTransformerEntity checks the value can be parsed, and the
+// sample streams (if any) are transformed without error.
+func validateStremioTransformerEntity[V any](r *http.Request, entities *stremio_wrap.TransformerEntityStore[V], dryRun stremioTransformerDryRun[V], payload *StremioTransformerEntityPayload[V]) error {
+	if err := entities.Validate(payload.Value); err != nil {
+		return shared.ErrorBadRequest(r, "invalid value: "+err.Error())
+	}
+	if len(payload.SampleStreams) == 0 {
+		return nil
+	}
+	results, err := dryRun(payload.Value, payload.Type, payload.SampleStreams)
+	if err != nil {
+		return shared.ErrorBadRequest(r, "invalid value: "+err.Error())
+	}
+	for i := range results {
+		if results[i].Error != "" {
+			return shared.ErrorBadRequest(r, "failed to transform sample stream "+strconv.Itoa(i)+": "+results[i].Error)
+		}
+	}
+	return nil
+}
+
+func handleStremioTransformerEntities[V any](entities *stremio_wrap.TransformerEntityStore[V], dryRun stremioTransformerDryRun[V]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			items, err := entities.List()
+			SendResponse(w, r, 200, ListStremioTransformerEntitiesData[V]{Items: items}, err)
+		case http.MethodPost:
+			payload := &StremioTransformerEntityPayload[V]{}
+			if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+				SendError(w, r, err)
+				return
+			}
+			if payload.Id == "" {
+				shared.ErrorBadRequest(r, "missing id").Send(w, r)
+				return
+			}
+			if _, err := entities.Get(payload.Id); err == nil {
+				shared.ErrorConflict(r, "already exists").Send(w, r)
+				return
+			} else if err != stremio_wrap.ErrTransformerEntityNotFound {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			if err := validateStremioTransformerEntity(r, entities, dryRun, payload); err != nil {
+				SendError(w, r, err)
+				return
+			}
+			entity, err := entities.Save(payload.Id, payload.Value)
+			if err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			SendResponse(w, r, 201, entity, nil)
+		default:
+			shared.ErrorMethodNotAllowed(r).Send(w, r)
+		}
+	}
+}
+
+func handleStremioTransformerEntity[V any](entities *stremio_wrap.TransformerEntityStore[V], dryRun stremioTransformerDryRun[V]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		switch r.Method {
+		case http.MethodGet:
+			entity, err := entities.Get(id)
+			if err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			SendResponse(w, r, 200, entity, nil)
+		case http.MethodPut:
+			payload := &StremioTransformerEntityPayload[V]{}
+			if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+				SendError(w, r, err)
+				return
+			}
+			if _, err := entities.Get(id); err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			if err := validateStremioTransformerEntity(r, entities, dryRun, payload); err != nil {
+				SendError(w, r, err)
+				return
+			}
+			entity, err := entities.Save(id, payload.Value)
+			if err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			SendResponse(w, r, 200, entity, nil)
+		case http.MethodDelete:
+			if _, err := entities.Get(id); err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			if err := entities.Delete(id); err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			w.WriteHeader(204)
+		default:
+			shared.ErrorMethodNotAllowed(r).Send(w, r)
+		}
+	}
+}
+
+func handleStremioTransformerEntityVersions[V any](entities *stremio_wrap.TransformerEntityStore[V]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !shared.IsMethod(r, http.MethodGet) {
+			shared.ErrorMethodNotAllowed(r).Send(w, r)
+			return
+		}
+
+		id := r.PathValue("id")
+		if versionStr := r.PathValue("version"); versionStr != "" {
+			version, err := strconv.Atoi(versionStr)
+			if err != nil {
+				shared.ErrorBadRequest(r, "invalid version").Send(w, r)
+				return
+			}
+			item, err := entities.GetVersion(id, version)
+			if err != nil {
+				sendStremioTransformerError(w, r, err)
+				return
+			}
+			SendResponse(w, r, 200, item, nil)
+			return
+		}
+
+		items, err := entities.ListVersions(id)
+		SendResponse(w, r, 200, ListStremioTransformerEntityVersionsData[V]{Items: items}, err)
+	}
+}
+
+func handleStremioTransformerEntityValidate[V any](entities *stremio_wrap.TransformerEntityStore[V], dryRun stremioTransformerDryRun[V]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !shared.IsMethod(r, http.MethodPost) {
+			shared.ErrorMethodNotAllowed(r).Send(w, r)
+			return
+		}
+
+		payload := &StremioTransformerValidatePayload{}
+		if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		entity, err := entities.Get(r.PathValue("id"))
+		if err != nil {
+			sendStremioTransformerError(w, r, err)
+			return
+		}
+
+		items, err := dryRun(entity.Value, payload.Type, payload.Streams)
+		SendResponse(w, r, 200, StremioTransformerDryRunData{Items: items}, err)
+	}
+}
+
+func handleStremioTransformerDryRun(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &StremioTransformerDryRunPayload{}
+	if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	extractor := payload.Extractor
+	if payload.ExtractorId != "" {
+		entity, err := stremio_wrap.TransformerExtractors.Get(payload.ExtractorId)
+		if err != nil {
+			sendStremioTransformerError(w, r, err)
+			return
+		}
+		extractor = entity.Value
+	}
+
+	template := payload.Template
+	if payload.TemplateId != "" {
+		entity, err := stremio_wrap.TransformerTemplates.Get(payload.TemplateId)
+		if err != nil {
+			sendStremioTransformerError(w, r, err)
+			return
+		}
+		template = entity.Value
+	}
+
+	items, err := stremio_wrap.DryRunTransformer(extractor, template, payload.Type, payload.Streams)
+	if err != nil {
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+		return
+	}
+	SendResponse(w, r, 200, StremioTransformerDryRunData{Items: items}, nil)
+}
+
+func dryRunStremioTransformerExtractor(value stremio_transformer.StreamExtractorBlob, sType string, streams []stremio.Stream) ([]stremio_wrap.TransformerDryRunResult, error) {
+	return stremio_wrap.DryRunTransformer(value, stremio_transformer.StreamTemplateBlob{}, sType, streams)
+}
+
+func dryRunStremioTransformerTemplate(value stremio_transformer.StreamTemplateBlob, sType string, streams []stremio.Stream) ([]stremio_wrap.TransformerDryRunResult, error) {
+	return stremio_wrap.DryRunTransformer("", value, sType, streams)
+}
+
+func AddStremioTransformerEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	extractors := stremio_wrap.TransformerExtractors
+	mux.HandleFunc("/v0/stremio/transformer/extractors", withAdminAuth(handleStremioTransformerEntities(extractors, dryRunStremioTransformerExtractor)))
+	mux.HandleFunc("/v0/stremio/transformer/extractors/{id}", withAdminAuth(handleStremioTransformerEntity(extractors, dryRunStremioTransformerExtractor)))
+	mux.HandleFunc("/v0/stremio/transformer/extractors/{id}/versions", withAdminAuth(handleStremioTransformerEntityVersions(extractors)))
+	mux.HandleFunc("/v0/stremio/transformer/extractors/{id}/versions/{version}", withAdminAuth(handleStremioTransformerEntityVersions(extractors)))
+	mux.HandleFunc("/v0/stremio/transformer/extractors/{id}/validate", withAdminAuth(handleStremioTransformerEntityValidate(extractors, dryRunStremioTransformerExtractor)))
+
+	templates := stremio_wrap.TransformerTemplates
+	mux.HandleFunc("/v0/stremio/transformer/templates", withAdminAuth(handleStremioTransformerEntities(templates, dryRunStremioTransformerTemplate)))
+	mux.HandleFunc("/v0/stremio/transformer/templates/{id}", withAdminAuth(handleStremioTransformerEntity(templates, dryRunStremioTransformerTemplate)))
+	mux.HandleFunc("/v0/stremio/transformer/templates/{id}/versions", withAdminAuth(handleStremioTransformerEntityVersions(templates)))
+	mux.HandleFunc("/v0/stremio/transformer/templates/{id}/versions/{version}", withAdminAuth(handleStremioTransformerEntityVersions(templates)))
+	mux.HandleFunc("/v0/stremio/transformer/templates/{id}/validate", withAdminAuth(handleStremioTransformerEntityValidate(templates, dryRunStremioTransformerTemplate)))
+
+	mux.HandleFunc("/v0/stremio/transformer/dry-run", withAdminAuth(handleStremioTransformerDryRun))
+}
@@ -3,10 +3,26 @@ package endpoint
 import (
 	"net/http"
 
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	"github.com/rodezfranco/stremthru/internal/torznab"
 )
 
+// isTorznabAuthorized checks the proxy auth from the header, or from the
+// `apikey` parameter used by torznab clients.
+func isTorznabAuthorized(r *http.Request) bool {
+	if isAuthorized, _, _ := getProxyAuthorization(r, false); isAuthorized {
+		return true
+	}
+	apikey := r.URL.Query().Get("apikey")
+	if apikey == "" {
+		return false
+	}
+	auth, err := core.ParseBasicAuth(apikey)
+	return err == nil && auth.Password != "" && config.ProxyAuthPassword.GetPassword(auth.Username) == auth.Password
+}
+
 func handleTorznab(w http.ResponseWriter, r *http.Request) {
 	t := r.URL.Query().Get("t")
 
@@ -25,12 +41,24 @@ func handleTorznab(w http.ResponseWriter, r *http.Request) {
 			shared.SendXML(w, r, 200, torznab.ErrorIncorrectParameter(err.Error()))
 			return
 		}
-		items, err := torznab.StremThruIndexer.Search(query)
+		// upstream indexers are queried with the operator's credentials,
+		// so their results are only included for authorized requests.
+		withUpstream := len(torznab.UpstreamIndexers) > 0 && isTorznabAuthorized(r)
+		var items []torznab.ResultItem
+		if withUpstream {
+			items, err = torznab.Search(query)
+		} else {
+			items, err = torznab.StremThruIndexer.Search(query)
+		}
 		if err != nil {
 			shared.SendXML(w, r, 200, torznab.ErrorUnknownError(err.Error()))
 			return
 		}
-		w.Header().Set("Cache-Control", "public, max-age=7200")
+		if withUpstream {
+			w.Header().Set("Cache-Control", "private, no-store")
+		} else {
+			w.Header().Set("Cache-Control", "public, max-age=7200")
+		}
 		shared.SendXML(w, r, 200, torznab.ResultFeed{
 			Info:  torznab.StremThruIndexer.Info(),
 			Items: items,
@@ -695,3 +695,69 @@ func GetIdMapsByLetterboxdId(letterboxdIds []string) (map[string]IMDBTitleMap, e
 	}
 	return idMapById, nil
 }
+
+func getIdMapsByColumnQuery(column string) string {
+	return fmt.Sprintf(
+		`SELECT %s, coalesce(it.%s, '') AS item_type FROM %s itm LEFT JOIN %s it ON itm.%s = it.%s WHERE itm.%s = ?`,
+		db.JoinPrefixedColumnNames(
+			"itm.",
+			MapColumn.IMDBId,
+			MapColumn.TMDBId,
+			MapColumn.TVDBId,
+			MapColumn.TraktId,
+			MapColumn.LetterboxdId,
+			MapColumn.MALId,
+		),
+		Column.Type,
+		MapTableName,
+		TableName,
+		MapColumn.IMDBId,
+		Column.TId,
+		column,
+	)
+}
+
+func getIdMapsByColumn(query string, id string) ([]IMDBTitleMap, error) {
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	idMaps := []IMDBTitleMap{}
+	for rows.Next() {
+		idMap := IMDBTitleMap{}
+		if err := rows.Scan(
+			&idMap.IMDBId,
+			&idMap.TMDBId,
+			&idMap.TVDBId,
+			&idMap.TraktId,
+			&idMap.LetterboxdId,
+			&idMap.MALId,
+			&idMap.Type,
+		); err != nil {
+			return nil, err
+		}
+		idMaps = append(idMaps, idMap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return idMaps, nil
+}
+
+var query_get_id_maps_by_tmdb_id = getIdMapsByColumnQuery(MapColumn.TMDBId)
+
+// GetIdMapsByTMDBId can return multiple maps, since TMDB ids are only unique
+// per title type.
+func GetIdMapsByTMDBId(tmdbId string) ([]IMDBTitleMap, error) {
+	return getIdMapsByColumn(query_get_id_maps_by_tmdb_id, tmdbId)
+}
+
+var query_get_id_maps_by_trakt_id = getIdMapsByColumnQuery(MapColumn.TraktId)
+
+// GetIdMapsByTraktId can return multiple maps, since Trakt ids are only unique
+// per title type.
+func GetIdMapsByTraktId(traktId string) ([]IMDBTitleMap, error) {
+	return getIdMapsByColumn(query_get_id_maps_by_trakt_id, traktId)
+}
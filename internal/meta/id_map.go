@@ -48,9 +48,27 @@ func ParseId(idStr string) (provider IdProvider, id string) {
 	if tvdbId, ok := strings.CutPrefix(idStr, "tvdb:"); ok {
 		return IdProviderTVDB, tvdbId
 	}
+	if tmdbId, ok := strings.CutPrefix(idStr, "tmdb:"); ok {
+		return IdProviderTMDB, tmdbId
+	}
+	if traktId, ok := strings.CutPrefix(idStr, "trakt:"); ok {
+		return IdProviderTrakt, traktId
+	}
 	return "", ""
 }
 
+// pickIdMap picks the map matching the id type, since TMDB and Trakt ids are
+// only unique per type.
+func pickIdMap(idType IdType, idms []imdb_title.IMDBTitleMap) *imdb_title.IMDBTitleMap {
+	for i := range idms {
+		idm := &idms[i]
+		if idType == IdTypeUnknown || IdType(idm.Type.ToSimple()) == idType {
+			return idm
+		}
+	}
+	return nil
+}
+
 var ErrorUnsupportedId = errors.New("unsupported id")
 var ErrorUnsupportedIdAnchor = errors.New("unsupported id anchor")
 
@@ -91,6 +109,28 @@ func GetIdMap(idType IdType, idStr string) (*IdMap, error) {
 			idMap.TVDB = id
 			idMap.Trakt = idm.TraktId
 			idMap.Letterboxd = idm.LetterboxdId
+		case IdProviderTMDB, IdProviderTrakt:
+			var idms []imdb_title.IMDBTitleMap
+			var err error
+			if idProvider == IdProviderTMDB {
+				idms, err = imdb_title.GetIdMapsByTMDBId(id)
+			} else {
+				idms, err = imdb_title.GetIdMapsByTraktId(id)
+			}
+			if err != nil {
+				return nil, err
+			}
+			idMap.IMDB = ""
+			idm := pickIdMap(idType, idms)
+			if idm == nil {
+				return &idMap, nil
+			}
+			idMap.Type = IdType(idm.Type.ToSimple())
+			idMap.IMDB = idm.IMDBId
+			idMap.TMDB = idm.TMDBId
+			idMap.TVDB = idm.TVDBId
+			idMap.Trakt = idm.TraktId
+			idMap.Letterboxd = idm.LetterboxdId
 		default:
 			return nil, ErrorUnsupportedId
 		}
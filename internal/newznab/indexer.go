@@ -0,0 +1,177 @@
+package newznab
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/nzb_info"
+	"github.com/rodezfranco/stremthru/internal/torznab"
+)
+
+const defaultLimit = 100
+const maxLimit = 500
+
+var Info = torznab.Info{
+	Title:       "StremThru",
+	Description: "StremThru Newznab",
+}
+
+var Caps = torznab.Caps{
+	Server: &torznab.CapsServer{
+		Title:     "StremThru",
+		Strapline: "StremThru Newznab",
+		Image:     "https://emojiapi.dev/api/v1/sparkles/256.png",
+		URL:       config.BaseURL.String(),
+		Version:   "1.0",
+	},
+	Limits: &torznab.CapsLimits{
+		Max:     maxLimit,
+		Default: defaultLimit,
+	},
+	Searching: []torznab.CapsSearchingItem{
+		{
+			Name:            "search",
+			Available:       true,
+			SupportedParams: []string{"q", "year"},
+		},
+		{
+			Name:            "tv-search",
+			Available:       true,
+			SupportedParams: []string{"q", "year", "season", "ep", "imdbid", "tvdbid", "tmdbid", "traktid"},
+		},
+		{
+			Name:            "movie-search",
+			Available:       true,
+			SupportedParams: []string{"q", "year", "imdbid", "tmdbid", "traktid"},
+		},
+	},
+	Categories: []torznab.CapsCategory{
+		{Category: torznab.CategoryMovies},
+		{Category: torznab.CategoryTV},
+	},
+}
+
+func toCategory(c nzb_info.NZBInfoCategory) torznab.Category {
+	switch c {
+	case nzb_info.NZBInfoCategoryMovie:
+		return torznab.CategoryMovies
+	case nzb_info.NZBInfoCategorySeries:
+		return torznab.CategoryTV
+	case nzb_info.NZBInfoCategoryXXX:
+		return torznab.CategoryXXX
+	default:
+		return torznab.CategoryOther
+	}
+}
+
+func getCategories(q torznab.Query) []nzb_info.NZBInfoCategory {
+	switch q.Type {
+	case "movie":
+		return []nzb_info.NZBInfoCategory{nzb_info.NZBInfoCategoryMovie}
+	case "tvsearch":
+		return []nzb_info.NZBInfoCategory{nzb_info.NZBInfoCategorySeries}
+	}
+	hasMovieCat, hasTvCat := q.HasMovies(), q.HasTVShows()
+	if hasMovieCat && !hasTvCat {
+		return []nzb_info.NZBInfoCategory{nzb_info.NZBInfoCategoryMovie}
+	}
+	if !hasMovieCat && hasTvCat {
+		return []nzb_info.NZBInfoCategory{nzb_info.NZBInfoCategorySeries}
+	}
+	return nil
+}
+
+// GetLink returns the url for downloading the nzb through the newznab api.
+func GetLink(baseURL *url.URL, hash string) string {
+	u := baseURL.JoinPath("/v0/newznab/api")
+	u.RawQuery = url.Values{"t": {"get"}, "id": {hash}}.Encode()
+	return u.String()
+}
+
+// Search looks up the local nzb index. The result items link back to the
+// `get` function, using baseURL.
+func Search(q torznab.Query, baseURL *url.URL) ([]ResultItem, error) {
+	if q.IMDBId == "" && (q.TVDBId != "" || q.TMDBId != "" || q.TraktId != "") {
+		imdbId, err := q.ResolveIMDBId()
+		if err != nil {
+			return nil, err
+		}
+		if imdbId == "" {
+			log.Debug("no imdb id found for query", "tvdbid", q.TVDBId, "tmdbid", q.TMDBId, "traktid", q.TraktId)
+			return []ResultItem{}, nil
+		}
+		q.IMDBId = imdbId
+	}
+
+	params := nzb_info.SearchParams{
+		Query:      q.Q,
+		Categories: getCategories(q),
+		Year:       q.Year,
+		Season:     q.Season,
+		Episode:    q.Ep,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+	}
+	if q.IMDBId != "" {
+		params.IMDBIds = []string{q.IMDBId}
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultLimit
+	}
+	params.Limit = min(params.Limit, maxLimit)
+
+	nInfos, err := nzb_info.Search(params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ResultItem, len(nInfos))
+	for i := range nInfos {
+		nInfo := &nInfos[i]
+		item := ResultItem{
+			Category:    toCategory(nInfo.Category),
+			GUID:        nInfo.Hash,
+			PublishDate: nInfo.CreatedAt.Time,
+			Title:       nInfo.Name,
+			IMDB:        nInfo.IMDBId,
+			Resolution:  nInfo.Resolution,
+			Size:        nInfo.Size,
+			Year:        nInfo.Year,
+		}
+		// the nzb is downloadable only if the original link is known,
+		// the stores' usenet lists do not have it.
+		if isValidNZBLink(nInfo.Link) {
+			item.Link = GetLink(baseURL, nInfo.Hash)
+		}
+		if len(nInfo.Seasons) == 1 {
+			item.Season = strconv.Itoa(nInfo.Seasons[0])
+		}
+		if len(nInfo.Episodes) == 1 {
+			item.Episode = strconv.Itoa(nInfo.Episodes[0])
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func isValidNZBLink(link string) bool {
+	if link == "" {
+		return false
+	}
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// GetNZBLink returns the original link of the nzb, if known. Only http(s)
+// links are returned, the links pushed by peers are not trusted otherwise.
+func GetNZBLink(id string) (string, error) {
+	nInfo, err := nzb_info.GetByHash(id)
+	if err != nil {
+		return "", err
+	}
+	if nInfo == nil || !isValidNZBLink(nInfo.Link) {
+		return "", torznab.ErrorNoSuchItem
+	}
+	return nInfo.Link, nil
+}
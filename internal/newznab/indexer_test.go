@@ -0,0 +1,16 @@
+package newznab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidNZBLink(t *testing.T) {
+	assert.True(t, isValidNZBLink("https://indexer.local/getnzb/abc.nzb"))
+	assert.True(t, isValidNZBLink("http://indexer.local/getnzb/abc.nzb"))
+	assert.False(t, isValidNZBLink(""))
+	assert.False(t, isValidNZBLink("javascript:alert(1)"))
+	assert.False(t, isValidNZBLink("file:///etc/passwd"))
+	assert.False(t, isValidNZBLink("//indexer.local/getnzb/abc.nzb"))
+}
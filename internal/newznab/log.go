@@ -0,0 +1,5 @@
+package newznab
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("newznab")
@@ -0,0 +1,127 @@
+package newznab
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/torznab"
+)
+
+const rfc822 = "Mon, 02 Jan 2006 15:04:05 -0700"
+
+type ChannelItemEnclosure struct {
+	XMLName xml.Name `xml:"enclosure"`
+	URL     string   `xml:"url,attr,omitempty"`
+	Length  int64    `xml:"length,attr,omitempty"`
+	Type    string   `xml:"type,attr,omitempty"`
+}
+
+type ChannelItemAttribute struct {
+	XMLName xml.Name `xml:"newznab:attr"`
+	Name    string   `xml:"name,attr"`
+	Value   string   `xml:"value,attr"`
+}
+
+type ChannelItem struct {
+	XMLName xml.Name `xml:"item"`
+
+	// standard rss elements
+	Category    string               `xml:"category,omitempty"`
+	Enclosure   ChannelItemEnclosure `xml:"enclosure,omitempty"`
+	GUID        string               `xml:"guid,omitempty"`
+	Link        string               `xml:"link,omitempty"`
+	PublishDate string               `xml:"pubDate,omitempty"`
+	Title       string               `xml:"title,omitempty"`
+
+	Attributes []ChannelItemAttribute
+}
+
+type Channel struct {
+	XMLName     xml.Name `xml:"channel"`
+	Title       string   `xml:"title,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Link        string   `xml:"link,omitempty"`
+	Items       []ResultItem
+}
+
+type RSS struct {
+	XMLName          xml.Name `xml:"rss"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr"`
+	NewznabNamespace string   `xml:"xmlns:newznab,attr"`
+	Version          string   `xml:"version,attr,omitempty"`
+	Channel          Channel  `xml:"channel"`
+}
+
+type ResultItem struct {
+	Category    torznab.Category
+	GUID        string
+	Link        string
+	PublishDate time.Time
+	Title       string
+
+	Episode    string
+	IMDB       string
+	Resolution string
+	Season     string
+	Size       int64
+	Year       int
+}
+
+func (ri ResultItem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	attrs := []ChannelItemAttribute{
+		{Name: "category", Value: strconv.Itoa(ri.Category.ID)},
+	}
+	if ri.Episode != "" {
+		attrs = append(attrs, ChannelItemAttribute{Name: "episode", Value: ri.Episode})
+	}
+	attrs = append(attrs, ChannelItemAttribute{Name: "guid", Value: ri.GUID})
+	if ri.IMDB != "" {
+		attrs = append(attrs, ChannelItemAttribute{Name: "imdb", Value: strings.TrimPrefix(ri.IMDB, "tt")})
+	}
+	if ri.Resolution != "" {
+		attrs = append(attrs, ChannelItemAttribute{Name: "resolution", Value: ri.Resolution})
+	}
+	if ri.Season != "" {
+		attrs = append(attrs, ChannelItemAttribute{Name: "season", Value: ri.Season})
+	}
+	if ri.Size > 0 {
+		attrs = append(attrs, ChannelItemAttribute{Name: "size", Value: strconv.FormatInt(ri.Size, 10)})
+	}
+	if ri.Year != 0 {
+		attrs = append(attrs, ChannelItemAttribute{Name: "year", Value: strconv.Itoa(ri.Year)})
+	}
+	return e.Encode(ChannelItem{
+		Attributes:  attrs,
+		Category:    ri.Category.Name,
+		GUID:        ri.GUID,
+		Link:        ri.Link,
+		PublishDate: ri.PublishDate.Format(rfc822),
+		Title:       ri.Title,
+		Enclosure: ChannelItemEnclosure{
+			URL:    ri.Link,
+			Length: ri.Size,
+			Type:   "application/x-nzb",
+		},
+	})
+}
+
+type ResultFeed struct {
+	Info  torznab.Info
+	Items []ResultItem
+}
+
+func (rf ResultFeed) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(RSS{
+		Version: "2.0",
+		Channel: Channel{
+			Description: rf.Info.Description,
+			Items:       rf.Items,
+			Link:        rf.Info.Link,
+			Title:       rf.Info.Title,
+		},
+		AtomNamespace:    "http://www.w3.org/2005/Atom",
+		NewznabNamespace: "http://www.newznab.com/DTD/2010/feeds/attributes/",
+	})
+}
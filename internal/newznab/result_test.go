@@ -0,0 +1,38 @@
+package newznab
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/torznab"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultFeedMarshalXML(t *testing.T) {
+	out, err := xml.Marshal(ResultFeed{
+		Info: Info,
+		Items: []ResultItem{
+			{
+				Category:    torznab.CategoryTV,
+				GUID:        "abc",
+				Link:        "http://localhost/v0/newznab/api?id=abc&t=get",
+				PublishDate: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				Title:       "The.Show.S01E02.1080p",
+				IMDB:        "tt0000001",
+				Season:      "1",
+				Episode:     "2",
+				Size:        1024,
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	xmlStr := string(out)
+	assert.Contains(t, xmlStr, `xmlns:newznab="http://www.newznab.com/DTD/2010/feeds/attributes/"`)
+	assert.Contains(t, xmlStr, `<enclosure url="http://localhost/v0/newznab/api?id=abc&amp;t=get" length="1024" type="application/x-nzb"></enclosure>`)
+	assert.Contains(t, xmlStr, `<newznab:attr name="category" value="5000"></newznab:attr>`)
+	assert.Contains(t, xmlStr, `<newznab:attr name="imdb" value="0000001"></newznab:attr>`)
+	assert.Contains(t, xmlStr, `<newznab:attr name="season" value="1"></newznab:attr>`)
+	assert.Contains(t, xmlStr, `<newznab:attr name="episode" value="2"></newznab:attr>`)
+}
@@ -0,0 +1,348 @@
+package nzb_info
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/db"
+	"github.com/rodezfranco/stremthru/internal/imdb_title"
+	"github.com/rodezfranco/stremthru/internal/torrent_info"
+	"github.com/rodezfranco/stremthru/internal/util"
+)
+
+type NZBInfoCategory = torrent_info.TorrentInfoCategory
+
+const (
+	NZBInfoCategoryMovie   = torrent_info.TorrentInfoCategoryMovie
+	NZBInfoCategorySeries  = torrent_info.TorrentInfoCategorySeries
+	NZBInfoCategoryXXX     = torrent_info.TorrentInfoCategoryXXX
+	NZBInfoCategoryUnknown = torrent_info.TorrentInfoCategoryUnknown
+)
+
+type NZBInfo struct {
+	Hash       string                         `json:"hash"`
+	Name       string                         `json:"name"`
+	Size       int64                          `json:"size"`
+	Link       string                         `json:"link"`
+	Source     string                         `json:"src"`
+	Category   NZBInfoCategory                `json:"category"`
+	IMDBId     string                         `json:"imdb_id"`
+	Title      string                         `json:"title"`
+	Year       int                            `json:"year"`
+	Seasons    torrent_info.CommaSeperatedInt `json:"seasons"`
+	Episodes   torrent_info.CommaSeperatedInt `json:"episodes"`
+	Resolution string                         `json:"resolution"`
+	CreatedAt  db.Timestamp                   `json:"cat"`
+	UpdatedAt  db.Timestamp                   `json:"uat"`
+}
+
+const TableName = "nzb_info"
+
+type ColumnStruct struct {
+	Hash       string
+	Name       string
+	Size       string
+	Link       string
+	Source     string
+	Category   string
+	IMDBId     string
+	Title      string
+	Year       string
+	Seasons    string
+	Episodes   string
+	Resolution string
+	CreatedAt  string
+	UpdatedAt  string
+}
+
+var Column = ColumnStruct{
+	Hash:       "hash",
+	Name:       "name",
+	Size:       "size",
+	Link:       "link",
+	Source:     "src",
+	Category:   "category",
+	IMDBId:     "imdb_id",
+	Title:      "title",
+	Year:       "year",
+	Seasons:    "seasons",
+	Episodes:   "episodes",
+	Resolution: "resolution",
+	CreatedAt:  "cat",
+	UpdatedAt:  "uat",
+}
+
+var Columns = []string{
+	Column.Hash,
+	Column.Name,
+	Column.Size,
+	Column.Link,
+	Column.Source,
+	Column.Category,
+	Column.IMDBId,
+	Column.Title,
+	Column.Year,
+	Column.Seasons,
+	Column.Episodes,
+	Column.Resolution,
+	Column.CreatedAt,
+	Column.UpdatedAt,
+}
+
+type NZBInfoInsertData struct {
+	Hash     string          `json:"hash"`
+	Name     string          `json:"name"`
+	Size     int64           `json:"size"`
+	Link     string          `json:"link,omitempty"`
+	Source   string          `json:"src"`
+	Category NZBInfoCategory `json:"category"`
+	IMDBId   string          `json:"imdb_id,omitempty"`
+}
+
+func (d NZBInfoInsertData) toNZBInfo() NZBInfo {
+	nInfo := NZBInfo{
+		Hash:     strings.ToLower(d.Hash),
+		Name:     d.Name,
+		Size:     d.Size,
+		Link:     d.Link,
+		Source:   d.Source,
+		Category: d.Category,
+		IMDBId:   d.IMDBId,
+		Seasons:  torrent_info.CommaSeperatedInt{},
+		Episodes: torrent_info.CommaSeperatedInt{},
+	}
+	if nInfo.Size <= 0 {
+		nInfo.Size = -1
+	}
+
+	r, err := util.ParseTorrentTitle(d.Name)
+	if err != nil {
+		log.Debug("failed to parse name", "error", err, "name", d.Name)
+		return nInfo
+	}
+	nInfo.Title = r.Title
+	if year, _, _ := strings.Cut(r.Year, "-"); year != "" {
+		nInfo.Year, _ = strconv.Atoi(year)
+	}
+	nInfo.Seasons = r.Seasons
+	nInfo.Episodes = r.Episodes
+	nInfo.Resolution = r.Resolution
+	if nInfo.Category == NZBInfoCategoryUnknown {
+		if len(nInfo.Seasons) > 0 || len(nInfo.Episodes) > 0 {
+			nInfo.Category = NZBInfoCategorySeries
+		} else if nInfo.Year != 0 {
+			nInfo.Category = NZBInfoCategoryMovie
+		}
+	}
+	return nInfo
+}
+
+func (nInfo *NZBInfo) resolveIMDBId() {
+	if nInfo.IMDBId != "" || nInfo.Title == "" {
+		return
+	}
+	titleType := imdb_title.SearchTitleTypeUnknown
+	switch nInfo.Category {
+	case NZBInfoCategoryMovie:
+		titleType = imdb_title.SearchTitleTypeMovie
+	case NZBInfoCategorySeries:
+		titleType = imdb_title.SearchTitleTypeShow
+	}
+	title, err := imdb_title.SearchOne(nInfo.Title, titleType, nInfo.Year, false)
+	if err != nil {
+		log.Warn("failed to resolve imdb id", "error", err, "title", nInfo.Title)
+		return
+	}
+	if title != nil {
+		nInfo.IMDBId = title.TId
+	}
+}
+
+var query_upsert_before_values = fmt.Sprintf(
+	"INSERT INTO %s (%s) VALUES ",
+	TableName,
+	strings.Join(Columns[:len(Columns)-2], ","),
+)
+var query_upsert_values_placeholder = "(" + util.RepeatJoin("?", len(Columns)-2, ",") + ")"
+var query_upsert_after_values = fmt.Sprintf(
+	" ON CONFLICT (%s) DO UPDATE SET %s",
+	Column.Hash,
+	strings.Join([]string{
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Name, Column.Name),
+		fmt.Sprintf("%s = CASE WHEN EXCLUDED.%s > 0 THEN EXCLUDED.%s ELSE %s.%s END", Column.Size, Column.Size, Column.Size, TableName, Column.Size),
+		fmt.Sprintf("%s = CASE WHEN EXCLUDED.%s != '' THEN EXCLUDED.%s ELSE %s.%s END", Column.Link, Column.Link, Column.Link, TableName, Column.Link),
+		fmt.Sprintf("%s = CASE WHEN EXCLUDED.%s != '' THEN EXCLUDED.%s ELSE %s.%s END", Column.Category, Column.Category, Column.Category, TableName, Column.Category),
+		fmt.Sprintf("%s = CASE WHEN EXCLUDED.%s != '' THEN EXCLUDED.%s ELSE %s.%s END", Column.IMDBId, Column.IMDBId, Column.IMDBId, TableName, Column.IMDBId),
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Title, Column.Title),
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Year, Column.Year),
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Seasons, Column.Seasons),
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Episodes, Column.Episodes),
+		fmt.Sprintf("%s = EXCLUDED.%s", Column.Resolution, Column.Resolution),
+		fmt.Sprintf("%s = %s", Column.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func Upsert(items []NZBInfoInsertData) {
+	if len(items) == 0 {
+		return
+	}
+
+	seenHash := map[string]struct{}{}
+	nInfos := make([]NZBInfo, 0, len(items))
+	for i := range items {
+		if items[i].Hash == "" || items[i].Name == "" {
+			continue
+		}
+		nInfo := items[i].toNZBInfo()
+		if _, seen := seenHash[nInfo.Hash]; seen {
+			continue
+		}
+		seenHash[nInfo.Hash] = struct{}{}
+		nInfo.resolveIMDBId()
+		nInfos = append(nInfos, nInfo)
+	}
+
+	for cInfos := range slices.Chunk(nInfos, 500) {
+		count := len(cInfos)
+		args := make([]any, 0, count*(len(Columns)-2))
+		for i := range cInfos {
+			nInfo := &cInfos[i]
+			args = append(
+				args,
+				nInfo.Hash,
+				nInfo.Name,
+				nInfo.Size,
+				nInfo.Link,
+				nInfo.Source,
+				nInfo.Category,
+				nInfo.IMDBId,
+				nInfo.Title,
+				nInfo.Year,
+				nInfo.Seasons,
+				nInfo.Episodes,
+				nInfo.Resolution,
+			)
+		}
+		query := query_upsert_before_values + util.RepeatJoin(query_upsert_values_placeholder, count, ",") + query_upsert_after_values
+		if _, err := db.Exec(query, args...); err != nil {
+			log.Error("failed to upsert nzb info", "error", err)
+		} else {
+			log.Debug("upserted nzb info", "count", count)
+		}
+	}
+}
+
+func scanNZBInfo(scanner interface{ Scan(dest ...any) error }, nInfo *NZBInfo) error {
+	return scanner.Scan(
+		&nInfo.Hash,
+		&nInfo.Name,
+		&nInfo.Size,
+		&nInfo.Link,
+		&nInfo.Source,
+		&nInfo.Category,
+		&nInfo.IMDBId,
+		&nInfo.Title,
+		&nInfo.Year,
+		&nInfo.Seasons,
+		&nInfo.Episodes,
+		&nInfo.Resolution,
+		&nInfo.CreatedAt,
+		&nInfo.UpdatedAt,
+	)
+}
+
+var query_get_by_hash = fmt.Sprintf(
+	"SELECT %s FROM %s WHERE %s = ?",
+	strings.Join(Columns, ","),
+	TableName,
+	Column.Hash,
+)
+
+func GetByHash(hash string) (*NZBInfo, error) {
+	nInfo := &NZBInfo{}
+	row := db.QueryRow(query_get_by_hash, strings.ToLower(hash))
+	if err := scanNZBInfo(row, nInfo); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nInfo, nil
+}
+
+type SearchParams struct {
+	IMDBIds    []string
+	Query      string
+	Categories []NZBInfoCategory
+	Year       int
+	Season     string
+	Episode    string
+	Limit      int
+	Offset     int
+}
+
+func Search(params SearchParams) ([]NZBInfo, error) {
+	args := []any{}
+	var query strings.Builder
+	query.WriteString(fmt.Sprintf("SELECT %s FROM %s WHERE %s > 0", strings.Join(Columns, ","), TableName, Column.Size))
+
+	if len(params.IMDBIds) > 0 {
+		query.WriteString(fmt.Sprintf(" AND %s IN (%s)", Column.IMDBId, util.RepeatJoin("?", len(params.IMDBIds), ",")))
+		for _, imdbId := range params.IMDBIds {
+			args = append(args, imdbId)
+		}
+	}
+	for _, term := range strings.Fields(params.Query) {
+		query.WriteString(fmt.Sprintf(" AND LOWER(%s) LIKE ?", Column.Name))
+		args = append(args, "%"+strings.ToLower(term)+"%")
+	}
+	if len(params.Categories) > 0 {
+		query.WriteString(fmt.Sprintf(" AND %s IN (%s)", Column.Category, util.RepeatJoin("?", len(params.Categories), ",")))
+		for _, category := range params.Categories {
+			args = append(args, category)
+		}
+	}
+	if params.Year != 0 {
+		query.WriteString(fmt.Sprintf(" AND %s = ?", Column.Year))
+		args = append(args, params.Year)
+	}
+	if params.Season != "" {
+		query.WriteString(fmt.Sprintf(" AND (%s = ? OR CONCAT(',', %s, ',') LIKE ?)", Column.Seasons, Column.Seasons))
+		args = append(args, params.Season, "%,"+params.Season+",%")
+	}
+	if params.Episode != "" {
+		query.WriteString(fmt.Sprintf(" AND (%s = ? OR CONCAT(',', %s, ',') LIKE ?)", Column.Episodes, Column.Episodes))
+		args = append(args, params.Episode, "%,"+params.Episode+",%")
+	}
+	query.WriteString(fmt.Sprintf(" ORDER BY %s DESC", Column.UpdatedAt))
+	if params.Limit > 0 {
+		query.WriteString(" LIMIT ?")
+		args = append(args, params.Limit)
+		if params.Offset > 0 {
+			query.WriteString(" OFFSET ?")
+			args = append(args, params.Offset)
+		}
+	}
+
+	rows, err := db.Query(query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []NZBInfo{}
+	for rows.Next() {
+		var nInfo NZBInfo
+		if err := scanNZBInfo(rows, &nInfo); err != nil {
+			return nil, err
+		}
+		items = append(items, nInfo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,5 @@
+package nzb_info
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("nzb_info")
@@ -10,6 +10,7 @@ import (
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/config"
 	meta_type "github.com/rodezfranco/stremthru/internal/meta/type"
+	"github.com/rodezfranco/stremthru/internal/nzb_info"
 	"github.com/rodezfranco/stremthru/internal/request"
 	"github.com/rodezfranco/stremthru/internal/server"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
@@ -279,3 +280,18 @@ func (c APIClient) FetchLetterboxdList(params *FetchLetterboxdListParams) (reque
 	res, err := c.Request("GET", "/v0/meta/lists/letterboxd/"+params.ListId, params, response)
 	return request.NewAPIResponse(res, response.Data), err
 }
+
+type PushNZBsParams struct {
+	request.Ctx
+	Items []nzb_info.NZBInfoInsertData `json:"items"`
+}
+
+type PushNZBsData struct{}
+
+func (c APIClient) PushNZBs(params *PushNZBsParams) (request.APIResponse[PushNZBsData], error) {
+	params.JSON = params
+
+	response := &Response[PushNZBsData]{}
+	res, err := c.Request("POST", "/v0/nzbs", params, response)
+	return request.NewAPIResponse(res, response.Data), err
+}
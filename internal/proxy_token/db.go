@@ -0,0 +1,273 @@
+package proxy_token
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/db"
+	"github.com/rodezfranco/stremthru/internal/util"
+)
+
+const TableName = "proxy_token"
+
+type ProxyToken struct {
+	Id        string       `json:"id"`
+	Username  string       `json:"username"`
+	Device    string       `json:"device"`
+	Link      string       `json:"link"`
+	IPRange   string       `json:"ip_range"`
+	ExpiresAt db.Timestamp `json:"eat"`
+	CreatedAt db.Timestamp `json:"cat"`
+}
+
+func (t *ProxyToken) IsExpired() bool {
+	return !t.ExpiresAt.IsZero() && t.ExpiresAt.Before(time.Now())
+}
+
+type ColumnStruct struct {
+	Id        string
+	Username  string
+	Device    string
+	Link      string
+	IPRange   string
+	ExpiresAt string
+	CreatedAt string
+}
+
+var Column = ColumnStruct{
+	Id:        "id",
+	Username:  "username",
+	Device:    "device",
+	Link:      "link",
+	IPRange:   "ip_range",
+	ExpiresAt: "eat",
+	CreatedAt: "cat",
+}
+
+var Columns = []string{
+	Column.Id,
+	Column.Username,
+	Column.Device,
+	Column.Link,
+	Column.IPRange,
+	Column.ExpiresAt,
+	Column.CreatedAt,
+}
+
+func generateId() string {
+	return util.GenerateRandomString(24, util.CharSet.AlphaNumericMixedCase)
+}
+
+var query_create = fmt.Sprintf(
+	"INSERT INTO %s (%s) VALUES (%s)",
+	TableName,
+	strings.Join(Columns[:len(Columns)-1], ","),
+	util.RepeatJoin("?", len(Columns)-1, ","),
+)
+
+// Create saves a new token, Id and CreatedAt are set on t.
+func Create(t *ProxyToken) error {
+	t.Id = generateId()
+	t.CreatedAt = db.Timestamp{Time: time.Now()}
+	_, err := db.Exec(query_create, t.Id, t.Username, t.Device, t.Link, t.IPRange, t.ExpiresAt)
+	return err
+}
+
+// shared token is reused by the user's links, so that a token is not
+// created for every link.
+var sharedTokenIdCache = cache.NewCache[string](&cache.CacheConfig{
+	Name:     "proxy_token:shared",
+	Lifetime: 6 * time.Hour,
+})
+
+// GetOrCreateShared returns the user's token shared by the links valid for
+// `expiresIn`, it is created with twice the lifetime so that it can be
+// reused for a while. The token is not bound to a link, device or ip range.
+func GetOrCreateShared(username string, expiresIn time.Duration) (*ProxyToken, error) {
+	cacheKey := username + ":" + expiresIn.String()
+	id := ""
+	if sharedTokenIdCache.Get(cacheKey, &id) {
+		t, err := Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil && (t.ExpiresAt.IsZero() || t.ExpiresAt.After(time.Now().Add(expiresIn))) {
+			return t, nil
+		}
+	}
+
+	t := &ProxyToken{Username: username}
+	if expiresIn != 0 {
+		t.ExpiresAt = db.Timestamp{Time: time.Now().Add(2 * expiresIn)}
+	}
+	if err := Create(t); err != nil {
+		return nil, err
+	}
+	sharedTokenIdCache.Add(cacheKey, t.Id)
+	return t, nil
+}
+
+var query_get = fmt.Sprintf(
+	"SELECT %s FROM %s WHERE %s = ?",
+	strings.Join(Columns, ","),
+	TableName,
+	Column.Id,
+)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scan(row scanner) (*ProxyToken, error) {
+	t := &ProxyToken{}
+	if err := row.Scan(&t.Id, &t.Username, &t.Device, &t.Link, &t.IPRange, &t.ExpiresAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// tokens are checked on every proxy request, the cache is cleared for the
+// revoked tokens.
+var tokenCache = cache.NewCache[ProxyToken](&cache.CacheConfig{
+	Name:     "proxy_token",
+	Lifetime: 1 * time.Minute,
+})
+
+// Get returns nil if the token does not exist, i.e. revoked, or is expired.
+func Get(id string) (*ProxyToken, error) {
+	t := &ProxyToken{}
+	if !tokenCache.Get(id, t) {
+		var err error
+		t, err = scan(db.QueryRow(query_get, id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		tokenCache.Add(id, *t)
+	}
+	if t.IsExpired() {
+		return nil, nil
+	}
+	return t, nil
+}
+
+var query_delete_expired = fmt.Sprintf(
+	"DELETE FROM %s WHERE %s IS NOT NULL AND %s < ?",
+	TableName,
+	Column.ExpiresAt,
+	Column.ExpiresAt,
+)
+
+// DeleteExpired deletes the expired tokens, and returns the deleted count.
+func DeleteExpired() (int64, error) {
+	result, err := db.Exec(query_delete_expired, db.Timestamp{Time: time.Now()})
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type ListParams struct {
+	Username string
+	Device   string
+}
+
+// List returns the tokens matching the non-empty params, newest first.
+func List(params *ListParams) ([]ProxyToken, error) {
+	if _, err := DeleteExpired(); err != nil {
+		log.Error("failed to delete expired tokens", "error", err)
+	}
+
+	var query strings.Builder
+	query.WriteString(fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 1", strings.Join(Columns, ","), TableName))
+	args := []any{}
+	if params.Username != "" {
+		query.WriteString(" AND " + Column.Username + " = ?")
+		args = append(args, params.Username)
+	}
+	if params.Device != "" {
+		query.WriteString(" AND " + Column.Device + " = ?")
+		args = append(args, params.Device)
+	}
+	query.WriteString(" ORDER BY " + Column.CreatedAt + " DESC")
+
+	rows, err := db.Query(query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ProxyToken{}
+	for rows.Next() {
+		t, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+var query_revoke = fmt.Sprintf(
+	"DELETE FROM %s WHERE %s = ?",
+	TableName,
+	Column.Id,
+)
+
+// Revoke returns false if the token did not exist.
+func Revoke(id string) (bool, error) {
+	result, err := db.Exec(query_revoke, id)
+	tokenCache.Remove(id)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// RevokeAll revokes all the tokens for username, limited to device if not
+// empty, and returns the revoked count.
+func RevokeAll(username, device string) (int64, error) {
+	cond := " WHERE " + Column.Username + " = ?"
+	args := []any{username}
+	if device != "" {
+		cond += " AND " + Column.Device + " = ?"
+		args = append(args, device)
+	}
+
+	rows, err := db.Query("SELECT "+Column.Id+" FROM "+TableName+cond, args...)
+	if err != nil {
+		return 0, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec("DELETE FROM "+TableName+cond, args...)
+	for _, id := range ids {
+		tokenCache.Remove(id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
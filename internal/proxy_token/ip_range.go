@@ -0,0 +1,62 @@
+package proxy_token
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+const IPRangeAuto = "auto"
+
+// ParseIPRange normalizes value to a CIDR prefix. A plain IP is treated as a
+// single address range, and `auto` is resolved to the range of requestIP,
+// i.e. /24 for IPv4 and /64 for IPv6.
+func ParseIPRange(value string, requestIP string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if value == IPRangeAuto {
+		addr, err := netip.ParseAddr(requestIP)
+		if err != nil {
+			return "", errors.New("failed to detect ip")
+		}
+		addr = addr.Unmap()
+		bits := 24
+		if addr.Is6() {
+			bits = 64
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			return "", err
+		}
+		return prefix.String(), nil
+	}
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+	}
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return "", err
+	}
+	return prefix.Masked().String(), nil
+}
+
+func (t *ProxyToken) IsAllowedIP(ip string) bool {
+	if t.IPRange == "" {
+		return true
+	}
+	prefix, err := netip.ParsePrefix(t.IPRange)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return prefix.Contains(addr.Unmap())
+}
@@ -0,0 +1,45 @@
+package proxy_token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIPRange(t *testing.T) {
+	for _, tc := range []struct {
+		value     string
+		requestIP string
+		result    string
+		isErr     bool
+	}{
+		{"", "10.0.0.1", "", false},
+		{"auto", "10.1.2.3", "10.1.2.0/24", false},
+		{"auto", "2001:db8:1:2:3::4", "2001:db8:1:2::/64", false},
+		{"auto", "", "", true},
+		{"10.1.2.3", "", "10.1.2.3/32", false},
+		{"10.1.2.3/16", "", "10.1.0.0/16", false},
+		{"invalid", "", "", true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			result, err := ParseIPRange(tc.value, tc.requestIP)
+			if tc.isErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestProxyTokenIsAllowedIP(t *testing.T) {
+	token := &ProxyToken{}
+	assert.True(t, token.IsAllowedIP("10.1.2.3"))
+
+	token.IPRange = "10.1.2.0/24"
+	assert.True(t, token.IsAllowedIP("10.1.2.3"))
+	assert.True(t, token.IsAllowedIP("::ffff:10.1.2.3"))
+	assert.False(t, token.IsAllowedIP("10.1.3.3"))
+	assert.False(t, token.IsAllowedIP(""))
+}
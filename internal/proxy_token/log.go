@@ -0,0 +1,5 @@
+package proxy_token
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("proxy_token")
@@ -0,0 +1,140 @@
+package proxy_usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/db"
+)
+
+const TableName = "proxy_usage"
+
+type ProxyUsage struct {
+	Username    string       `json:"username"`
+	Date        db.DateOnly  `json:"date"`
+	Bytes       int64        `json:"bytes"`
+	Connections int          `json:"conns"`
+	CreatedAt   db.Timestamp `json:"cat"`
+	UpdatedAt   db.Timestamp `json:"uat"`
+}
+
+type ColumnStruct struct {
+	Username    string
+	Date        string
+	Bytes       string
+	Connections string
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+var Column = ColumnStruct{
+	Username:    "username",
+	Date:        "date",
+	Bytes:       "bytes",
+	Connections: "conns",
+	CreatedAt:   "cat",
+	UpdatedAt:   "uat",
+}
+
+var Columns = []string{
+	Column.Username,
+	Column.Date,
+	Column.Bytes,
+	Column.Connections,
+	Column.CreatedAt,
+	Column.UpdatedAt,
+}
+
+// GetDate returns the accounting date, usage is tracked in UTC.
+func GetDate(t time.Time) db.DateOnly {
+	t = t.UTC()
+	return db.DateOnly{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func GetMonthStartDate(t time.Time) db.DateOnly {
+	t = t.UTC()
+	return db.DateOnly{Time: time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)}
+}
+
+var query_record = fmt.Sprintf(
+	"INSERT INTO %s AS pu (%s) VALUES (?,?,?,?) ON CONFLICT (%s,%s) DO UPDATE SET %s",
+	TableName,
+	strings.Join(Columns[0:4], ","),
+	Column.Username,
+	Column.Date,
+	strings.Join([]string{
+		fmt.Sprintf("%s = pu.%s + EXCLUDED.%s", Column.Bytes, Column.Bytes, Column.Bytes),
+		fmt.Sprintf("%s = pu.%s + EXCLUDED.%s", Column.Connections, Column.Connections, Column.Connections),
+		fmt.Sprintf("%s = %s", Column.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+// Record adds the bytes transferred and the connections opened to the
+// user's usage for the day.
+func Record(username string, bytes int64, connections int) error {
+	_, err := db.Exec(query_record, username, GetDate(time.Now()), bytes, connections)
+	return err
+}
+
+var query_get_daily = fmt.Sprintf(
+	"SELECT %s FROM %s WHERE %s = ? AND %s >= ? AND %s <= ? ORDER BY %s ASC",
+	strings.Join(Columns, ","),
+	TableName,
+	Column.Username,
+	Column.Date,
+	Column.Date,
+	Column.Date,
+)
+
+// GetDaily returns the usage for each day in [from, to] with any transfer.
+func GetDaily(username string, from, to time.Time) ([]ProxyUsage, error) {
+	rows, err := db.Query(query_get_daily, username, GetDate(from), GetDate(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ProxyUsage{}
+	for rows.Next() {
+		item := ProxyUsage{}
+		if err := rows.Scan(&item.Username, &item.Date, &item.Bytes, &item.Connections, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+var query_get_total = fmt.Sprintf(
+	"SELECT CAST(COALESCE(SUM(%s), 0) AS bigint), CAST(COALESCE(SUM(%s), 0) AS int) FROM %s WHERE %s = ? AND %s >= ? AND %s <= ?",
+	Column.Bytes,
+	Column.Connections,
+	TableName,
+	Column.Username,
+	Column.Date,
+	Column.Date,
+)
+
+type Total struct {
+	Bytes       int64 `json:"bytes"`
+	Connections int   `json:"conns"`
+}
+
+// GetTotal returns the usage summed over [from, to].
+func GetTotal(username string, from, to time.Time) (*Total, error) {
+	total := &Total{}
+	row := db.QueryRow(query_get_total, username, GetDate(from), GetDate(to))
+	if err := row.Scan(&total.Bytes, &total.Connections); err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// GetMonthlyTotal returns the usage for the month of t, up to t.
+func GetMonthlyTotal(username string, t time.Time) (*Total, error) {
+	return GetTotal(username, GetMonthStartDate(t).Time, t)
+}
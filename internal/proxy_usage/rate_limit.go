@@ -0,0 +1,58 @@
+package proxy_usage
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type rateLimitedResponseWriter struct {
+	http.ResponseWriter
+	ctx            context.Context
+	bytesPerSecond int64
+	start          time.Time
+	written        int64
+}
+
+// Write splits p into pieces of 100ms worth of bytes, and waits before each
+// piece to keep the average rate under the limit.
+func (w *rateLimitedResponseWriter) Write(p []byte) (int, error) {
+	if w.start.IsZero() {
+		w.start = time.Now()
+	}
+	piece := max(1, int(w.bytesPerSecond/10))
+	n := 0
+	for n < len(p) {
+		expected := time.Duration(float64(w.written) / float64(w.bytesPerSecond) * float64(time.Second))
+		if wait := expected - time.Since(w.start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-w.ctx.Done():
+				timer.Stop()
+				return n, w.ctx.Err()
+			case <-timer.C:
+			}
+		}
+		end := min(n+piece, len(p))
+		wn, err := w.ResponseWriter.Write(p[n:end])
+		n += wn
+		w.written += int64(wn)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// NewRateLimitedResponseWriter limits the body write rate of w, w is
+// returned as is if bytesPerSecond is not positive.
+func NewRateLimitedResponseWriter(w http.ResponseWriter, r *http.Request, bytesPerSecond int64) http.ResponseWriter {
+	if bytesPerSecond <= 0 {
+		return w
+	}
+	return &rateLimitedResponseWriter{
+		ResponseWriter: w,
+		ctx:            r.Context(),
+		bytesPerSecond: bytesPerSecond,
+	}
+}
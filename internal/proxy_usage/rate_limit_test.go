@@ -0,0 +1,26 @@
+package proxy_usage
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedResponseWriter(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+
+	assert.Same(t, rec, NewRateLimitedResponseWriter(rec, r, 0))
+
+	w := NewRateLimitedResponseWriter(rec, r, 10000)
+	data := bytes.Repeat([]byte{'a'}, 3000)
+	start := time.Now()
+	n, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, data, rec.Body.Bytes())
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
@@ -0,0 +1,134 @@
+package proxy_usage
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("proxy usage quota exceeded")
+
+// usage is synced at this interval during the transfer, so that long
+// running connections are accounted for before they are closed and the
+// remaining quota reflects the user's other connections.
+const usageSyncInterval = 30 * time.Second
+
+type UsageResponseWriter struct {
+	http.ResponseWriter
+	quota        int64
+	remaining    int64
+	pending      int64
+	written      int64
+	isConnSynced bool
+	syncedAt     time.Time
+	syncErr      error
+	// sync records the usage, and returns the monthly usage including it.
+	sync func(bytes int64, connections int) (int64, error)
+}
+
+// Write writes p as long as the quota allows, the write is cut short with
+// ErrQuotaExceeded once the quota is used up.
+func (w *UsageResponseWriter) Write(p []byte) (int, error) {
+	isCut := false
+	if w.quota > 0 {
+		if w.remaining <= 0 {
+			return 0, ErrQuotaExceeded
+		}
+		if int64(len(p)) > w.remaining {
+			p = p[:w.remaining]
+			isCut = true
+		}
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	w.pending += int64(n)
+	w.remaining -= int64(n)
+
+	if time.Since(w.syncedAt) >= usageSyncInterval {
+		w.syncErr = w.Sync()
+	}
+
+	if err == nil && isCut {
+		err = ErrQuotaExceeded
+	}
+	return n, err
+}
+
+func (w *UsageResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// IsQuotaExceeded reports if the quota was used up as of the last sync.
+func (w *UsageResponseWriter) IsQuotaExceeded() bool {
+	return w.quota > 0 && w.remaining <= 0
+}
+
+// BytesWritten returns the bytes written through w.
+func (w *UsageResponseWriter) BytesWritten() int64 {
+	return w.written
+}
+
+// Sync records the pending usage, and refreshes the remaining quota.
+func (w *UsageResponseWriter) Sync() error {
+	connections := 1
+	if w.isConnSynced {
+		connections = 0
+	}
+	if w.pending == 0 && connections == 0 {
+		return nil
+	}
+
+	usage, err := w.sync(w.pending, connections)
+	w.syncedAt = time.Now()
+	if err != nil {
+		return err
+	}
+	w.pending = 0
+	w.isConnSynced = true
+	if w.quota > 0 {
+		w.remaining = w.quota - usage
+	}
+	return nil
+}
+
+// Close records the pending usage, it returns the error of the last failed
+// sync during the transfer if the final sync succeeds.
+func (w *UsageResponseWriter) Close() error {
+	if err := w.Sync(); err != nil {
+		return err
+	}
+	return w.syncErr
+}
+
+// NewUsageResponseWriter records the usage of the user's connection through
+// the returned writer, and stops writing once `quota` bytes are used in the
+// month. quota is ignored if not positive. The writer is usable even if it
+// fails to get the current usage, the remaining quota is then refreshed on
+// the first sync.
+func NewUsageResponseWriter(w http.ResponseWriter, username string, quota int64) (*UsageResponseWriter, error) {
+	uw := &UsageResponseWriter{
+		ResponseWriter: w,
+		quota:          quota,
+		syncedAt:       time.Now(),
+		sync: func(bytes int64, connections int) (int64, error) {
+			if err := Record(username, bytes, connections); err != nil {
+				return 0, err
+			}
+			usage, err := GetMonthlyTotal(username, time.Now())
+			if err != nil {
+				return 0, err
+			}
+			return usage.Bytes, nil
+		},
+	}
+	if quota > 0 {
+		uw.remaining = quota
+		usage, err := GetMonthlyTotal(username, time.Now())
+		if err != nil {
+			return uw, err
+		}
+		uw.remaining -= usage.Bytes
+	}
+	return uw, nil
+}
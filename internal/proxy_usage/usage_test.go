@@ -0,0 +1,77 @@
+package proxy_usage
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUsageResponseWriter(quota int64, usage *int64, connections *int) (*UsageResponseWriter, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	return &UsageResponseWriter{
+		ResponseWriter: rec,
+		quota:          quota,
+		remaining:      quota - *usage,
+		syncedAt:       time.Now(),
+		sync: func(b int64, c int) (int64, error) {
+			*usage += b
+			*connections += c
+			return *usage, nil
+		},
+	}, rec
+}
+
+func TestUsageResponseWriter(t *testing.T) {
+	t.Run("stops at quota", func(t *testing.T) {
+		usage, connections := int64(0), 0
+		w, rec := newTestUsageResponseWriter(1000, &usage, &connections)
+
+		n, err := w.Write(bytes.Repeat([]byte{'a'}, 600))
+		assert.NoError(t, err)
+		assert.Equal(t, 600, n)
+
+		n, err = w.Write(bytes.Repeat([]byte{'a'}, 600))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Equal(t, 400, n)
+		assert.True(t, w.IsQuotaExceeded())
+		assert.Equal(t, 1000, rec.Body.Len())
+
+		assert.Equal(t, int64(0), usage)
+		assert.NoError(t, w.Close())
+		assert.Equal(t, int64(1000), usage)
+		assert.Equal(t, 1, connections)
+	})
+
+	t.Run("syncs during transfer", func(t *testing.T) {
+		usage, connections := int64(0), 0
+		w, _ := newTestUsageResponseWriter(1000, &usage, &connections)
+
+		n, err := w.Write(bytes.Repeat([]byte{'a'}, 100))
+		assert.NoError(t, err)
+		assert.Equal(t, 100, n)
+		assert.Equal(t, int64(0), usage)
+
+		// used by other connection
+		usage += 800
+
+		w.syncedAt = time.Now().Add(-usageSyncInterval)
+		n, err = w.Write(bytes.Repeat([]byte{'a'}, 100))
+		assert.NoError(t, err)
+		assert.Equal(t, 100, n)
+		assert.Equal(t, int64(1000), usage)
+		assert.Equal(t, 1, connections)
+		assert.True(t, w.IsQuotaExceeded())
+
+		n, err = w.Write([]byte{'a'})
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Equal(t, 0, n)
+
+		assert.NoError(t, w.Close())
+		assert.Equal(t, int64(1000), usage)
+		assert.Equal(t, 1, connections)
+		assert.Equal(t, int64(200), w.BytesWritten())
+	})
+}
@@ -30,6 +30,18 @@ var ErrorNotFound = func(r *http.Request) *core.APIError {
 	return err
 }
 
+var ErrorConflict = func(r *http.Request, msg string) *core.APIError {
+	if msg == "" {
+		msg = "conflict"
+	}
+
+	err := core.NewAPIError(msg)
+	err.InjectReq(r)
+	err.Code = core.ErrorCodeConflict
+	err.StatusCode = http.StatusConflict
+	return err
+}
+
 var ErrorMethodNotAllowed = func(r *http.Request) *core.APIError {
 	err := core.NewAPIError("method not allowed")
 	err.InjectReq(r)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_cache"
 	"github.com/rodezfranco/stremthru/internal/context"
 	"github.com/rodezfranco/stremthru/internal/server"
 )
@@ -151,13 +152,13 @@ var proxyHttpClientByTunnelType = map[config.TunnelType]*http.Client{
 		transport := config.DefaultHTTPTransport.Clone()
 		transport.Proxy = config.Tunnel.GetProxy(config.TUNNEL_TYPE_FORCED)
 		return &http.Client{
-			Transport: transport,
+			Transport: config.StoreTunnelPool.RoundTripper(transport),
 		}
 	}(),
 }
 
 func ProxyResponse(w http.ResponseWriter, r *http.Request, url string, tunnelType config.TunnelType) (bytesWritten int64, err error) {
-	request, err := http.NewRequest(r.Method, url, nil)
+	request, err := http.NewRequestWithContext(r.Context(), r.Method, url, nil)
 	if err != nil {
 		e := ErrorInternalServerError(r, "failed to create request")
 		e.Cause = err
@@ -185,6 +186,40 @@ func ProxyResponse(w http.ResponseWriter, r *http.Request, url string, tunnelTyp
 	return io.Copy(w, response.Body)
 }
 
+// CachedProxyResponse serves the content through content proxy cache when
+// enabled, otherwise same as ProxyResponse.
+func CachedProxyResponse(w http.ResponseWriter, r *http.Request, url string, tunnelType config.TunnelType) (bytesWritten int64, err error) {
+	cache := content_proxy_cache.Default
+	if cache == nil {
+		return ProxyResponse(w, r, url, tunnelType)
+	}
+
+	proxyHttpClient := proxyHttpClientByTunnelType[tunnelType]
+
+	bytesWritten, wroteHeader, err := cache.Serve(w, r, url, func(method string, rangeHeader string) (*http.Response, error) {
+		request, err := http.NewRequestWithContext(r.Context(), method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		copyHeaders(r.Header, request.Header, true)
+		request.Header.Del("If-Range")
+		request.Header.Del("If-None-Match")
+		request.Header.Del("If-Modified-Since")
+		if rangeHeader == "" {
+			request.Header.Del("Range")
+		} else {
+			request.Header.Set("Range", rangeHeader)
+		}
+		return proxyHttpClient.Do(request)
+	})
+	if err != nil && !wroteHeader {
+		e := ErrorBadGateway(r, "failed to request url")
+		e.Cause = err
+		SendError(w, r, e)
+	}
+	return bytesWritten, err
+}
+
 func extractRequestScheme(r *http.Request) string {
 	scheme := r.Header.Get("X-Forwarded-Proto")
 
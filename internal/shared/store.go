@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,53 +13,63 @@ import (
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/cache"
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_hls"
 	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/internal/db"
+	"github.com/rodezfranco/stremthru/internal/proxy_token"
 	"github.com/rodezfranco/stremthru/store"
 	"github.com/rodezfranco/stremthru/store/alldebrid"
 	"github.com/rodezfranco/stremthru/store/debrider"
 	"github.com/rodezfranco/stremthru/store/debridlink"
 	"github.com/rodezfranco/stremthru/store/easydebrid"
+	"github.com/rodezfranco/stremthru/store/multi"
 	"github.com/rodezfranco/stremthru/store/offcloud"
 	"github.com/rodezfranco/stremthru/store/pikpak"
 	"github.com/rodezfranco/stremthru/store/premiumize"
+	"github.com/rodezfranco/stremthru/store/qbittorrent"
 	"github.com/rodezfranco/stremthru/store/realdebrid"
 	"github.com/rodezfranco/stremthru/store/torbox"
 )
 
 var adStore = alldebrid.NewStoreClient(&alldebrid.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("alldebrid")),
+	HTTPClient: config.GetStoreHTTPClient("alldebrid"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var drStore = debrider.NewStoreClient(&debrider.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("debrider")),
+	HTTPClient: config.GetStoreHTTPClient("debrider"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var dlStore = debridlink.NewStoreClient(&debridlink.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("debridlink")),
+	HTTPClient: config.GetStoreHTTPClient("debridlink"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var edStore = easydebrid.NewStoreClient(&easydebrid.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("easydebrid")),
+	HTTPClient: config.GetStoreHTTPClient("easydebrid"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var pmStore = premiumize.NewStoreClient(&premiumize.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("premiumize")),
+	HTTPClient: config.GetStoreHTTPClient("premiumize"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var ppStore = pikpak.NewStoreClient(&pikpak.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("pikpak")),
+	HTTPClient: config.GetStoreHTTPClient("pikpak"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var ocStore = offcloud.NewStoreClient(&offcloud.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("offcloud")),
+	HTTPClient: config.GetStoreHTTPClient("offcloud"),
+	UserAgent:  config.StoreClientUserAgent,
+})
+var qbStore = qbittorrent.NewStoreClient(&qbittorrent.StoreClientConfig{
+	BaseURL:    config.StoreQBittorrent.GetURL(),
+	HTTPClient: config.GetHTTPClient(config.TUNNEL_TYPE_NONE),
 	UserAgent:  config.StoreClientUserAgent,
 })
 var rdStore = realdebrid.NewStoreClient(&realdebrid.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("realdebrid")),
+	HTTPClient: config.GetStoreHTTPClient("realdebrid"),
 	UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
 })
 var tbStore = torbox.NewStoreClient(&torbox.StoreClientConfig{
-	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("torbox")),
+	HTTPClient: config.GetStoreHTTPClient("torbox"),
 	UserAgent:  config.StoreClientUserAgent,
 })
 
@@ -78,6 +89,8 @@ func GetStore(name string) store.Store {
 		return ppStore
 	case store.StoreNamePremiumize:
 		return pmStore
+	case store.StoreNameQBittorrent:
+		return qbStore
 	case store.StoreNameRealDebrid:
 		return rdStore
 	case store.StoreNameTorBox:
@@ -103,6 +116,8 @@ func GetStoreByCode(code string) store.Store {
 		return ppStore
 	case store.StoreCodePremiumize:
 		return pmStore
+	case store.StoreCodeQBittorrent:
+		return qbStore
 	case store.StoreCodeRealDebrid:
 		return rdStore
 	case store.StoreCodeTorBox:
@@ -112,6 +127,25 @@ func GetStoreByCode(code string) store.Store {
 	}
 }
 
+// GetMultiStore returns a virtual store backed by all the stores configured
+// for the proxy user, in order of preference.
+func GetMultiStore(user string) store.Store {
+	members := []multi.StoreMember{}
+	for _, name := range config.StoreAuthToken.ListStores(user) {
+		s := GetStore(name)
+		if s == nil {
+			continue
+		}
+		members = append(members, multi.StoreMember{
+			Store: s,
+			Token: config.StoreAuthToken.GetToken(user, name),
+		})
+	}
+	return multi.NewStoreClient(&multi.StoreClientConfig{
+		Members: members,
+	})
+}
+
 type proxyLinkTokenData struct {
 	EncLink    string            `json:"enc_link"`
 	EncFormat  string            `json:"enc_format"`
@@ -123,12 +157,67 @@ type proxyLinkData struct {
 	Value   string            `json:"v"`
 	Headers map[string]string `json:"reqh,omitempty"`
 	TunT    config.TunnelType `json:"tunt,omitempty"`
+	TokenId string            `json:"tid,omitempty"`
 }
 
+// CreateProxyLink creates a proxy link, it is revocable if
+// `STREMTHRU_CONTENT_PROXY_REVOCABLE_LINK` is enabled. Such links share the
+// user's token, revoking it revokes all of them.
 func CreateProxyLink(r *http.Request, link string, headers map[string]string, tunnelType config.TunnelType, expiresIn time.Duration, user, password string, shouldEncrypt bool, filename string) (string, error) {
+	if config.ContentProxyRevocableLink && user != "" {
+		token, err := proxy_token.GetOrCreateShared(user, expiresIn)
+		if err != nil {
+			return "", err
+		}
+		return createProxyLink(r, link, headers, tunnelType, expiresIn, user, password, shouldEncrypt, filename, token.Id)
+	}
+	return createProxyLink(r, link, headers, tunnelType, expiresIn, user, password, shouldEncrypt, filename, "")
+}
+
+// GetHLSProxyLink returns the HLS playlist link for the proxyLink, to be
+// segmented on the fly with the variant.
+func GetHLSProxyLink(proxyLink string, variant content_proxy_hls.Variant) (string, error) {
+	u, err := url.Parse(proxyLink)
+	if err != nil {
+		return "", err
+	}
+	prefix, rest, ok := strings.Cut(u.Path, "/v0/proxy/")
+	token, _, _ := strings.Cut(rest, "/")
+	if !ok || token == "" {
+		return "", errors.New("not a proxy link")
+	}
+	u.Path = prefix + "/v0/proxy/" + token + "/hls/" + string(variant) + "/" + content_proxy_hls.PlaylistFileName
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+type ProxyLinkScope struct {
+	Device  string
+	IPRange string
+}
+
+// CreateRevocableProxyLink creates a proxy link backed by a server-side token,
+// so that it can be listed and revoked before it expires.
+func CreateRevocableProxyLink(r *http.Request, link string, headers map[string]string, tunnelType config.TunnelType, expiresIn time.Duration, user, password string, shouldEncrypt bool, filename string, scope ProxyLinkScope) (string, error) {
+	token := &proxy_token.ProxyToken{
+		Username: user,
+		Device:   scope.Device,
+		Link:     link,
+		IPRange:  scope.IPRange,
+	}
+	if expiresIn != 0 {
+		token.ExpiresAt = db.Timestamp{Time: time.Now().Add(expiresIn)}
+	}
+	if err := proxy_token.Create(token); err != nil {
+		return "", err
+	}
+	return createProxyLink(r, link, headers, tunnelType, expiresIn, user, password, shouldEncrypt, filename, token.Id)
+}
+
+func createProxyLink(r *http.Request, link string, headers map[string]string, tunnelType config.TunnelType, expiresIn time.Duration, user, password string, shouldEncrypt bool, filename string, tokenId string) (string, error) {
 	var encodedToken string
 
-	if !shouldEncrypt && expiresIn == 0 {
+	if !shouldEncrypt && expiresIn == 0 && tokenId == "" {
 		blob, err := json.Marshal(proxyLinkData{
 			User:    user + ":" + password,
 			Value:   link,
@@ -164,6 +253,7 @@ func CreateProxyLink(r *http.Request, link string, headers map[string]string, tu
 
 		claims := core.JWTClaims[proxyLinkTokenData]{
 			RegisteredClaims: jwt.RegisteredClaims{
+				ID:      tokenId,
 				Issuer:  "stremthru",
 				Subject: user,
 			},
@@ -198,20 +288,99 @@ func CreateProxyLink(r *http.Request, link string, headers map[string]string, tu
 func GenerateStremThruLink(r *http.Request, ctx *context.StoreContext, link string) (*store.GenerateLinkData, error) {
 	params := &store.GenerateLinkParams{}
 	params.APIKey = ctx.StoreAuthToken
+	params.Context = config.WithTunnelPoolKey(r.Context(), ctx.ProxyAuthUser)
 	params.Link = link
 	if ctx.ClientIP != "" {
 		params.ClientIP = ctx.ClientIP
 	}
 
+	if ms, ok := ctx.Store.(*multi.StoreClient); ok {
+		data, storeName, err := ms.GenerateLinkWithStore(params)
+		if err != nil {
+			return nil, err
+		}
+		mctx := *ctx
+		mctx.Store = GetStore(string(storeName))
+		mctx.StoreAuthToken = config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, string(storeName))
+		return wrapStoreLinkWithProxy(r, &mctx, data)
+	}
+
 	data, err := ctx.Store.GenerateLink(params)
 	if err != nil {
 		return nil, err
 	}
 
+	return wrapStoreLinkWithProxy(r, ctx, data)
+}
+
+func GenerateStremThruNewsLink(r *http.Request, ctx *context.StoreContext, link string) (*store.GenerateLinkData, error) {
+	newsStore, ok := ctx.Store.(store.NewsStore)
+	if !ok {
+		return nil, store.ErrorNewsNotSupported(ctx.Store.GetName())
+	}
+
+	params := &store.GenerateNewsLinkParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Context = config.WithTunnelPoolKey(r.Context(), ctx.ProxyAuthUser)
+	params.Link = link
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+
+	data, err := newsStore.GenerateNewsLink(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapStoreLinkWithProxy(r, ctx, data)
+}
+
+func GenerateStremThruWebDLLink(r *http.Request, ctx *context.StoreContext, link string) (*store.GenerateLinkData, error) {
+	webdlStore, ok := ctx.Store.(store.WebDLStore)
+	if !ok {
+		return nil, store.ErrorWebDLNotSupported(ctx.Store.GetName())
+	}
+
+	params := &store.GenerateWebDLLinkParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Context = config.WithTunnelPoolKey(r.Context(), ctx.ProxyAuthUser)
+	params.Link = link
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+
+	data, err := webdlStore.GenerateWebDLLink(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// link is not ready yet, e.g. still being downloaded by the store
+	if data.Link == "" {
+		return data, nil
+	}
+
+	return wrapStoreLinkWithProxy(r, ctx, data)
+}
+
+func wrapStoreLinkWithProxy(r *http.Request, ctx *context.StoreContext, data *store.GenerateLinkData) (*store.GenerateLinkData, error) {
 	storeName := string(ctx.Store.GetName())
+
+	// local files are only accessible through the content proxy
+	if qbittorrent.IsLockedFileLink(data.Link) {
+		if !ctx.IsProxyAuthorized {
+			return nil, ErrorUnauthorized(r)
+		}
+		proxyLink, err := CreateProxyLink(r, data.Link, nil, config.TUNNEL_TYPE_NONE, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, "")
+		if err != nil {
+			return nil, err
+		}
+		data.Link = proxyLink
+		return data, nil
+	}
+
 	if config.StoreContentProxy.IsEnabled(storeName) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, storeName) {
 		if ctx.IsProxyAuthorized {
-			tunnelType := config.StoreTunnel.GetTypeForStream(string(ctx.Store.GetName()))
+			tunnelType := config.StoreTunnel.GetTypeForStream(storeName)
 			proxyLink, err := CreateProxyLink(r, data.Link, nil, tunnelType, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, "")
 			if err != nil {
 				return nil, err
@@ -240,31 +409,58 @@ func getUserCredsFromJWT(t *jwt.Token) (user, password string, err error) {
 	return user, password, nil
 }
 
-func UnwrapProxyLinkToken(encodedToken string) (user string, link string, headers map[string]string, tunnelType config.TunnelType, err error) {
+func UnwrapProxyLinkToken(r *http.Request, encodedToken string) (user string, link string, headers map[string]string, tunnelType config.TunnelType, err error) {
+	proxyLink, err := unwrapProxyLinkToken(encodedToken)
+	if err != nil {
+		return "", "", nil, "", err
+	}
+
+	if proxyLink.TokenId != "" {
+		token, err := proxy_token.Get(proxyLink.TokenId)
+		if err != nil {
+			return "", "", nil, "", err
+		}
+		if token == nil || token.Username != proxyLink.User {
+			err := core.NewAPIError("revoked token")
+			err.StatusCode = http.StatusUnauthorized
+			return "", "", nil, "", err
+		}
+		if !token.IsAllowedIP(core.GetRequestIP(r)) {
+			err := core.NewAPIError("ip not allowed")
+			err.StatusCode = http.StatusForbidden
+			return "", "", nil, "", err
+		}
+	}
+
+	return proxyLink.User, proxyLink.Value, proxyLink.Headers, proxyLink.TunT, nil
+}
+
+func unwrapProxyLinkToken(encodedToken string) (*proxyLinkData, error) {
 	proxyLink := &proxyLinkData{}
 	if found := proxyLinkTokenCache.Get(encodedToken, proxyLink); found {
-		return proxyLink.User, proxyLink.Value, proxyLink.Headers, proxyLink.TunT, nil
+		return proxyLink, nil
 	}
 
 	if strings.HasPrefix(encodedToken, "base64.") {
 		blob, err := core.Base64DecodeToByte(strings.TrimPrefix(encodedToken, "base64."))
 		if err != nil {
-			return "", "", nil, "", err
+			return nil, err
 		}
 		if err := json.Unmarshal(blob, proxyLink); err != nil {
-			return "", "", nil, "", err
+			return nil, err
 		}
 		user, pass, _ := strings.Cut(proxyLink.User, ":")
 		if pass != config.ProxyAuthPassword.GetPassword(user) {
 			err := core.NewAPIError("unauthorized")
 			err.StatusCode = http.StatusUnauthorized
-			return "", "", nil, "", err
+			return nil, err
 		}
 		proxyLink.User = user
 	} else {
 		claims := &core.JWTClaims[proxyLinkTokenData]{}
-		password := ""
-		_, err = core.ParseJWT(func(t *jwt.Token) (any, error) {
+		user, password := "", ""
+		_, err := core.ParseJWT(func(t *jwt.Token) (any, error) {
+			var err error
 			user, password, err = getUserCredsFromJWT(t)
 			return []byte(password), err
 		}, encodedToken, claims)
@@ -277,20 +473,20 @@ func UnwrapProxyLinkToken(encodedToken string) (user string, link string, header
 				err = rerr
 			}
 
-			return "", "", nil, "", err
+			return nil, err
 		}
 
 		var linkBlob string
 		if claims.Data.EncFormat == "base64" {
 			blob, err := core.Base64Decode(claims.Data.EncLink)
 			if err != nil {
-				return "", "", nil, "", err
+				return nil, err
 			}
 			linkBlob = blob
 		} else {
 			blob, err := core.Decrypt(password, claims.Data.EncLink)
 			if err != nil {
-				return "", "", nil, "", err
+				return nil, err
 			}
 			linkBlob = blob
 		}
@@ -300,6 +496,7 @@ func UnwrapProxyLinkToken(encodedToken string) (user string, link string, header
 		proxyLink.User = user
 		proxyLink.TunT = claims.Data.TunnelType
 		proxyLink.Value = link
+		proxyLink.TokenId = claims.ID
 
 		if hasHeaders {
 			proxyLink.Headers = map[string]string{}
@@ -313,5 +510,5 @@ func UnwrapProxyLinkToken(encodedToken string) (user string, link string, header
 
 	proxyLinkTokenCache.Add(encodedToken, *proxyLink)
 
-	return proxyLink.User, proxyLink.Value, proxyLink.Headers, proxyLink.TunT, nil
+	return proxyLink, nil
 }
@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/store"
+	"github.com/rodezfranco/stremthru/store/qbittorrent"
+)
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+
+	bytesWritten int64
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// serveQBittorrentFile serves the file for qBittorrent locked file link. The
+// path is resolved through the client for every request, so only completed
+// files within the download directory are served.
+func serveQBittorrentFile(w http.ResponseWriter, r *http.Request, user string, link string) (bytesWritten int64, err error) {
+	token := config.StoreAuthToken.GetToken(user, string(store.StoreNameQBittorrent))
+	if token == "" {
+		err := ErrorUnauthorized(r)
+		SendError(w, r, err)
+		return 0, err
+	}
+
+	params := &qbittorrent.GetFilePathParams{Link: link}
+	params.APIKey = token
+	params.Context = r.Context()
+	path, err := qbStore.GetFilePath(params)
+	if err != nil {
+		SendError(w, r, err)
+		return 0, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		e := ErrorNotFound(r)
+		e.Cause = err
+		SendError(w, r, e)
+		return 0, e
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err == nil && stat.IsDir() {
+		err = errors.New("is a directory")
+	}
+	if err != nil {
+		e := ErrorNotFound(r)
+		e.Cause = err
+		SendError(w, r, e)
+		return 0, e
+	}
+
+	cw := &countingResponseWriter{ResponseWriter: w}
+	http.ServeContent(cw, r, stat.Name(), stat.ModTime(), file)
+	return cw.bytesWritten, nil
+}
+
+// ProxyLinkResponse serves the content for the link of a proxy link token.
+func ProxyLinkResponse(w http.ResponseWriter, r *http.Request, user string, link string, tunnelType config.TunnelType) (bytesWritten int64, err error) {
+	if qbittorrent.IsLockedFileLink(link) {
+		return serveQBittorrentFile(w, r, user, link)
+	}
+	return CachedProxyResponse(w, r, link, tunnelType)
+}
@@ -0,0 +1,147 @@
+package store_webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/kv"
+)
+
+const maxDeliveryAttempts = 5
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+type Delivery struct {
+	User          string         `json:"user"`
+	URL           string         `json:"url"`
+	Event         Event          `json:"event"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	StatusCode    int            `json:"status_code,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+}
+
+var deliveries = kv.NewKVStore[Delivery](&kv.KVStoreConfig{
+	Type:      "store:webhook:delivery",
+	ExpiresIn: 7 * 24 * time.Hour,
+})
+
+var httpClient = config.GetHTTPClient(config.TUNNEL_TYPE_NONE)
+
+// newDelivery logs the pending delivery, the first attempt is expected to be
+// made right away by the caller.
+func newDelivery(user string, event *Event) *Delivery {
+	url := config.StoreWebhook.GetURL(user)
+	if url == "" {
+		return nil
+	}
+	d := &Delivery{
+		User:          user,
+		URL:           url,
+		Event:         *event,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: time.Now().Add(getRetryDelay(1)),
+	}
+	d.save()
+	return d
+}
+
+func (d *Delivery) save() {
+	if err := deliveries.Set(d.Event.Id, *d); err != nil {
+		log.Error("failed to save webhook delivery", "error", err, "event_id", d.Event.Id)
+	}
+}
+
+// getRetryDelay backs off exponentially, starting at 1 minute.
+func getRetryDelay(attempts int) time.Duration {
+	return time.Minute << (attempts - 1)
+}
+
+// sign computes the hex encoded HMAC-SHA256 of `<timestamp>.<body>`.
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Delivery) send() (int, error) {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "stremthru")
+	req.Header.Set("X-StremThru-Event", d.Event.Type)
+	req.Header.Set("X-StremThru-Delivery", d.Event.Id)
+	req.Header.Set("X-StremThru-Signature", "t="+timestamp+",v1="+sign(config.StoreWebhook.GetSecret(), timestamp, body))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, errors.New("unexpected status: " + res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func (d *Delivery) attempt() {
+	d.Attempts++
+	statusCode, err := d.send()
+	d.StatusCode = statusCode
+	if err == nil {
+		d.Status = DeliveryStatusDelivered
+		d.Error = ""
+	} else {
+		d.Error = err.Error()
+		if d.Attempts >= maxDeliveryAttempts {
+			d.Status = DeliveryStatusFailed
+			log.Warn("webhook delivery failed", "error", err, "user", d.User, "event_id", d.Event.Id, "attempts", d.Attempts)
+		} else {
+			d.NextAttemptAt = time.Now().Add(getRetryDelay(d.Attempts))
+			log.Debug("webhook delivery failed, will retry", "error", err, "user", d.User, "event_id", d.Event.Id, "attempts", d.Attempts)
+		}
+	}
+	d.save()
+}
+
+func retryDeliveries() error {
+	ds, err := deliveries.List()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range ds {
+		d := &ds[i].Value
+		if d.Status != DeliveryStatusPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.attempt()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package store_webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliverySend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, EventTypeMagnetStatus, r.Header.Get("X-StremThru-Event"))
+		assert.Equal(t, "evt", r.Header.Get("X-StremThru-Delivery"))
+
+		timestamp, signature, _ := strings.Cut(strings.TrimPrefix(r.Header.Get("X-StremThru-Signature"), "t="), ",v1=")
+		assert.Equal(t, sign(config.StoreWebhook.GetSecret(), timestamp, body), signature)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := &Delivery{
+		URL:   server.URL + "/ok",
+		Event: Event{Id: "evt", Type: EventTypeMagnetStatus},
+	}
+	statusCode, err := d.send()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	d.URL = server.URL + "/fail"
+	statusCode, err = d.send()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, sign("secret", "1700000000", []byte(`{}`)), sign("secret", "1700000000", []byte(`{}`)))
+	assert.NotEqual(t, sign("secret", "1700000000", []byte(`{}`)), sign("secret", "1700000001", []byte(`{}`)))
+	assert.NotEqual(t, sign("secret", "1700000000", []byte(`{}`)), sign("other", "1700000000", []byte(`{}`)))
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	assert.Equal(t, 1*time.Minute, getRetryDelay(1))
+	assert.Equal(t, 8*time.Minute, getRetryDelay(4))
+}
@@ -0,0 +1,5 @@
+package store_webhook
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("store/webhook")
@@ -0,0 +1,189 @@
+package store_webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/kv"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+const EventTypeMagnetStatus = "magnet.status"
+
+type MagnetEventData struct {
+	Store      store.StoreName    `json:"store"`
+	Id         string             `json:"id"`
+	Hash       string             `json:"hash"`
+	Name       string             `json:"name"`
+	Status     store.MagnetStatus `json:"status"`
+	PrevStatus store.MagnetStatus `json:"prev_status"`
+}
+
+type Event struct {
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      MagnetEventData `json:"data"`
+}
+
+func newEventId() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type trackedMagnet struct {
+	User   string             `json:"user"`
+	Store  store.StoreName    `json:"store"`
+	Id     string             `json:"id"`
+	Hash   string             `json:"hash"`
+	Name   string             `json:"name"`
+	Status store.MagnetStatus `json:"status"`
+}
+
+func (tm trackedMagnet) key() string {
+	return tm.User + ":" + string(tm.Store) + ":" + tm.Id
+}
+
+// magnets stuck without any status change are dropped on expiry
+var trackedMagnets = kv.NewKVStore[trackedMagnet](&kv.KVStoreConfig{
+	Type:      "store:webhook:magnet",
+	ExpiresIn: 7 * 24 * time.Hour,
+})
+
+func notify(tm *trackedMagnet, prevStatus store.MagnetStatus) *Delivery {
+	return newDelivery(tm.User, &Event{
+		Id:        newEventId(),
+		Type:      EventTypeMagnetStatus,
+		CreatedAt: time.Now().UTC(),
+		Data: MagnetEventData{
+			Store:      tm.Store,
+			Id:         tm.Id,
+			Hash:       tm.Hash,
+			Name:       tm.Name,
+			Status:     tm.Status,
+			PrevStatus: prevStatus,
+		},
+	})
+}
+
+// TrackMagnet notifies the user's webhook about the added magnet, and keeps
+// watching it for status changes until it reaches a final status. Status
+// changes are only watched if the magnet was added with the user's
+// configured credential, as that is what is used for checking it later.
+func TrackMagnet(user string, storeName store.StoreName, storeToken string, data *store.AddMagnetData) {
+	if user == "" || config.StoreWebhook.GetURL(user) == "" {
+		return
+	}
+
+	tm := &trackedMagnet{
+		User:   user,
+		Store:  storeName,
+		Id:     data.Id,
+		Hash:   data.Hash,
+		Name:   data.Name,
+		Status: data.Status,
+	}
+
+	if d := notify(tm, ""); d != nil {
+		go d.attempt()
+	}
+
+	if tm.Status.IsFinal() {
+		return
+	}
+	if _, token := getStore(user, storeName); storeName != store.StoreNameMulti && token != storeToken {
+		log.Debug("not tracking magnet, added with different credential", "store", tm.Store, "id", tm.Id)
+		return
+	}
+	if err := trackedMagnets.Set(tm.key(), *tm); err != nil {
+		log.Error("failed to track magnet", "error", err, "store", tm.Store, "id", tm.Id)
+	}
+}
+
+// getStore returns the store and the token configured for the user. Multi
+// store uses its member stores' tokens, so the token is not used for it.
+func getStore(user string, storeName store.StoreName) (store.Store, string) {
+	if storeName == store.StoreNameMulti {
+		if len(config.StoreAuthToken.ListStores(user)) == 0 {
+			return nil, ""
+		}
+		return shared.GetMultiStore(user), ""
+	}
+	return shared.GetStore(string(storeName)), config.StoreAuthToken.GetToken(user, string(storeName))
+}
+
+func isNotFoundError(err error) bool {
+	var serr core.StremThruError
+	return errors.As(err, &serr) && serr.GetStatusCode() == http.StatusNotFound
+}
+
+func checkTrackedMagnet(tm *trackedMagnet) {
+	key := tm.key()
+
+	s, token := getStore(tm.User, tm.Store)
+	if s == nil || (token == "" && tm.Store != store.StoreNameMulti) {
+		log.Warn("untracking magnet, missing store credential", "user", tm.User, "store", tm.Store, "id", tm.Id)
+		if err := trackedMagnets.Del(key); err != nil {
+			log.Error("failed to untrack magnet", "error", err, "store", tm.Store, "id", tm.Id)
+		}
+		return
+	}
+
+	params := &store.GetMagnetParams{Id: tm.Id}
+	params.APIKey = token
+	m, err := s.GetMagnet(params)
+	if err != nil {
+		if !isNotFoundError(err) {
+			log.Error("failed to get magnet", "error", core.PackError(err), "store", tm.Store, "id", tm.Id)
+			return
+		}
+		log.Debug("untracking magnet, not found", "store", tm.Store, "id", tm.Id)
+		if err := trackedMagnets.Del(key); err != nil {
+			log.Error("failed to untrack magnet", "error", err, "store", tm.Store, "id", tm.Id)
+		}
+		return
+	}
+
+	if m.Status == tm.Status {
+		return
+	}
+
+	prevStatus := tm.Status
+	tm.Status = m.Status
+	if m.Name != "" {
+		tm.Name = m.Name
+	}
+	if d := notify(tm, prevStatus); d != nil {
+		d.attempt()
+	}
+
+	if tm.Status.IsFinal() {
+		err = trackedMagnets.Del(key)
+	} else {
+		err = trackedMagnets.Set(key, *tm)
+	}
+	if err != nil {
+		log.Error("failed to update tracked magnet", "error", err, "store", tm.Store, "id", tm.Id)
+	}
+}
+
+// Process checks the tracked magnets for status changes and retries the
+// pending webhook deliveries.
+func Process() error {
+	tms, err := trackedMagnets.List()
+	if err != nil {
+		return err
+	}
+	for i := range tms {
+		checkTrackedMagnet(&tms[i].Value)
+	}
+
+	return retryDeliveries()
+}
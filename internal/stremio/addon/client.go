@@ -27,11 +27,16 @@ var DefaultHTTPClient = func() *http.Client {
 
 type ClientConfig struct {
 	HTTPClient *http.Client
+	// TrackHealth skips the upstream addon temporarily after consecutive
+	// failures, tracked per base url.
+	TrackHealth bool
 }
 
 type Client struct {
 	HTTPClient *http.Client
 
+	trackHealth bool
+
 	reqQuery  func(query *url.Values, params request.Context)
 	reqHeader func(query *http.Header, params request.Context)
 }
@@ -64,6 +69,7 @@ func NewClient(conf *ClientConfig) *Client {
 	c := &Client{}
 
 	c.HTTPClient = conf.HTTPClient
+	c.trackHealth = conf.TrackHealth
 
 	c.reqQuery = func(query *url.Values, params request.Context) {
 	}
@@ -119,6 +125,10 @@ func processResponseBody(res *http.Response, err error, v any) error {
 }
 
 func (c Client) Request(method string, url *url.URL, params request.Context, v any) (*http.Response, error) {
+	return c.request(method, url, nil, params, v)
+}
+
+func (c Client) request(method string, url *url.URL, baseUrl *url.URL, params request.Context, v any) (*http.Response, error) {
 	if params == nil {
 		params = &Ctx{}
 	}
@@ -128,8 +138,16 @@ func (c Client) Request(method string, url *url.URL, params request.Context, v a
 		error.Cause = err
 		return nil, error
 	}
+	trackHealth := c.trackHealth && baseUrl != nil
+	if trackHealth && !healthTracker.allow(baseUrl) {
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
 	res, err := c.HTTPClient.Do(req)
 	err = processResponseBody(res, err, v)
+	if trackHealth {
+		healthTracker.record(baseUrl, time.Since(start), err)
+	}
 	if err != nil {
 		error := core.NewUpstreamError("")
 		if rerr, ok := err.(*core.Error); ok {
@@ -180,7 +198,7 @@ type GetManifestParams struct {
 func (c Client) GetManifest(params *GetManifestParams) (request.APIResponse[stremio.Manifest], error) {
 	adjustClientIPHeader(params.Ctx, params.ClientIP, nil)
 	response := &stremio.Manifest{}
-	res, err := c.Request("GET", params.BaseURL.JoinPath("manifest.json"), params, response)
+	res, err := c.request("GET", params.BaseURL.JoinPath("manifest.json"), params.BaseURL, params, response)
 	if err == nil && !response.IsValid() {
 		err = errors.New("invalid manifest")
 	}
@@ -203,7 +221,7 @@ func (c Client) FetchStream(params *FetchStreamParams) (request.APIResponse[stre
 	apiResponse, err, _ := fetchStreamGroup.Do(url.String(), func() (any, error) {
 		adjustClientIPHeader(params.Ctx, params.ClientIP, nil)
 		response := &stremio.StreamHandlerResponse{}
-		res, err := c.Request("GET", url, params, response)
+		res, err := c.request("GET", url, params.BaseURL, params, response)
 		return request.NewAPIResponse(res, *response), err
 	})
 	return apiResponse.(request.APIResponse[stremio.StreamHandlerResponse]), err
@@ -225,7 +243,7 @@ func (c Client) FetchCatalog(params *FetchCatalogParams) (request.APIResponse[st
 	}
 	adjustClientIPHeader(params.Ctx, params.ClientIP, nil)
 	response := &stremio.CatalogHandlerResponse{}
-	res, err := c.Request("GET", params.BaseURL.JoinPath(path), params, response)
+	res, err := c.request("GET", params.BaseURL.JoinPath(path), params.BaseURL, params, response)
 	return request.NewAPIResponse(res, *response), err
 }
 
@@ -241,7 +259,7 @@ func (c Client) FetchMeta(params *FetchMetaParams) (request.APIResponse[stremio.
 	path := "meta/" + params.Type + "/" + params.Id
 	adjustClientIPHeader(params.Ctx, params.ClientIP, nil)
 	response := &stremio.MetaHandlerResponse{}
-	res, err := c.Request("GET", params.BaseURL.JoinPath(path), params, response)
+	res, err := c.request("GET", params.BaseURL.JoinPath(path), params.BaseURL, params, response)
 	return request.NewAPIResponse(res, *response), err
 }
 
@@ -261,7 +279,7 @@ func (c Client) FetchSubtitles(params *FetchSubtitlesParams) (request.APIRespons
 	}
 	adjustClientIPHeader(params.Ctx, params.ClientIP, nil)
 	response := &stremio.SubtitlesHandlerResponse{}
-	res, err := c.Request("GET", params.BaseURL.JoinPath(path), params, response)
+	res, err := c.request("GET", params.BaseURL.JoinPath(path), params.BaseURL, params, response)
 	return request.NewAPIResponse(res, *response), err
 }
 
@@ -0,0 +1,212 @@
+package stremio_addon
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+)
+
+type CircuitState string
+
+const (
+	CircuitStateClosed   CircuitState = "closed"
+	CircuitStateOpen     CircuitState = "open"
+	CircuitStateHalfOpen CircuitState = "half_open"
+)
+
+const circuitFailureThreshold = 5
+const circuitOpenDuration = 30 * time.Second
+const circuitMaxOpenDuration = 10 * time.Minute
+
+// health of an upstream not requested for this long is dropped
+const healthIdleTimeout = 30 * time.Minute
+
+// weight of the latest request for the moving averages
+const healthEWMAWeight = 0.2
+
+var ErrCircuitOpen = errors.New("upstream addon is unavailable, skipped temporarily")
+
+type UpstreamHealth struct {
+	Host              string        `json:"host"`
+	State             CircuitState  `json:"state"`
+	RequestCount      int64         `json:"request_count"`
+	ErrorCount        int64         `json:"error_count"`
+	ErrorRate         float64       `json:"error_rate"`
+	Latency           time.Duration `json:"-"`
+	LatencyMs         int64         `json:"latency_ms"`
+	ConsecutiveErrors int           `json:"consecutive_errors"`
+	LastError         string        `json:"last_error,omitempty"`
+	LastErrorAt       time.Time     `json:"last_error_at,omitzero"`
+	OpenedAt          time.Time     `json:"opened_at,omitzero"`
+	OpenUntil         time.Time     `json:"open_until,omitzero"`
+
+	openCount int
+	probing   bool
+	usedAt    time.Time
+}
+
+func (h *UpstreamHealth) IsHealthy() bool {
+	return h.State == CircuitStateClosed
+}
+
+func (h *UpstreamHealth) snapshot() UpstreamHealth {
+	s := *h
+	s.LatencyMs = h.Latency.Milliseconds()
+	return s
+}
+
+type upstreamHealthTracker struct {
+	m       sync.Mutex
+	byKey   map[string]*UpstreamHealth
+	sweptAt time.Time
+}
+
+var healthTracker = &upstreamHealthTracker{byKey: map[string]*UpstreamHealth{}}
+
+// getHealthKey keys the health by the addon's base url, which includes the
+// user's configuration. So a broken configuration of one user does not
+// affect other users of the same addon.
+func getHealthKey(baseUrl *url.URL) string {
+	return baseUrl.String()
+}
+
+func (t *upstreamHealthTracker) get(baseUrl *url.URL) *UpstreamHealth {
+	now := time.Now()
+	if now.Sub(t.sweptAt) >= healthIdleTimeout {
+		for key, h := range t.byKey {
+			if now.Sub(h.usedAt) >= healthIdleTimeout {
+				delete(t.byKey, key)
+			}
+		}
+		t.sweptAt = now
+	}
+
+	key := getHealthKey(baseUrl)
+	h, ok := t.byKey[key]
+	if !ok {
+		h = &UpstreamHealth{Host: baseUrl.Host, State: CircuitStateClosed}
+		t.byKey[key] = h
+	}
+	h.usedAt = now
+	return h
+}
+
+// allow checks if request can be sent to the host. When the circuit is open,
+// a single probe request is allowed after the open duration.
+func (t *upstreamHealthTracker) allow(baseUrl *url.URL) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	h := t.get(baseUrl)
+	switch h.State {
+	case CircuitStateOpen:
+		if time.Now().Before(h.OpenUntil) {
+			return false
+		}
+		h.State = CircuitStateHalfOpen
+		h.probing = true
+		return true
+	case CircuitStateHalfOpen:
+		if h.probing {
+			return false
+		}
+		h.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (t *upstreamHealthTracker) record(baseUrl *url.URL, latency time.Duration, err error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	h := t.get(baseUrl)
+	h.RequestCount++
+	if h.Latency == 0 {
+		h.Latency = latency
+	} else {
+		h.Latency = time.Duration(float64(h.Latency)*(1-healthEWMAWeight) + float64(latency)*healthEWMAWeight)
+	}
+
+	isFailure := isHealthFailure(err)
+	errorValue := 0.0
+	if isFailure {
+		errorValue = 1.0
+	}
+	h.ErrorRate = h.ErrorRate*(1-healthEWMAWeight) + errorValue*healthEWMAWeight
+
+	if !isFailure {
+		h.ConsecutiveErrors = 0
+		h.State = CircuitStateClosed
+		h.probing = false
+		h.openCount = 0
+		h.OpenedAt = time.Time{}
+		h.OpenUntil = time.Time{}
+		return
+	}
+
+	h.ErrorCount++
+	h.ConsecutiveErrors++
+	h.LastError = err.Error()
+	h.LastErrorAt = time.Now()
+
+	if h.State == CircuitStateHalfOpen || h.ConsecutiveErrors >= circuitFailureThreshold {
+		// failed probe keeps the circuit open for longer
+		openDuration := min(circuitOpenDuration<<h.openCount, circuitMaxOpenDuration)
+		h.openCount++
+		h.State = CircuitStateOpen
+		h.probing = false
+		h.OpenedAt = time.Now()
+		h.OpenUntil = h.OpenedAt.Add(openDuration)
+	}
+}
+
+// isHealthFailure ignores client errors, e.g. 404 for missing content is
+// a valid response from an addon.
+func isHealthFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.StatusCode >= http.StatusInternalServerError || rerr.StatusCode == http.StatusTooManyRequests
+	}
+	var cerr core.StremThruError
+	if errors.As(err, &cerr) {
+		if statusCode := cerr.GetStatusCode(); statusCode != 0 && statusCode < http.StatusInternalServerError && statusCode != http.StatusTooManyRequests {
+			return false
+		}
+	}
+	return true
+}
+
+func GetUpstreamHealth(baseUrl *url.URL) UpstreamHealth {
+	healthTracker.m.Lock()
+	defer healthTracker.m.Unlock()
+
+	if h, ok := healthTracker.byKey[getHealthKey(baseUrl)]; ok {
+		return h.snapshot()
+	}
+	return UpstreamHealth{Host: baseUrl.Host, State: CircuitStateClosed}
+}
+
+func ListUpstreamHealth() []UpstreamHealth {
+	healthTracker.m.Lock()
+	defer healthTracker.m.Unlock()
+
+	items := make([]UpstreamHealth, 0, len(healthTracker.byKey))
+	for _, h := range healthTracker.byKey {
+		items = append(items, h.snapshot())
+	}
+	slices.SortFunc(items, func(a, b UpstreamHealth) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+	return items
+}
@@ -0,0 +1,63 @@
+package stremio_addon
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamHealthTracker(t *testing.T) {
+	tracker := &upstreamHealthTracker{byKey: map[string]*UpstreamHealth{}}
+	baseUrl, _ := url.Parse("https://addon.example.com/config-a")
+
+	notFound := &ResponseError{StatusCode: 404}
+	for range circuitFailureThreshold {
+		assert.True(t, tracker.allow(baseUrl))
+		tracker.record(baseUrl, time.Millisecond, notFound)
+	}
+	assert.Equal(t, CircuitStateClosed, tracker.get(baseUrl).State, "client errors are not failures")
+
+	failure := errors.New("connection refused")
+	for range circuitFailureThreshold {
+		assert.True(t, tracker.allow(baseUrl))
+		tracker.record(baseUrl, time.Millisecond, failure)
+	}
+	h := tracker.get(baseUrl)
+	assert.Equal(t, CircuitStateOpen, h.State)
+	assert.False(t, tracker.allow(baseUrl))
+
+	h.OpenUntil = time.Now().Add(-time.Second)
+	assert.True(t, tracker.allow(baseUrl), "probe is allowed after open duration")
+	assert.Equal(t, CircuitStateHalfOpen, h.State)
+	assert.False(t, tracker.allow(baseUrl), "only single probe is allowed")
+
+	tracker.record(baseUrl, time.Millisecond, failure)
+	assert.Equal(t, CircuitStateOpen, h.State)
+	assert.Equal(t, 2*circuitOpenDuration, h.OpenUntil.Sub(h.OpenedAt), "failed probe doubles open duration")
+
+	h.OpenUntil = time.Now().Add(-time.Second)
+	assert.True(t, tracker.allow(baseUrl))
+	tracker.record(baseUrl, time.Millisecond, nil)
+	assert.Equal(t, CircuitStateClosed, h.State)
+	assert.Equal(t, 0, h.ConsecutiveErrors)
+	assert.True(t, tracker.allow(baseUrl))
+
+	t.Run("keyed by base url", func(t *testing.T) {
+		other, _ := url.Parse("https://addon.example.com/config-b")
+		for range circuitFailureThreshold {
+			tracker.record(baseUrl, time.Millisecond, failure)
+		}
+		assert.False(t, tracker.allow(baseUrl))
+		assert.True(t, tracker.allow(other))
+	})
+
+	t.Run("evicts idle", func(t *testing.T) {
+		tracker.get(baseUrl).usedAt = time.Now().Add(-healthIdleTimeout)
+		tracker.sweptAt = time.Time{}
+		tracker.get(&url.URL{Host: "other.example.com"})
+		assert.NotContains(t, tracker.byKey, getHealthKey(baseUrl))
+	})
+}
@@ -0,0 +1,125 @@
+package stremio_api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WatchedBitField is the watched state of the videos of a library item,
+// stored in `state.watched` as `<anchor_video_id>:<anchor_length>:<bitfield>`.
+// The bitfield is zlib compressed and base64 encoded, with a bit for each
+// video in the order of the meta's videos.
+type WatchedBitField struct {
+	values   []byte
+	videoIds []string
+}
+
+func getBit(values []byte, i int) bool {
+	idx := i / 8
+	return idx < len(values) && values[idx]&(1<<(i%8)) != 0
+}
+
+// NewWatchedBitField parses `serialized` for `videoIds`. The bitfield is
+// shifted using the anchor video, so that it lines up with videos added
+// since it was serialized.
+func NewWatchedBitField(serialized string, videoIds []string) (*WatchedBitField, error) {
+	wb := &WatchedBitField{
+		values:   make([]byte, (len(videoIds)+7)/8),
+		videoIds: videoIds,
+	}
+	if serialized == "" {
+		return wb, nil
+	}
+
+	parts := strings.Split(serialized, ":")
+	if len(parts) < 3 {
+		return nil, errors.New("invalid watched bitfield")
+	}
+	anchorLength, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return nil, errors.New("invalid watched bitfield anchor length")
+	}
+	anchorVideoId := strings.Join(parts[:len(parts)-2], ":")
+
+	compressed, err := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	values, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if idx := slices.Index(videoIds, anchorVideoId); idx != -1 {
+		offset = anchorLength - idx - 1
+	}
+	for i := range videoIds {
+		if prev := i + offset; prev >= 0 && getBit(values, prev) {
+			wb.set(i, true)
+		}
+	}
+	return wb, nil
+}
+
+func (wb *WatchedBitField) set(i int, watched bool) {
+	if watched {
+		wb.values[i/8] |= 1 << (i % 8)
+	} else {
+		wb.values[i/8] &^= 1 << (i % 8)
+	}
+}
+
+func (wb *WatchedBitField) IsWatched(videoId string) bool {
+	idx := slices.Index(wb.videoIds, videoId)
+	return idx != -1 && getBit(wb.values, idx)
+}
+
+// SetWatched marks the video as watched, unknown video is ignored.
+func (wb *WatchedBitField) SetWatched(videoId string, watched bool) {
+	if idx := slices.Index(wb.videoIds, videoId); idx != -1 {
+		wb.set(idx, watched)
+	}
+}
+
+// ListWatched returns the ids of the watched videos.
+func (wb *WatchedBitField) ListWatched() []string {
+	videoIds := []string{}
+	for i, videoId := range wb.videoIds {
+		if getBit(wb.values, i) {
+			videoIds = append(videoIds, videoId)
+		}
+	}
+	return videoIds
+}
+
+func (wb *WatchedBitField) String() string {
+	lastIdx := 0
+	for i := len(wb.videoIds) - 1; i >= 0; i-- {
+		if getBit(wb.values, i) {
+			lastIdx = i
+			break
+		}
+	}
+	lastVideoId := "undefined"
+	if lastIdx < len(wb.videoIds) {
+		lastVideoId = wb.videoIds[lastIdx]
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(wb.values)
+	zw.Close()
+	return lastVideoId + ":" + strconv.Itoa(lastIdx+1) + ":" + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
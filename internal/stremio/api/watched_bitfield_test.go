@@ -0,0 +1,51 @@
+package stremio_api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchedBitField(t *testing.T) {
+	videoIds := []string{}
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"} {
+		videoIds = append(videoIds, "tt2934286:1:"+id)
+	}
+
+	wb, err := NewWatchedBitField("tt2934286:1:5:5:eJyTZwAAAEAAIA==", videoIds)
+	assert.NoError(t, err)
+	assert.Equal(t, videoIds[0:5], wb.ListWatched())
+	assert.True(t, wb.IsWatched("tt2934286:1:5"))
+	assert.False(t, wb.IsWatched("tt2934286:1:6"))
+
+	wb.SetWatched("tt2934286:1:9", true)
+	wb.SetWatched("tt2934286:1:1", false)
+	wb.SetWatched("tt2934286:2:1", true)
+	assert.Equal(t, append(videoIds[1:5:5], "tt2934286:1:9"), wb.ListWatched())
+
+	serialized := wb.String()
+	assert.Regexp(t, `^tt2934286:1:9:9:`, serialized)
+
+	t.Run("roundtrip", func(t *testing.T) {
+		wb, err := NewWatchedBitField(serialized, videoIds)
+		assert.NoError(t, err)
+		assert.Equal(t, append(videoIds[1:5:5], "tt2934286:1:9"), wb.ListWatched())
+	})
+
+	t.Run("shifts for prepended video", func(t *testing.T) {
+		wb, err := NewWatchedBitField(serialized, append([]string{"tt2934286:0:1"}, videoIds...))
+		assert.NoError(t, err)
+		assert.Equal(t, append(videoIds[1:5:5], "tt2934286:1:9"), wb.ListWatched())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		wb, err := NewWatchedBitField("", videoIds)
+		assert.NoError(t, err)
+		assert.Empty(t, wb.ListWatched())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewWatchedBitField("tt2934286:1", videoIds)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,238 @@
+package stremio_backup
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/kv"
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+const (
+	DefaultKeepCount = 7
+	MaxKeepCount     = 30
+)
+
+var ErrBackupNotFound = errors.New("backup not found")
+
+type Schedule struct {
+	StremioAuthKey string        `json:"stremio_auth_key"`
+	StremioEmail   string        `json:"stremio_email"`
+	Interval       time.Duration `json:"interval"`
+	KeepCount      int           `json:"keep_count"`
+	LastBackupAt   time.Time     `json:"last_backup_at"`
+	LastError      string        `json:"last_error,omitempty"`
+}
+
+func (s *Schedule) IsDue() bool {
+	return time.Since(s.LastBackupAt) >= s.Interval
+}
+
+type Backup struct {
+	Version   int64                     `json:"version"`
+	CreatedAt time.Time                 `json:"created_at"`
+	Addons    []stremio.Addon           `json:"addons"`
+	Library   []stremio_api.LibraryItem `json:"library"`
+}
+
+type BackupInfo struct {
+	Version      int64
+	CreatedAt    time.Time
+	AddonCount   int
+	LibraryCount int
+}
+
+var schedules = kv.NewKVStore[Schedule](&kv.KVStoreConfig{
+	Type: "stremio:backup:schedule",
+})
+
+var backups = kv.NewKVStore[Backup](&kv.KVStoreConfig{
+	Type: "stremio:backup",
+})
+
+var client = stremio_api.NewClient(&stremio_api.ClientConfig{})
+
+func GetSchedule(email string) (*Schedule, error) {
+	s := &Schedule{}
+	if err := schedules.GetValue(email, s); err != nil {
+		return nil, err
+	}
+	if s.StremioEmail == "" {
+		return nil, nil
+	}
+	return s, nil
+}
+
+func SaveSchedule(s *Schedule) error {
+	if s.StremioEmail == "" {
+		return errors.New("missing stremio email")
+	}
+	if s.Interval < time.Hour {
+		s.Interval = 24 * time.Hour
+	}
+	if s.KeepCount <= 0 {
+		s.KeepCount = DefaultKeepCount
+	}
+	s.KeepCount = min(s.KeepCount, MaxKeepCount)
+	return schedules.Set(s.StremioEmail, *s)
+}
+
+func DeleteSchedule(email string) error {
+	return schedules.Del(email)
+}
+
+func listBackups(email string) ([]kv.ParsedKV[Backup], error) {
+	items, err := backups.WithScope(email).List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(items, func(a, b kv.ParsedKV[Backup]) int {
+		return cmp.Compare(b.Value.Version, a.Value.Version)
+	})
+	return items, nil
+}
+
+// ListBackups returns the backups, newest first.
+func ListBackups(email string) ([]BackupInfo, error) {
+	items, err := listBackups(email)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]BackupInfo, len(items))
+	for i := range items {
+		b := &items[i].Value
+		infos[i] = BackupInfo{
+			Version:      b.Version,
+			CreatedAt:    b.CreatedAt,
+			AddonCount:   len(b.Addons),
+			LibraryCount: countLibraryItems(b.Library),
+		}
+	}
+	return infos, nil
+}
+
+func GetBackup(email string, version int64) (*Backup, error) {
+	b := &Backup{}
+	if err := backups.WithScope(email).GetValue(strconv.FormatInt(version, 10), b); err != nil {
+		return nil, err
+	}
+	if b.Version == 0 {
+		return nil, ErrBackupNotFound
+	}
+	return b, nil
+}
+
+var mutex sync.Mutex
+
+// CreateBackup saves the addon collection and library of the account, and
+// drops the oldest backups beyond the schedule's keep count.
+func CreateBackup(s *Schedule) (*Backup, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	b, err := createBackup(s)
+	if err != nil {
+		s.LastError = err.Error()
+	} else {
+		s.LastError = ""
+		s.LastBackupAt = b.CreatedAt
+	}
+	if s.Interval != 0 {
+		if serr := SaveSchedule(s); serr != nil {
+			log.Error("failed to save backup schedule", "error", serr, "email", s.StremioEmail)
+		}
+	}
+	return b, err
+}
+
+func createBackup(s *Schedule) (*Backup, error) {
+	addonsParams := &stremio_api.GetAddonsParams{}
+	addonsParams.APIKey = s.StremioAuthKey
+	addonsRes, err := client.GetAddons(addonsParams)
+	if err != nil {
+		return nil, errors.New("failed to get addons: " + err.Error())
+	}
+
+	libraryParams := &stremio_api.GetAllLibraryItemsParams{}
+	libraryParams.APIKey = s.StremioAuthKey
+	libraryRes, err := client.GetAllLibraryItems(libraryParams)
+	if err != nil {
+		return nil, errors.New("failed to get library: " + err.Error())
+	}
+
+	now := time.Now()
+	b := &Backup{
+		Version:   now.UnixMilli(),
+		CreatedAt: now,
+		Addons:    addonsRes.Data.Addons,
+		Library:   libraryRes.Data,
+	}
+
+	store := backups.WithScope(s.StremioEmail)
+	if err := store.Set(strconv.FormatInt(b.Version, 10), *b); err != nil {
+		return nil, err
+	}
+
+	keepCount := s.KeepCount
+	if keepCount <= 0 {
+		keepCount = DefaultKeepCount
+	}
+	items, err := listBackups(s.StremioEmail)
+	if err != nil {
+		return b, err
+	}
+	for i := keepCount; i < len(items); i++ {
+		if err := store.Del(items[i].Key); err != nil {
+			log.Error("failed to delete old backup", "error", err, "email", s.StremioEmail, "version", items[i].Key)
+		}
+	}
+
+	return b, nil
+}
+
+// RestoreBackup replaces the addon collection, and puts back the library
+// items from the backup. Items added to the library after the backup are kept.
+func RestoreBackup(authKey string, b *Backup) error {
+	addonsParams := &stremio_api.SetAddonsParams{Addons: b.Addons}
+	addonsParams.APIKey = authKey
+	if _, err := client.SetAddons(addonsParams); err != nil {
+		return errors.New("failed to restore addons: " + err.Error())
+	}
+
+	if len(b.Library) == 0 {
+		return nil
+	}
+	libraryParams := &stremio_api.UpdateLibraryItemsParams{Changes: b.Library}
+	libraryParams.APIKey = authKey
+	res, err := client.UpdateLibraryItems(libraryParams)
+	if err != nil {
+		return errors.New("failed to restore library: " + err.Error())
+	}
+	if !res.Data.Success {
+		return errors.New("failed to restore library")
+	}
+	return nil
+}
+
+// ProcessSchedules creates backup for the accounts that are due.
+func ProcessSchedules() error {
+	items, err := schedules.List()
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		s := &items[i].Value
+		if !s.IsDue() {
+			continue
+		}
+		if _, err := CreateBackup(s); err != nil {
+			log.Warn("failed to create backup", "error", err, "email", s.StremioEmail)
+		}
+	}
+	return nil
+}
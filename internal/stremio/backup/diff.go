@@ -0,0 +1,194 @@
+package stremio_backup
+
+import (
+	"strconv"
+
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+type AddonMove struct {
+	Name         string
+	TransportUrl string
+	FromPosition int
+	ToPosition   int
+}
+
+type LibraryItemDiff struct {
+	Id      string
+	Name    string
+	Type    string
+	Changes []string
+}
+
+type BackupDiff struct {
+	AddonsAdded    []stremio.Addon
+	AddonsRemoved  []stremio.Addon
+	AddonsMoved    []AddonMove
+	LibraryAdded   []LibraryItemDiff
+	LibraryRemoved []LibraryItemDiff
+	LibraryChanged []LibraryItemDiff
+}
+
+func (d *BackupDiff) IsEmpty() bool {
+	return len(d.AddonsAdded) == 0 && len(d.AddonsRemoved) == 0 && len(d.AddonsMoved) == 0 &&
+		len(d.LibraryAdded) == 0 && len(d.LibraryRemoved) == 0 && len(d.LibraryChanged) == 0
+}
+
+func countLibraryItems(items []stremio_api.LibraryItem) int {
+	count := 0
+	for i := range items {
+		if !items[i].Removed {
+			count++
+		}
+	}
+	return count
+}
+
+// getStableIndexes returns the indexes (in b) of the longest common
+// subsequence of a and b, the rest of the common items are the ones moved.
+func getStableIndexes(a, b []string) map[int]struct{} {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	stable := map[int]struct{}{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] == b[j] {
+			stable[j] = struct{}{}
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return stable
+}
+
+func diffAddons(diff *BackupDiff, from, to []stremio.Addon) {
+	fromIndex := make(map[string]int, len(from))
+	for i := range from {
+		fromIndex[from[i].TransportUrl] = i
+	}
+	toIndex := make(map[string]int, len(to))
+	for i := range to {
+		toIndex[to[i].TransportUrl] = i
+	}
+
+	fromCommon := []string{}
+	for i := range from {
+		if _, ok := toIndex[from[i].TransportUrl]; ok {
+			fromCommon = append(fromCommon, from[i].TransportUrl)
+		} else {
+			diff.AddonsRemoved = append(diff.AddonsRemoved, from[i])
+		}
+	}
+	toCommon := []string{}
+	for i := range to {
+		if _, ok := fromIndex[to[i].TransportUrl]; ok {
+			toCommon = append(toCommon, to[i].TransportUrl)
+		} else {
+			diff.AddonsAdded = append(diff.AddonsAdded, to[i])
+		}
+	}
+
+	stable := getStableIndexes(fromCommon, toCommon)
+	for j, transportUrl := range toCommon {
+		if _, ok := stable[j]; ok {
+			continue
+		}
+		addon := &to[toIndex[transportUrl]]
+		diff.AddonsMoved = append(diff.AddonsMoved, AddonMove{
+			Name:         addon.Manifest.Name,
+			TransportUrl: transportUrl,
+			FromPosition: fromIndex[transportUrl] + 1,
+			ToPosition:   toIndex[transportUrl] + 1,
+		})
+	}
+}
+
+func formatProgress(state *stremio_api.LibraryItemState) string {
+	if state.Duration <= 0 {
+		return "0%"
+	}
+	return strconv.Itoa(state.TimeOffset*100/state.Duration) + "%"
+}
+
+func getLibraryItemChanges(from, to *stremio_api.LibraryItem) []string {
+	changes := []string{}
+	fromWatched := from.State.FlaggedWatched > 0 || from.State.TimesWatched > 0
+	toWatched := to.State.FlaggedWatched > 0 || to.State.TimesWatched > 0
+	if fromWatched != toWatched {
+		if toWatched {
+			changes = append(changes, "marked watched")
+		} else {
+			changes = append(changes, "marked unwatched")
+		}
+	}
+	if from.State.TimesWatched != to.State.TimesWatched {
+		changes = append(changes, "times watched: "+strconv.Itoa(from.State.TimesWatched)+" → "+strconv.Itoa(to.State.TimesWatched))
+	}
+	if from.State.Watched != to.State.Watched {
+		changes = append(changes, "watched episodes changed")
+	}
+	if from.State.VideoId != to.State.VideoId {
+		changes = append(changes, "video: "+from.State.VideoId+" → "+to.State.VideoId)
+	}
+	if from.State.TimeOffset != to.State.TimeOffset {
+		changes = append(changes, "progress: "+formatProgress(&from.State)+" → "+formatProgress(&to.State))
+	}
+	return changes
+}
+
+func diffLibrary(diff *BackupDiff, from, to []stremio_api.LibraryItem) {
+	fromById := make(map[string]*stremio_api.LibraryItem, len(from))
+	for i := range from {
+		if !from[i].Removed {
+			fromById[from[i].Id] = &from[i]
+		}
+	}
+	toById := make(map[string]struct{}, len(to))
+	for i := range to {
+		item := &to[i]
+		if item.Removed {
+			continue
+		}
+		toById[item.Id] = struct{}{}
+		fromItem, ok := fromById[item.Id]
+		if !ok {
+			diff.LibraryAdded = append(diff.LibraryAdded, LibraryItemDiff{Id: item.Id, Name: item.Name, Type: item.Type})
+			continue
+		}
+		if changes := getLibraryItemChanges(fromItem, item); len(changes) > 0 {
+			diff.LibraryChanged = append(diff.LibraryChanged, LibraryItemDiff{Id: item.Id, Name: item.Name, Type: item.Type, Changes: changes})
+		}
+	}
+	for i := range from {
+		item := &from[i]
+		if item.Removed {
+			continue
+		}
+		if _, ok := toById[item.Id]; !ok {
+			diff.LibraryRemoved = append(diff.LibraryRemoved, LibraryItemDiff{Id: item.Id, Name: item.Name, Type: item.Type})
+		}
+	}
+}
+
+// DiffBackups returns the changes needed to go from one backup to another.
+func DiffBackups(from, to *Backup) *BackupDiff {
+	diff := &BackupDiff{}
+	diffAddons(diff, from.Addons, to.Addons)
+	diffLibrary(diff, from.Library, to.Library)
+	return diff
+}
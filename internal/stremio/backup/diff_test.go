@@ -0,0 +1,54 @@
+package stremio_backup
+
+import (
+	"testing"
+
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/rodezfranco/stremthru/stremio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBackups(t *testing.T) {
+	addon := func(name string) stremio.Addon {
+		return stremio.Addon{
+			TransportUrl: "https://" + name + ".example.com/manifest.json",
+			Manifest:     stremio.Manifest{Name: name},
+		}
+	}
+
+	from := &Backup{
+		Addons: []stremio.Addon{addon("a"), addon("b"), addon("c"), addon("d")},
+		Library: []stremio_api.LibraryItem{
+			{Id: "tt1", Name: "One", Type: "movie"},
+			{Id: "tt2", Name: "Two", Type: "movie"},
+			{Id: "tt3", Name: "Three", Type: "series", State: stremio_api.LibraryItemState{VideoId: "tt3:1:1", TimeOffset: 10, Duration: 100}},
+			{Id: "tt4", Name: "Four", Type: "movie", Removed: true},
+		},
+	}
+	to := &Backup{
+		Addons: []stremio.Addon{addon("d"), addon("a"), addon("c"), addon("e")},
+		Library: []stremio_api.LibraryItem{
+			{Id: "tt1", Name: "One", Type: "movie", State: stremio_api.LibraryItemState{FlaggedWatched: 1}},
+			{Id: "tt2", Name: "Two", Type: "movie", Removed: true},
+			{Id: "tt3", Name: "Three", Type: "series", State: stremio_api.LibraryItemState{VideoId: "tt3:1:2", TimeOffset: 50, Duration: 100}},
+			{Id: "tt4", Name: "Four", Type: "movie"},
+		},
+	}
+
+	diff := DiffBackups(from, to)
+
+	assert.Equal(t, []stremio.Addon{addon("e")}, diff.AddonsAdded)
+	assert.Equal(t, []stremio.Addon{addon("b")}, diff.AddonsRemoved)
+	assert.Equal(t, []AddonMove{
+		{Name: "d", TransportUrl: addon("d").TransportUrl, FromPosition: 4, ToPosition: 1},
+	}, diff.AddonsMoved)
+
+	assert.Equal(t, []LibraryItemDiff{{Id: "tt4", Name: "Four", Type: "movie"}}, diff.LibraryAdded)
+	assert.Equal(t, []LibraryItemDiff{{Id: "tt2", Name: "Two", Type: "movie"}}, diff.LibraryRemoved)
+	assert.Equal(t, []LibraryItemDiff{
+		{Id: "tt1", Name: "One", Type: "movie", Changes: []string{"marked watched"}},
+		{Id: "tt3", Name: "Three", Type: "series", Changes: []string{"video: tt3:1:1 → tt3:1:2", "progress: 10% → 50%"}},
+	}, diff.LibraryChanged)
+
+	assert.True(t, DiffBackups(from, from).IsEmpty())
+}
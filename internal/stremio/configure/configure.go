@@ -18,6 +18,7 @@ const (
 	ConfigTypeCheckbox ConfigType = "checkbox"
 	ConfigTypeSelect   ConfigType = "select"
 	ConfigTypeURL      ConfigType = "url"
+	ConfigTypeTextarea ConfigType = "textarea"
 )
 
 type ConfigAction struct {
@@ -0,0 +1,248 @@
+package stremio_sidekick
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/shared"
+	stremio_backup "github.com/rodezfranco/stremthru/internal/stremio/backup"
+)
+
+func loadBackupsTemplateData(td *TemplateData, email string) error {
+	td.Backups.IsLoaded = true
+	td.Backups.Interval = "24h"
+	td.Backups.KeepCount = stremio_backup.DefaultKeepCount
+
+	s, err := stremio_backup.GetSchedule(email)
+	if err != nil {
+		return err
+	}
+	if s != nil {
+		td.Backups.IsScheduled = true
+		td.Backups.Interval = strconv.Itoa(int(s.Interval.Hours())) + "h"
+		td.Backups.KeepCount = s.KeepCount
+		if !s.LastBackupAt.IsZero() {
+			td.Backups.LastBackupAt = s.LastBackupAt.UTC().Format(time.DateTime) + " UTC"
+		}
+		td.Backups.LastError = s.LastError
+	}
+
+	items, err := stremio_backup.ListBackups(email)
+	if err != nil {
+		return err
+	}
+	td.Backups.Items = items
+	if len(items) > 1 {
+		td.Backups.DiffFrom = items[1].Version
+		td.Backups.DiffTo = items[0].Version
+	}
+	return nil
+}
+
+func getBackupsCookieValue(w http.ResponseWriter, r *http.Request) (*CookieValue, bool) {
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return nil, false
+	}
+	if cookie.IsExpired {
+		shared.ErrorForbidden(r).Send(w, r)
+		return nil, false
+	}
+	return cookie, true
+}
+
+func sendBackupsSection(w http.ResponseWriter, r *http.Request, td *TemplateData) {
+	buf, err := executeTemplate(td, "sidekick_backups_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func handleBackups(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodPost) && !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, ok := getBackupsCookieValue(w, r)
+	if !ok {
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	email := cookie.Email()
+
+	switch r.Method {
+	case http.MethodPost:
+		s, err := stremio_backup.GetSchedule(email)
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+		if s == nil {
+			s = &stremio_backup.Schedule{StremioEmail: email}
+		}
+		s.StremioAuthKey = cookie.AuthKey()
+		if interval, err := time.ParseDuration(r.FormValue("interval")); err == nil {
+			s.Interval = interval
+		}
+		if keepCount, err := strconv.Atoi(r.FormValue("keep_count")); err == nil {
+			s.KeepCount = keepCount
+		}
+		if err := stremio_backup.SaveSchedule(s); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		td.Backups.Message = "Scheduled"
+	case http.MethodDelete:
+		if err := stremio_backup.DeleteSchedule(email); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		td.Backups.Message = "Unscheduled"
+	}
+
+	if err := loadBackupsTemplateData(td, email); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	sendBackupsSection(w, r, td)
+}
+
+func handleBackupsCreate(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, ok := getBackupsCookieValue(w, r)
+	if !ok {
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	email := cookie.Email()
+
+	s, err := stremio_backup.GetSchedule(email)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if s == nil {
+		s = &stremio_backup.Schedule{StremioEmail: email}
+	}
+	s.StremioAuthKey = cookie.AuthKey()
+	if _, err := stremio_backup.CreateBackup(s); err != nil {
+		td.Backups.Error = err.Error()
+	} else {
+		td.Backups.Message = "Backed Up"
+	}
+
+	if err := loadBackupsTemplateData(td, email); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	sendBackupsSection(w, r, td)
+}
+
+func handleBackupsDiff(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, ok := getBackupsCookieValue(w, r)
+	if !ok {
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	email := cookie.Email()
+
+	if err := loadBackupsTemplateData(td, email); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	fromVersion, err := strconv.ParseInt(r.FormValue("from"), 10, 64)
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid from version").Send(w, r)
+		return
+	}
+	toVersion, err := strconv.ParseInt(r.FormValue("to"), 10, 64)
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid to version").Send(w, r)
+		return
+	}
+	td.Backups.DiffFrom = fromVersion
+	td.Backups.DiffTo = toVersion
+
+	from, err := stremio_backup.GetBackup(email, fromVersion)
+	if err == nil {
+		var to *stremio_backup.Backup
+		if to, err = stremio_backup.GetBackup(email, toVersion); err == nil {
+			td.Backups.Diff = stremio_backup.DiffBackups(from, to)
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, stremio_backup.ErrBackupNotFound) {
+			SendError(w, r, err)
+			return
+		}
+		td.Backups.Error = err.Error()
+	}
+
+	sendBackupsSection(w, r, td)
+}
+
+func handleBackupRestore(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, ok := getBackupsCookieValue(w, r)
+	if !ok {
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	email := cookie.Email()
+
+	version, err := strconv.ParseInt(r.PathValue("version"), 10, 64)
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid version").Send(w, r)
+		return
+	}
+
+	b, err := stremio_backup.GetBackup(email, version)
+	if err != nil {
+		if errors.Is(err, stremio_backup.ErrBackupNotFound) {
+			shared.ErrorNotFound(r).Send(w, r)
+		} else {
+			SendError(w, r, err)
+		}
+		return
+	}
+
+	if err := stremio_backup.RestoreBackup(cookie.AuthKey(), b); err != nil {
+		LogError(r, "failed to restore backup", err)
+		td.Backups.Error = err.Error()
+	} else {
+		td.Backups.Message = "Restored backup from " + b.CreatedAt.UTC().Format(time.DateTime) + " UTC"
+	}
+
+	if err := loadBackupsTemplateData(td, email); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	sendBackupsSection(w, r, td)
+}
@@ -107,6 +107,10 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 
 	td := getTemplateData(cookie, w, r)
 
+	if err := loadTraktSyncTemplateData(td, cookie); err != nil {
+		LogError(r, "failed to load trakt.tv sync", err)
+	}
+
 	if action := r.Header.Get("x-addon-configure-action"); action != "" {
 		switch action {
 		case "authorize":
@@ -1150,5 +1154,13 @@ func AddStremioSidekickEndpoints(mux *http.ServeMux) {
 	router.HandleFunc("/library/backup", handleLibraryBackup)
 	router.HandleFunc("/library/restore", handleLibraryRestore)
 
+	router.HandleFunc("/backups", handleBackups)
+	router.HandleFunc("/backups/create", handleBackupsCreate)
+	router.HandleFunc("/backups/diff", handleBackupsDiff)
+	router.HandleFunc("/backups/{version}/restore", handleBackupRestore)
+
+	router.HandleFunc("/trakt-sync", handleTraktSync)
+	router.HandleFunc("/trakt-sync/sync", handleTraktSyncNow)
+
 	mux.Handle("/stremio/sidekick/", http.StripPrefix("/stremio/sidekick", commonMiddleware(router)))
 }
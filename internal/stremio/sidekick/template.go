@@ -7,8 +7,12 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/oauth"
+	stremio_backup "github.com/rodezfranco/stremthru/internal/stremio/backup"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
+	stremio_sync "github.com/rodezfranco/stremthru/internal/stremio/sync"
 	stremio_template "github.com/rodezfranco/stremthru/internal/stremio/template"
 	"github.com/rodezfranco/stremthru/stremio"
 )
@@ -50,6 +54,38 @@ type TemplateData struct {
 		}
 	}
 
+	Backups struct {
+		IsLoaded     bool
+		IsScheduled  bool
+		Interval     string
+		KeepCount    int
+		LastBackupAt string
+		LastError    string
+		Items        []stremio_backup.BackupInfo
+		DiffFrom     int64
+		DiffTo       int64
+		Diff         *stremio_backup.BackupDiff
+		Message      string
+		Error        string
+	}
+
+	TraktSync struct {
+		IsEnabled     bool
+		IsLinked      bool
+		AuthorizeURL  string
+		TraktTokenId  string
+		TraktUserName string
+		ConflictRule  string
+		SyncWatched   bool
+		SyncProgress  bool
+		LastSyncedAt  string
+		LastError     string
+		Message       string
+		Error         struct {
+			TraktTokenId string
+		}
+	}
+
 	CanAuthAdmin   bool
 	HasAuthAdmin   bool
 	AuthAdminError string
@@ -109,6 +145,14 @@ var executeTemplate = func() stremio_template.Executor[TemplateData] {
 
 		td.CanAuthAdmin = !IsPublicInstance
 
+		td.TraktSync.IsEnabled = TraktEnabled
+		if td.TraktSync.IsEnabled && td.TraktSync.AuthorizeURL == "" {
+			td.TraktSync.AuthorizeURL = oauth.TraktOAuthConfig.AuthCodeURL(uuid.NewString())
+		}
+		if td.TraktSync.ConflictRule == "" {
+			td.TraktSync.ConflictRule = string(stremio_sync.TraktConflictRuleNewest)
+		}
+
 		td.Version = config.Version
 		if td.Addons == nil {
 			td.Addons = []stremio.Addon{}
@@ -0,0 +1,185 @@
+package stremio_sidekick
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/oauth"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	stremio_sync "github.com/rodezfranco/stremthru/internal/stremio/sync"
+)
+
+var TraktEnabled = config.Integration.Trakt.IsEnabled()
+
+func getTraktToken(tokenId string) (*oauth.OAuthToken, string) {
+	if tokenId == "" {
+		return nil, "Auth Code is required"
+	}
+	otok, err := oauth.GetOAuthTokenById(tokenId)
+	if err != nil {
+		return nil, "failed to retrieve token: " + err.Error()
+	}
+	if otok == nil || otok.Provider != oauth.ProviderTraktTv || otok.AccessToken == "" {
+		return nil, "Invalid or Revoked"
+	}
+	return otok, ""
+}
+
+func setTraktSyncTemplateData(td *TemplateData, link *stremio_sync.TraktLink) {
+	if link == nil {
+		td.TraktSync.IsLinked = false
+		td.TraktSync.SyncWatched = true
+		td.TraktSync.SyncProgress = true
+		return
+	}
+	td.TraktSync.IsLinked = true
+	td.TraktSync.TraktTokenId = link.TraktTokenId
+	td.TraktSync.ConflictRule = string(link.ConflictRule)
+	td.TraktSync.SyncWatched = link.SyncWatched
+	td.TraktSync.SyncProgress = link.SyncProgress
+	if !link.LastSyncedAt.IsZero() {
+		td.TraktSync.LastSyncedAt = link.LastSyncedAt.UTC().Format(time.DateTime) + " UTC"
+	}
+	td.TraktSync.LastError = link.LastError
+	if otok, _ := getTraktToken(link.TraktTokenId); otok != nil {
+		td.TraktSync.TraktUserName = otok.UserName
+	}
+}
+
+func loadTraktSyncTemplateData(td *TemplateData, cookie *CookieValue) error {
+	if !TraktEnabled || !td.IsAuthed {
+		return nil
+	}
+	link, err := stremio_sync.GetTraktLink(cookie.Email())
+	if err != nil {
+		return err
+	}
+	setTraktSyncTemplateData(td, link)
+	return nil
+}
+
+func handleTraktSync(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodPost) && !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	if !TraktEnabled {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if cookie.IsExpired {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+
+	email := cookie.Email()
+	link, err := stremio_sync.GetTraktLink(email)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if link == nil {
+			link = &stremio_sync.TraktLink{StremioEmail: email}
+		}
+		if tokenId := r.FormValue("trakt_token_id"); tokenId != "" || link.TraktTokenId == "" {
+			link.TraktTokenId = tokenId
+		}
+		link.StremioAuthKey = cookie.AuthKey()
+		link.ConflictRule = stremio_sync.TraktConflictRule(r.FormValue("conflict_rule"))
+		link.SyncWatched = r.FormValue("sync_watched") == "on"
+		link.SyncProgress = r.FormValue("sync_progress") == "on"
+
+		if _, errMsg := getTraktToken(link.TraktTokenId); errMsg != "" {
+			setTraktSyncTemplateData(td, link)
+			td.TraktSync.IsLinked = false
+			td.TraktSync.Error.TraktTokenId = errMsg
+			break
+		}
+
+		if err := stremio_sync.SaveTraktLink(link); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		setTraktSyncTemplateData(td, link)
+		td.TraktSync.Message = "Saved"
+	case http.MethodDelete:
+		if link != nil {
+			if err := stremio_sync.DeleteTraktLink(email); err != nil {
+				SendError(w, r, err)
+				return
+			}
+		}
+		setTraktSyncTemplateData(td, nil)
+		td.TraktSync.Message = "Unlinked"
+	default:
+		setTraktSyncTemplateData(td, link)
+	}
+
+	buf, err := executeTemplate(td, "sidekick_trakt_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func handleTraktSyncNow(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	if !TraktEnabled {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if cookie.IsExpired {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	link, err := stremio_sync.GetTraktLink(cookie.Email())
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if link == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	link.StremioAuthKey = cookie.AuthKey()
+	td := getTemplateData(cookie, w, r)
+	if err := stremio_sync.SyncTrakt(link); err != nil {
+		LogError(r, "failed to sync trakt.tv", err)
+	} else {
+		td.TraktSync.Message = "Synced"
+	}
+	setTraktSyncTemplateData(td, link)
+
+	buf, err := executeTemplate(td, "sidekick_trakt_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/rodezfranco/stremthru/internal/shared"
 	store_video "github.com/rodezfranco/stremthru/internal/store/video"
-	"github.com/rodezfranco/stremthru/store"
 )
 
 func handleAction(w http.ResponseWriter, r *http.Request) {
@@ -47,14 +46,7 @@ func handleAction(w http.ResponseWriter, r *http.Request) {
 	switch strings.TrimPrefix(actionId, storeActionIdPrefix) {
 	case "clear_cache":
 		catalogCache.Remove(getCatalogCacheKey(idPrefix, ctx.StoreAuthToken))
-		switch ctx.Store.GetName() {
-		case store.StoreNameAlldebrid:
-			adLinksCache.Remove(getADLinksCacheKey(idPrefix, ctx.StoreAuthToken))
-		case store.StoreNamePremiumize:
-			pmItemsCache.Remove(getPMItemsCacheKey(idPrefix, ctx.StoreAuthToken))
-		case store.StoreNameRealDebrid:
-			rdDownloadsCache.Remove(getRDDownloadsCacheKey(idPrefix, ctx.StoreAuthToken))
-		}
+		webdlsMetaCache.Remove(getWebDLsMetaCacheKey(idPrefix, ctx.StoreAuthToken))
 	}
 
 	store_video.Redirect("200", w, r)
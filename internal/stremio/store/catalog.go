@@ -8,11 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rodezfranco/stremthru/internal/buddy"
 	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
-	stremio_store_usenet "github.com/rodezfranco/stremthru/internal/stremio/store/usenet"
-	stremio_store_webdl "github.com/rodezfranco/stremthru/internal/stremio/store/webdl"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
 	"github.com/rodezfranco/stremthru/internal/torrent_stream"
 	"github.com/rodezfranco/stremthru/internal/util"
@@ -40,23 +40,30 @@ const fetch_list_limit = 500
 func getUsenetCatalogItems(s store.Store, storeToken string, clientIp string, idPrefix string) []CachedCatalogItem {
 	items := []CachedCatalogItem{}
 
+	newsStore, ok := s.(store.NewsStore)
+	if !ok {
+		return items
+	}
+
 	cacheKey := getCatalogCacheKey(idPrefix, storeToken)
 	if !catalogCache.Get(cacheKey, &items) {
 		offset := 0
 		hasMore := true
 		for hasMore && offset < max_fetch_list_items {
-			params := &stremio_store_usenet.ListNewsParams{
+			params := &store.ListNewsParams{
 				Limit:    fetch_list_limit,
 				Offset:   offset,
 				ClientIP: clientIp,
 			}
 			params.APIKey = storeToken
-			res, err := stremio_store_usenet.ListNews(params, s.GetName())
+			res, err := newsStore.ListNews(params)
 			if err != nil {
 				log.Error("failed to list news", "error", err, "offset", offset)
 				break
 			}
 
+			go buddy.BulkTrackNews(s, res.Items)
+
 			for _, item := range res.Items {
 				if item.Status == store.MagnetStatusDownloaded {
 					cItem := CachedCatalogItem{stremio.MetaPreview{
@@ -65,7 +72,7 @@ func getUsenetCatalogItems(s store.Store, storeToken string, clientIp string, id
 						Name:        item.Name,
 						PosterShape: stremio.MetaPosterShapePoster,
 					}, item.Hash}
-					cItem.Description = getMetaPreviewDescriptionForUsenet(cItem.Hash, item.Name, item.GetLargestFileName())
+					cItem.Description = getMetaPreviewDescriptionForUsenet(cItem.Hash, item.Name, getLargestNewsFileName(item.Files))
 					items = append(items, cItem)
 				}
 			}
@@ -82,18 +89,23 @@ func getUsenetCatalogItems(s store.Store, storeToken string, clientIp string, id
 func getWebDLCatalogItems(s store.Store, storeToken string, clientIp string, idPrefix string) []CachedCatalogItem {
 	items := []CachedCatalogItem{}
 
+	webdlStore, ok := s.(store.WebDLStore)
+	if !ok {
+		return items
+	}
+
 	cacheKey := getCatalogCacheKey(idPrefix, storeToken)
 	if !catalogCache.Get(cacheKey, &items) {
 		offset := 0
 		hasMore := true
 		for hasMore && offset < max_fetch_list_items {
-			params := &stremio_store_webdl.ListWebDLsParams{
+			params := &store.ListWebDLsParams{
 				Limit:    fetch_list_limit,
 				Offset:   offset,
 				ClientIP: clientIp,
 			}
 			params.APIKey = storeToken
-			res, err := stremio_store_webdl.ListWebDLs(params, s.GetName())
+			res, err := webdlStore.ListWebDLs(params)
 			if err != nil {
 				log.Error("failed to list webdls", "error", err, "offset", offset)
 				break
@@ -172,10 +184,14 @@ func getCatalogItems(s store.Store, storeToken string, clientIp string, idPrefix
 			hasMore = len(res.Items) == fetch_list_limit && offset < res.TotalItems
 
 			if hasMore && offset >= max_fetch_list_items {
-				worker_queue.StoreCrawlerQueue.Queue(worker_queue.StoreCrawlerQueueItem{
-					StoreCode:  string(s.GetName().Code()),
-					StoreToken: storeToken,
-				})
+				// store token is not persisted in the queue, the rest of the
+				// list is crawled only for the configured user's token.
+				if storeUser := config.StoreAuthToken.GetUser(string(s.GetName()), storeToken); storeUser != "" {
+					worker_queue.StoreCrawlerQueue.Queue(worker_queue.StoreCrawlerQueueItem{
+						StoreCode: string(s.GetName().Code()),
+						StoreUser: storeUser,
+					})
+				}
 				break
 			}
 
@@ -324,7 +340,8 @@ func handleCatalog(w http.ResponseWriter, r *http.Request) {
 		hashes[i] = item.Hash
 	}
 
-	includeWebDLsMetaPreview := ud.EnableWebDL && (idr.storeCode == store.StoreCodeRealDebrid || idr.storeCode == store.StoreCodePremiumize || idr.storeCode == store.StoreCodeAllDebrid)
+	// torbox web downloads have their own catalog
+	includeWebDLsMetaPreview := ud.EnableWebDL && !idr.isUsenet && !idr.isWebDL && idr.storeName != store.StoreNameTorBox && isWebDLStore(idr.storeName)
 
 	count := len(hashes)
 	if includeWebDLsMetaPreview {
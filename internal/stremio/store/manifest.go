@@ -77,16 +77,15 @@ func GetManifest(r *http.Request, ud *UserData) *stremio.Manifest {
 						code := "st-" + string(storeCode)
 						idPrefixes = append(idPrefixes, getIdPrefix(code))
 						catalogs = append(catalogs, getManifestCatalog(code, ud.HideCatalog))
-						if storeName == store.StoreNameTorBox {
+						if isNewsStore(storeName) {
 							usenetCode := code + "-usenet"
 							idPrefixes = append(idPrefixes, getIdPrefix(usenetCode))
 							catalogs = append(catalogs, getManifestCatalog(usenetCode, ud.HideCatalog))
-
-							if ud.EnableWebDL {
-								webdlCode := code + "-webdl"
-								idPrefixes = append(idPrefixes, getIdPrefix(webdlCode))
-								catalogs = append(catalogs, getManifestCatalog(webdlCode, ud.HideCatalog))
-							}
+						}
+						if storeName == store.StoreNameTorBox && ud.EnableWebDL {
+							webdlCode := code + "-webdl"
+							idPrefixes = append(idPrefixes, getIdPrefix(webdlCode))
+							catalogs = append(catalogs, getManifestCatalog(webdlCode, ud.HideCatalog))
 						}
 					}
 				}
@@ -107,16 +106,15 @@ func GetManifest(r *http.Request, ud *UserData) *stremio.Manifest {
 
 			idPrefixes = append(idPrefixes, getIdPrefix(storeCode))
 			catalogs = append(catalogs, getManifestCatalog(storeCode, ud.HideCatalog))
-			if storeName == store.StoreNameTorBox {
+			if isNewsStore(storeName) {
 				usenetCode := storeCode + "-usenet"
 				idPrefixes = append(idPrefixes, getIdPrefix(usenetCode))
 				catalogs = append(catalogs, getManifestCatalog(usenetCode, ud.HideCatalog))
-
-				if ud.EnableWebDL {
-					webdlCode := storeCode + "-webdl"
-					idPrefixes = append(idPrefixes, getIdPrefix(webdlCode))
-					catalogs = append(catalogs, getManifestCatalog(webdlCode, ud.HideCatalog))
-				}
+			}
+			if storeName == store.StoreNameTorBox && ud.EnableWebDL {
+				webdlCode := storeCode + "-webdl"
+				idPrefixes = append(idPrefixes, getIdPrefix(webdlCode))
+				catalogs = append(catalogs, getManifestCatalog(webdlCode, ud.HideCatalog))
 			}
 		}
 	} else {
@@ -159,6 +157,14 @@ func GetManifest(r *http.Request, ud *UserData) *stremio.Manifest {
 		},
 	}
 
+	if len(subtitleProviders) > 0 {
+		manifest.Resources = append(manifest.Resources, stremio.Resource{
+			Name:       stremio.ResourceNameSubtitles,
+			Types:      streamResource.Types,
+			IDPrefixes: streamResource.IDPrefixes,
+		})
+	}
+
 	return manifest
 }
 
@@ -12,8 +12,6 @@ import (
 	"github.com/rodezfranco/stremthru/internal/cache"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
-	stremio_store_usenet "github.com/rodezfranco/stremthru/internal/stremio/store/usenet"
-	stremio_store_webdl "github.com/rodezfranco/stremthru/internal/stremio/store/webdl"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
 	"github.com/rodezfranco/stremthru/internal/torrent_stream"
 	"github.com/rodezfranco/stremthru/internal/util"
@@ -154,6 +152,20 @@ func getMetaPreviewDescriptionForTorrent(hash, name string) string {
 	return getMetaPreviewDescription(description, r, false)
 }
 
+func getLargestNewsFileName(files []store.NewsFile) string {
+	name, size := "", int64(0)
+	for i, file := range files {
+		if file.Size > size {
+			name = file.Name
+			size = file.Size
+		}
+		if i > 99 {
+			break
+		}
+	}
+	return name
+}
+
 func getMetaPreviewDescriptionForUsenet(hash, name string, largestFilename string) string {
 	description := "[ 🌐 " + hash + " ]"
 
@@ -244,15 +256,17 @@ type contentInfo struct {
 
 func getStoreContentInfo(s store.Store, storeToken string, id string, clientIp string, idr *ParsedId) (*contentInfo, error) {
 	if idr.isUsenet {
-		if s.GetName() != store.StoreNameTorBox {
+		newsStore, ok := s.(store.NewsStore)
+		if !ok {
 			return nil, nil
 		}
 
-		params := &stremio_store_usenet.GetNewsParams{
-			Id: id,
+		params := &store.GetNewsParams{
+			Id:       id,
+			ClientIP: clientIp,
 		}
 		params.APIKey = storeToken
-		news, err := stremio_store_usenet.GetNews(params, s.GetName())
+		news, err := newsStore.GetNews(params)
 		if err != nil {
 			return nil, err
 		}
@@ -274,19 +288,21 @@ func getStoreContentInfo(s store.Store, storeToken string, id string, clientIp s
 			})
 
 		}
-		return &contentInfo{cInfo, news.GetLargestFileName()}, nil
+		return &contentInfo{cInfo, getLargestNewsFileName(news.Files)}, nil
 	}
 
 	if idr.isWebDL {
-		if s.GetName() != store.StoreNameTorBox {
+		webdlStore, ok := s.(store.WebDLStore)
+		if !ok {
 			return nil, nil
 		}
 
-		params := &stremio_store_webdl.GetWebDLParams{
-			Id: id,
+		params := &store.GetWebDLParams{
+			Id:       id,
+			ClientIP: clientIp,
 		}
 		params.APIKey = storeToken
-		webdl, err := stremio_store_webdl.GetWebDL(params, s.GetName())
+		webdl, err := webdlStore.GetWebDL(params)
 		if err != nil {
 			return nil, err
 		}
@@ -410,17 +426,10 @@ func handleMeta(w http.ResponseWriter, r *http.Request) {
 	if id == getWebDLsMetaId(idStoreCode) {
 		res := stremio.MetaHandlerResponse{}
 
-		switch idr.storeCode {
-		case store.StoreCodeAllDebrid:
-			res.Meta = getADWebDLsMeta(r, ctx, idr, eud)
-		case store.StoreCodeRealDebrid:
-			res.Meta = getRDWebDLsMeta(r, ctx, idr)
-		case store.StoreCodePremiumize:
-			res.Meta, err = getPMWebDLsMeta(r, ctx, idr, eud)
-			if err != nil {
-				SendError(w, r, err)
-				return
-			}
+		res.Meta, err = getWebDLsMeta(r, ctx, idr, eud)
+		if err != nil {
+			SendError(w, r, err)
+			return
 		}
 
 		SendResponse(w, r, 200, res)
@@ -3,13 +3,9 @@ package stremio_store
 import (
 	"net/http"
 	"strings"
-	"time"
 
-	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	store_video "github.com/rodezfranco/stremthru/internal/store/video"
-	stremio_store_usenet "github.com/rodezfranco/stremthru/internal/stremio/store/usenet"
-	stremio_store_webdl "github.com/rodezfranco/stremthru/internal/stremio/store/webdl"
 )
 
 func handleStrem(w http.ResponseWriter, r *http.Request) {
@@ -58,69 +54,27 @@ func handleStrem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if idr.isUsenet {
-		storeName := ctx.Store.GetName()
-		rParams := &stremio_store_usenet.GenerateLinkParams{
-			Link:     link,
-			CLientIP: ctx.ClientIP,
-		}
-		rParams.APIKey = ctx.StoreAuthToken
-		var lerr error
-		data, err := stremio_store_usenet.GenerateLink(rParams, storeName)
-		if err == nil {
-			if config.StoreContentProxy.IsEnabled(string(storeName)) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, string(storeName)) {
-				if ctx.IsProxyAuthorized {
-					tunnelType := config.StoreTunnel.GetTypeForStream(string(ctx.Store.GetName()))
-					if proxyLink, err := shared.CreateProxyLink(r, data.Link, nil, tunnelType, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, ""); err == nil {
-						data.Link = proxyLink
-					} else {
-						lerr = err
-					}
-				}
-			}
-		} else {
-			lerr = err
-		}
-		if lerr != nil {
-			LogError(r, "failed to generate stremthru link", lerr)
+		stLink, err := shared.GenerateStremThruNewsLink(r, ctx, link)
+		if err != nil {
+			LogError(r, "failed to generate stremthru link", err)
 			store_video.Redirect("500", w, r)
 			return
 		}
 
-		http.Redirect(w, r, data.Link, http.StatusFound)
+		http.Redirect(w, r, stLink.Link, http.StatusFound)
 	} else if idr.isWebDL || videoId == WEBDL_META_ID_INDICATOR {
-		storeName := ctx.Store.GetName()
-		rParams := &stremio_store_webdl.GenerateLinkParams{
-			Link:     link,
-			CLientIP: ctx.ClientIP,
-		}
-		rParams.APIKey = ctx.StoreAuthToken
-		var lerr error
-		data, err := stremio_store_webdl.GenerateLink(rParams, storeName)
-		if err == nil {
-			if data.Link == "" {
-				store_video.Redirect(store_video.StoreVideoNameDownloading, w, r)
-				return
-			}
-			if config.StoreContentProxy.IsEnabled(string(storeName)) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, string(storeName)) {
-				if ctx.IsProxyAuthorized {
-					tunnelType := config.StoreTunnel.GetTypeForStream(string(ctx.Store.GetName()))
-					if proxyLink, err := shared.CreateProxyLink(r, data.Link, nil, tunnelType, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, ""); err == nil {
-						data.Link = proxyLink
-					} else {
-						lerr = err
-					}
-				}
-			}
-		} else {
-			lerr = err
-		}
-		if lerr != nil {
-			LogError(r, "failed to generate stremthru link", lerr)
+		stLink, err := shared.GenerateStremThruWebDLLink(r, ctx, link)
+		if err != nil {
+			LogError(r, "failed to generate stremthru link", err)
 			store_video.Redirect("500", w, r)
 			return
 		}
+		if stLink.Link == "" {
+			store_video.Redirect(store_video.StoreVideoNameDownloading, w, r)
+			return
+		}
 
-		http.Redirect(w, r, data.Link, http.StatusFound)
+		http.Redirect(w, r, stLink.Link, http.StatusFound)
 	} else {
 		stLink, err := shared.GenerateStremThruLink(r, ctx, url)
 		if err != nil {
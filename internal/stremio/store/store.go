@@ -40,8 +40,12 @@ func AddStremioStoreEndpoints(mux *http.ServeMux) {
 
 	router.HandleFunc("/{userData}/stream/{contentType}/{idJson}", withCors(handleStream))
 
+	router.HandleFunc("/{userData}/subtitles/{contentType}/{idJson}", withCors(handleSubtitles))
+	router.HandleFunc("/{userData}/subtitles/{contentType}/{id}/{extraJson}", withCors(handleSubtitles))
+
 	router.HandleFunc("/{userData}/_/action/{actionId}", withCors(handleAction))
 	router.HandleFunc("/{userData}/_/strem/{videoId}", withCors(handleStrem))
+	router.HandleFunc("/{userData}/_/subtitle/{provider}/{subtitleId}/{fileName}", withCors(handleSubtitle))
 
 	mux.Handle("/stremio/store/", http.StripPrefix("/stremio/store", commonMiddleware(router)))
 }
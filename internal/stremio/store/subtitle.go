@@ -0,0 +1,188 @@
+package stremio_store
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/server"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+func getSubtitleQuery(r *http.Request) *SubtitleQuery {
+	query := &SubtitleQuery{}
+	if extraParams := GetPathValue(r, "extra"); extraParams != "" {
+		if q, err := url.ParseQuery(extraParams); err == nil {
+			query.VideoHash = q.Get("videoHash")
+			query.FileName = q.Get("filename")
+			if size, err := strconv.ParseInt(q.Get("videoSize"), 10, 64); err == nil {
+				query.VideoSize = size
+			}
+		}
+	}
+	return query
+}
+
+// fillSubtitleQueryFromStore fills the missing file name and video hash using
+// the file from store.
+func fillSubtitleQueryFromStore(r *http.Request, ud *UserData, id string, query *SubtitleQuery) error {
+	idr, err := parseId(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := ud.GetRequestContext(r, idr)
+	if err != nil {
+		return err
+	}
+	if ctx.Store == nil {
+		return errors.New("store not found")
+	}
+
+	videoId, link, name, err := getVideoIdAndData(id, idr)
+	if err != nil {
+		return err
+	}
+	if link == "" && name == "" {
+		return nil
+	}
+
+	cInfo, err := getStoreContentInfo(ctx.Store, ctx.StoreAuthToken, videoId, ctx.ClientIP, idr)
+	if err != nil {
+		return err
+	}
+	if cInfo == nil {
+		return nil
+	}
+
+	for i := range cInfo.Files {
+		f := &cInfo.Files[i]
+		if (link != "" && f.Link == link) || (name != "" && f.Name == name) {
+			if query.FileName == "" {
+				query.FileName = f.Name
+			}
+			if query.VideoHash == "" {
+				query.VideoHash = f.VideoHash
+			}
+			if query.VideoSize == 0 {
+				query.VideoSize = f.Size
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func handleSubtitles(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ud, err := getUserData(r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	log := server.GetReqCtx(r).Log
+
+	res := stremio.SubtitlesHandlerResponse{
+		Subtitles: []stremio.Subtitle{},
+	}
+
+	id := getId(r)
+	query := getSubtitleQuery(r)
+
+	if isStoreId(id) && (query.FileName == "" || query.VideoHash == "") {
+		if err := fillSubtitleQueryFromStore(r, ud, id, query); err != nil {
+			LogError(r, "failed to get file from store", err)
+		}
+	}
+
+	if query.FileName == "" && query.VideoHash == "" {
+		SendResponse(w, r, 200, res)
+		return
+	}
+
+	eud, err := ud.GetEncoded()
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	subtitleBaseUrl := ExtractRequestBaseURL(r).JoinPath("/stremio/store/" + eud + "/_/subtitle/")
+	for _, provider := range subtitleProviders {
+		results, err := provider.Search(query)
+		if err != nil {
+			log.Error("failed to search subtitles", "error", err, "provider", provider.GetName())
+			continue
+		}
+		for i := range results {
+			result := &results[i]
+			res.Subtitles = append(res.Subtitles, stremio.Subtitle{
+				Id:   provider.GetName() + ":" + result.Id,
+				Url:  subtitleBaseUrl.JoinPath(provider.GetName(), base64.RawURLEncoding.EncodeToString([]byte(result.Id)), filepath.Base(result.Id)).String(),
+				Lang: result.Lang,
+			})
+		}
+	}
+
+	SendResponse(w, r, 200, res)
+}
+
+func handleSubtitle(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodHead) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	if _, err := getUserData(r); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	provider := getSubtitleProvider(r.PathValue("provider"))
+	if provider == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	subtitleId, err := base64.RawURLEncoding.DecodeString(r.PathValue("subtitleId"))
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid subtitle id").Send(w, r)
+		return
+	}
+
+	file, err := provider.Open(string(subtitleId))
+	if err != nil {
+		if errors.Is(err, ErrSubtitleNotFound) {
+			shared.ErrorNotFound(r).Send(w, r)
+		} else {
+			SendError(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(string(subtitleId))) {
+	case ".vtt":
+		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	case ".srt":
+		w.Header().Set("Content-Type", "application/x-subrip; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		if _, err := io.Copy(w, file); err != nil {
+			LogError(r, "failed to send subtitle", err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package stremio_store
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+)
+
+var ErrSubtitleNotFound = errors.New("subtitle not found")
+
+type SubtitleQuery struct {
+	// OpenSubtitles moviehash of the video file
+	VideoHash string
+	VideoSize int64
+	FileName  string
+}
+
+type SubtitleResult struct {
+	Id   string
+	Lang string
+}
+
+type SubtitleProvider interface {
+	GetName() string
+	Search(query *SubtitleQuery) ([]SubtitleResult, error)
+	Open(id string) (io.ReadCloser, error)
+}
+
+var subtitleExtensions = []string{".srt", ".vtt", ".ass", ".ssa", ".sub"}
+
+func isSubtitleFile(name string) bool {
+	return slices.Contains(subtitleExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// getSubtitleLang extracts the language from file name like `Movie.en.srt`,
+// `und` is used when missing.
+func getSubtitleLang(name string) string {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	if ext := filepath.Ext(stem); len(ext) == 3 || len(ext) == 4 {
+		return strings.ToLower(ext[1:])
+	}
+	if len(stem) == 2 || len(stem) == 3 {
+		return strings.ToLower(stem)
+	}
+	return "und"
+}
+
+// LocalSubtitleProvider matches the subtitles stored in a directory:
+//   - `<dir>/<video_hash>/<lang>.srt` by OpenSubtitles moviehash
+//   - `<dir>/<video_file_name_without_ext>.<lang>.srt` by file name
+type LocalSubtitleProvider struct {
+	Dir string
+}
+
+func (p *LocalSubtitleProvider) GetName() string {
+	return "local"
+}
+
+func (p *LocalSubtitleProvider) Search(query *SubtitleQuery) ([]SubtitleResult, error) {
+	results := []SubtitleResult{}
+
+	if query.VideoHash != "" {
+		hash := strings.ToLower(query.VideoHash)
+		if filepath.IsLocal(hash) {
+			entries, err := os.ReadDir(filepath.Join(p.Dir, hash))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if entry.IsDir() || !isSubtitleFile(entry.Name()) {
+					continue
+				}
+				results = append(results, SubtitleResult{
+					Id:   hash + "/" + entry.Name(),
+					Lang: getSubtitleLang(entry.Name()),
+				})
+			}
+		}
+	}
+
+	if query.FileName != "" {
+		stem := strings.TrimSuffix(filepath.Base(query.FileName), filepath.Ext(query.FileName))
+		entries, err := os.ReadDir(p.Dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !isSubtitleFile(name) {
+				continue
+			}
+			lang := ""
+			if nameStem := strings.TrimSuffix(name, filepath.Ext(name)); nameStem == stem {
+				lang = "und"
+			} else if strings.HasPrefix(nameStem, stem+".") {
+				lang = getSubtitleLang(name)
+			} else {
+				continue
+			}
+			results = append(results, SubtitleResult{
+				Id:   name,
+				Lang: lang,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (p *LocalSubtitleProvider) Open(id string) (io.ReadCloser, error) {
+	if !filepath.IsLocal(id) || !isSubtitleFile(id) {
+		return nil, ErrSubtitleNotFound
+	}
+	file, err := os.Open(filepath.Join(p.Dir, id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSubtitleNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+var subtitleProviders = func() []SubtitleProvider {
+	providers := []SubtitleProvider{}
+	if dir := config.Stremio.Store.SubtitleDir; dir != "" {
+		providers = append(providers, &LocalSubtitleProvider{Dir: dir})
+	}
+	return providers
+}()
+
+func getSubtitleProvider(name string) SubtitleProvider {
+	for _, p := range subtitleProviders {
+		if p.GetName() == name {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package stremio_store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalSubtitleProvider(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"8e245d9679d31e12/en.srt",
+		"8e245d9679d31e12/hin.vtt",
+		"8e245d9679d31e12/notes.txt",
+		"Movie.2020.1080p.srt",
+		"Movie.2020.1080p.fr.srt",
+		"Movie.2020.720p.en.srt",
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	p := &LocalSubtitleProvider{Dir: dir}
+
+	for _, tc := range []struct {
+		name   string
+		query  SubtitleQuery
+		result []SubtitleResult
+	}{
+		{"by hash", SubtitleQuery{VideoHash: "8E245D9679D31E12"}, []SubtitleResult{
+			{Id: "8e245d9679d31e12/en.srt", Lang: "en"},
+			{Id: "8e245d9679d31e12/hin.vtt", Lang: "hin"},
+		}},
+		{"by file name", SubtitleQuery{FileName: "Movie.2020.1080p.mkv"}, []SubtitleResult{
+			{Id: "Movie.2020.1080p.fr.srt", Lang: "fr"},
+			{Id: "Movie.2020.1080p.srt", Lang: "und"},
+		}},
+		{"no match", SubtitleQuery{VideoHash: "0000000000000000", FileName: "Other.mkv"}, []SubtitleResult{}},
+		{"path traversal", SubtitleQuery{VideoHash: "../etc"}, []SubtitleResult{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Search(&tc.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, result)
+		})
+	}
+
+	file, err := p.Open("8e245d9679d31e12/en.srt")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(file)
+	file.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "8e245d9679d31e12/en.srt", string(content))
+
+	_, err = p.Open("../secret.srt")
+	assert.ErrorIs(t, err, ErrSubtitleNotFound)
+	_, err = p.Open("8e245d9679d31e12/notes.txt")
+	assert.ErrorIs(t, err, ErrSubtitleNotFound)
+}
@@ -49,14 +49,13 @@ func (ud *UserData) getIdPrefixes() []string {
 						storeName := store.StoreName(name)
 						storeCode := "st-" + string(storeName.Code())
 						ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(storeCode))
-						if storeName == store.StoreNameTorBox {
+						if isNewsStore(storeName) {
 							code := storeCode + "-usenet"
 							ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
-
-							if ud.EnableWebDL {
-								code := storeCode + "-webdl"
-								ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
-							}
+						}
+						if storeName == store.StoreNameTorBox && ud.EnableWebDL {
+							code := storeCode + "-webdl"
+							ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
 						}
 					}
 				}
@@ -65,14 +64,13 @@ func (ud *UserData) getIdPrefixes() []string {
 			storeName := store.StoreName(ud.StoreName)
 			storeCode := string(storeName.Code())
 			ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(storeCode))
-			if storeName == store.StoreNameTorBox {
+			if isNewsStore(storeName) {
 				code := storeCode + "-usenet"
 				ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
-
-				if ud.EnableWebDL {
-					code := storeCode + "-webdl"
-					ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
-				}
+			}
+			if storeName == store.StoreNameTorBox && ud.EnableWebDL {
+				code := storeCode + "-webdl"
+				ud.idPrefixes = append(ud.idPrefixes, getIdPrefix(code))
 			}
 		}
 	}
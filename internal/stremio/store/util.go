@@ -8,6 +8,7 @@ import (
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
+	"github.com/rodezfranco/stremthru/store"
 )
 
 var IsMethod = shared.IsMethod
@@ -49,3 +50,13 @@ func getContentType(r *http.Request) (string, *core.APIError) {
 	}
 	return contentType, nil
 }
+
+func isNewsStore(storeName store.StoreName) bool {
+	_, ok := shared.GetStore(string(storeName)).(store.NewsStore)
+	return ok
+}
+
+func isWebDLStore(storeName store.StoreName) bool {
+	_, ok := shared.GetStore(string(storeName)).(store.WebDLStore)
+	return ok
+}
@@ -0,0 +1,111 @@
+package stremio_store
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/store"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+var webdlsMetaCache = cache.NewCache[[]stremio.MetaVideo](&cache.CacheConfig{
+	Lifetime: 5 * time.Minute,
+	Name:     "stremio:store:webdls",
+})
+
+func getWebDLsMetaCacheKey(idPrefix, storeToken string) string {
+	return idPrefix + storeToken
+}
+
+func getWebDLsMeta(r *http.Request, ctx *context.StoreContext, idr *ParsedId, eud string) (stremio.Meta, error) {
+	released := time.Now().UTC()
+
+	meta := stremio.Meta{
+		Id:          getWebDLsMetaId(idr.getStoreCode()),
+		Type:        ContentTypeOther,
+		Name:        "Web Downloads",
+		Description: "Web Downloads from " + string(idr.storeName),
+		Released:    &released,
+		Videos:      []stremio.MetaVideo{},
+	}
+
+	webdlStore, ok := ctx.Store.(store.WebDLStore)
+	if !ok {
+		return meta, store.ErrorWebDLNotSupported(ctx.Store.GetName())
+	}
+
+	cacheKey := getWebDLsMetaCacheKey(getIdPrefix(idr.getStoreCode()), ctx.StoreAuthToken)
+	if !webdlsMetaCache.Get(cacheKey, &meta.Videos) {
+		storeName := string(ctx.Store.GetName())
+		isProxied := config.StoreContentProxy.IsEnabled(storeName) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, storeName) && ctx.IsProxyAuthorized
+		idPrefix := getWebDLsMetaIdPrefix(idr.getStoreCode())
+		streamBaseUrl := ExtractRequestBaseURL(r).JoinPath("/stremio/store/" + eud + "/_/strem/")
+
+		offset := 0
+		hasMore := true
+		for hasMore && offset < max_fetch_list_items {
+			params := &store.ListWebDLsParams{
+				Limit:    fetch_list_limit,
+				Offset:   offset,
+				ClientIP: ctx.ClientIP,
+			}
+			params.APIKey = ctx.StoreAuthToken
+			res, err := webdlStore.ListWebDLs(params)
+			if err != nil {
+				log.Error("failed to list webdls", "error", err, "store", idr.storeCode)
+				return meta, err
+			}
+
+			for i := range res.Items {
+				item := &res.Items[i]
+				for j := range item.Files {
+					file := &item.Files[j]
+					if file.Link == "" || !core.HasVideoExtension(file.Name) {
+						continue
+					}
+
+					videoId := idPrefix + item.Id
+					if len(item.Files) > 1 {
+						videoId += ":" + strconv.Itoa(file.Idx)
+					}
+					videoTitle := getMetaPreviewDescriptionForWebDL("", item.Name, true) + "\n📄 " + file.Name
+					if isProxied {
+						videoTitle = "✨ " + videoTitle
+					}
+					meta.Videos = append(meta.Videos, stremio.MetaVideo{
+						Id:       videoId,
+						Title:    videoTitle,
+						Released: item.AddedAt,
+						Streams: []stremio.Stream{
+							{
+								URL: streamBaseUrl.JoinPath(url.PathEscape(idPrefix + file.Link)).String(),
+								BehaviorHints: &stremio.StreamBehaviorHints{
+									VideoSize: file.Size,
+									Filename:  file.Name,
+								},
+							},
+						},
+						Episode: -1,
+						Season:  -1,
+					})
+				}
+			}
+
+			offset += fetch_list_limit
+			// items can be filtered out by the store, so rely on the total only
+			hasMore = offset < res.TotalItems
+			if hasMore {
+				time.Sleep(1 * time.Second)
+			}
+		}
+
+		webdlsMetaCache.Add(cacheKey, meta.Videos)
+	}
+	return meta, nil
+}
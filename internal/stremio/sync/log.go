@@ -0,0 +1,5 @@
+package stremio_sync
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("stremio/sync")
@@ -0,0 +1,369 @@
+package stremio_sync
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/kv"
+	"github.com/rodezfranco/stremthru/internal/oauth"
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/rodezfranco/stremthru/internal/trakt"
+)
+
+type TraktConflictRule string
+
+const (
+	TraktConflictRuleNewest  TraktConflictRule = "newest"
+	TraktConflictRuleStremio TraktConflictRule = "stremio"
+	TraktConflictRuleTrakt   TraktConflictRule = "trakt"
+)
+
+func (r TraktConflictRule) IsValid() bool {
+	switch r {
+	case TraktConflictRuleNewest, TraktConflictRuleStremio, TraktConflictRuleTrakt:
+		return true
+	default:
+		return false
+	}
+}
+
+type TraktLink struct {
+	StremioAuthKey string            `json:"stremio_auth_key"`
+	StremioEmail   string            `json:"stremio_email"`
+	TraktTokenId   string            `json:"trakt_token_id"`
+	ConflictRule   TraktConflictRule `json:"conflict_rule"`
+	SyncWatched    bool              `json:"sync_watched"`
+	SyncProgress   bool              `json:"sync_progress"`
+	LastSyncedAt   time.Time         `json:"last_synced_at"`
+	LastError      string            `json:"last_error,omitempty"`
+}
+
+var traktLinks = kv.NewKVStore[TraktLink](&kv.KVStoreConfig{
+	Type: "stremio:sync:trakt",
+})
+
+func GetTraktLink(email string) (*TraktLink, error) {
+	link := &TraktLink{}
+	if err := traktLinks.GetValue(email, link); err != nil {
+		return nil, err
+	}
+	if link.TraktTokenId == "" {
+		return nil, nil
+	}
+	return link, nil
+}
+
+func SaveTraktLink(link *TraktLink) error {
+	if link.StremioEmail == "" {
+		return errors.New("missing stremio email")
+	}
+	if !link.ConflictRule.IsValid() {
+		link.ConflictRule = TraktConflictRuleNewest
+	}
+	return traktLinks.Set(link.StremioEmail, *link)
+}
+
+func DeleteTraktLink(email string) error {
+	return traktLinks.Del(email)
+}
+
+const (
+	// progress outside this range is either not started or almost finished,
+	// those are not worth syncing.
+	minSyncProgress = 1.0
+	maxSyncProgress = 90.0
+	// progress within this difference is considered same.
+	syncProgressTolerance = 1.0
+)
+
+var stremioClient = stremio_api.NewClient(&stremio_api.ClientConfig{})
+
+// shouldApplyTrakt decides if the state from Trakt should win over the one
+// from Stremio. A side counts as changed if it was updated after the last
+// sync, the conflict rule is only used when both sides changed.
+func shouldApplyTrakt(rule TraktConflictRule, lastSyncedAt, stremioAt, traktAt time.Time) bool {
+	stremioChanged := stremioAt.After(lastSyncedAt)
+	traktChanged := traktAt.After(lastSyncedAt)
+	if stremioChanged && traktChanged {
+		switch rule {
+		case TraktConflictRuleStremio:
+			return false
+		case TraktConflictRuleTrakt:
+			return true
+		default:
+			return traktAt.After(stremioAt)
+		}
+	}
+	return traktChanged
+}
+
+// getStremioVideoKey returns `<imdb_id>` for movie and
+// `<imdb_id>:<season>:<episode>` for series episode, from the video currently
+// tracked by the library item.
+func getStremioVideoKey(item *stremio_api.LibraryItem) string {
+	if !strings.HasPrefix(item.Id, "tt") {
+		return ""
+	}
+	switch item.Type {
+	case "movie":
+		return item.Id
+	case "series":
+		if season, episode, ok := parseEpisodeVideoId(item.Id, item.State.VideoId); ok {
+			return getEpisodeKey(item.Id, season, episode)
+		}
+	}
+	return ""
+}
+
+func parseEpisodeVideoId(id, videoId string) (season int, episode int, ok bool) {
+	parts := strings.Split(videoId, ":")
+	if len(parts) != 3 || parts[0] != id {
+		return 0, 0, false
+	}
+	season, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	episode, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return season, episode, true
+}
+
+func getEpisodeKey(id string, season, episode int) string {
+	return id + ":" + strconv.Itoa(season) + ":" + strconv.Itoa(episode)
+}
+
+func getTraktPlaybackKey(item *trakt.SyncPlaybackItem) string {
+	if item.Movie != nil {
+		return item.Movie.Ids.IMDB
+	}
+	if item.Show != nil && item.Episode != nil && item.Show.Ids.IMDB != "" {
+		return getEpisodeKey(item.Show.Ids.IMDB, item.Episode.Season, item.Episode.Number)
+	}
+	return ""
+}
+
+func getStremioProgress(state *stremio_api.LibraryItemState) float64 {
+	if state.Duration <= 0 {
+		return 0
+	}
+	return float64(state.TimeOffset) * 100 / float64(state.Duration)
+}
+
+func isStremioWatched(item *stremio_api.LibraryItem) bool {
+	return item.State.FlaggedWatched > 0 || item.State.TimesWatched > 0
+}
+
+var syncTraktMutex sync.Mutex
+
+// SyncTrakt syncs the watched state of movies and series episodes, and the
+// playback progress between the Stremio library and Trakt. Only the items
+// already present in the Stremio library are considered.
+func SyncTrakt(link *TraktLink) error {
+	syncTraktMutex.Lock()
+	defer syncTraktMutex.Unlock()
+
+	err := syncTrakt(link)
+	if err != nil {
+		link.LastError = err.Error()
+	} else {
+		link.LastError = ""
+		link.LastSyncedAt = time.Now()
+	}
+	if serr := SaveTraktLink(link); serr != nil {
+		log.Error("failed to save trakt link", "error", serr, "email", link.StremioEmail)
+	}
+	return err
+}
+
+func syncTrakt(link *TraktLink) error {
+	otok, err := oauth.GetOAuthTokenById(link.TraktTokenId)
+	if err != nil {
+		return err
+	}
+	if otok == nil || otok.AccessToken == "" {
+		return errors.New("trakt.tv token is invalid or revoked")
+	}
+
+	params := &stremio_api.GetAllLibraryItemsParams{}
+	params.APIKey = link.StremioAuthKey
+	res, err := stremioClient.GetAllLibraryItems(params)
+	if err != nil {
+		return errors.New("failed to get stremio library: " + err.Error())
+	}
+
+	client := trakt.GetAPIClient(link.TraktTokenId)
+	since := link.LastSyncedAt
+	now := time.Now()
+
+	changes := map[string]*stremio_api.LibraryItem{}
+
+	if link.SyncWatched {
+		watchedRes, err := client.FetchWatched(&trakt.FetchWatchedParams{Type: "movies"})
+		if err != nil {
+			return errors.New("failed to get trakt.tv watched movies: " + err.Error())
+		}
+		traktWatchedAt := map[string]time.Time{}
+		for i := range watchedRes.Data {
+			item := &watchedRes.Data[i]
+			if item.Movie != nil && item.Movie.Ids.IMDB != "" {
+				traktWatchedAt[item.Movie.Ids.IMDB] = item.LastWatchedAt
+			}
+		}
+
+		toTrakt := []trakt.AddToHistoryMovie{}
+		for i := range res.Data {
+			item := &res.Data[i]
+			if item.Removed || item.Type != "movie" || !strings.HasPrefix(item.Id, "tt") {
+				continue
+			}
+			watchedAt, isTraktWatched := traktWatchedAt[item.Id]
+			if isStremioWatched(item) {
+				if !isTraktWatched && item.MTime.After(since) {
+					watchedAt := item.State.LastWatched
+					if watchedAt.IsZero() {
+						watchedAt = item.MTime
+					}
+					toTrakt = append(toTrakt, trakt.AddToHistoryMovie{
+						WatchedAt: watchedAt.UTC(),
+						Ids:       trakt.SyncIds{IMDB: item.Id},
+					})
+				}
+				continue
+			}
+			if !isTraktWatched {
+				continue
+			}
+			// newly added library item has no watched state of its own
+			if !item.CTime.After(since) && !shouldApplyTrakt(link.ConflictRule, since, item.MTime, watchedAt) {
+				continue
+			}
+			item.State.FlaggedWatched = 1
+			item.State.TimesWatched = max(item.State.TimesWatched, 1)
+			if watchedAt.After(item.State.LastWatched) {
+				item.State.LastWatched = watchedAt
+			}
+			changes[item.Id] = item
+		}
+
+		if len(toTrakt) > 0 {
+			log.Debug("adding watched movies to trakt.tv", "email", link.StremioEmail, "count", len(toTrakt))
+			if _, err := client.AddToHistory(&trakt.AddToHistoryParams{Movies: toTrakt}); err != nil {
+				return errors.New("failed to add trakt.tv history: " + err.Error())
+			}
+		}
+
+		if err := syncTraktWatchedEpisodes(client, link, res.Data, changes); err != nil {
+			return err
+		}
+	}
+
+	if link.SyncProgress {
+		traktPlayback := map[string]*trakt.SyncPlaybackItem{}
+		for _, typ := range []string{"movies", "episodes"} {
+			playbackRes, err := client.FetchPlayback(&trakt.FetchPlaybackParams{Type: typ})
+			if err != nil {
+				return errors.New("failed to get trakt.tv playback: " + err.Error())
+			}
+			for i := range playbackRes.Data {
+				item := &playbackRes.Data[i]
+				if key := getTraktPlaybackKey(item); key != "" {
+					if prev, ok := traktPlayback[key]; !ok || item.PausedAt.After(prev.PausedAt) {
+						traktPlayback[key] = item
+					}
+				}
+			}
+		}
+
+		toTrakt := []*trakt.ScrobblePauseParams{}
+		for i := range res.Data {
+			item := &res.Data[i]
+			if item.Removed || item.State.Duration <= 0 {
+				continue
+			}
+			key := getStremioVideoKey(item)
+			if key == "" {
+				continue
+			}
+			progress := getStremioProgress(&item.State)
+			stremioChanged := item.State.LastWatched.After(since)
+			if tItem, ok := traktPlayback[key]; ok {
+				if math.Abs(progress-tItem.Progress) <= syncProgressTolerance {
+					continue
+				}
+				if shouldApplyTrakt(link.ConflictRule, since, item.State.LastWatched, tItem.PausedAt) {
+					item.State.TimeOffset = int(tItem.Progress * float64(item.State.Duration) / 100)
+					item.State.LastWatched = tItem.PausedAt
+					changes[item.Id] = item
+					continue
+				}
+			}
+			if !stremioChanged || progress < minSyncProgress || progress > maxSyncProgress {
+				continue
+			}
+			sp := &trakt.ScrobblePauseParams{Progress: math.Round(progress*100) / 100}
+			if item.Type == "movie" {
+				sp.Movie = &trakt.SyncMedia{Ids: trakt.SyncIds{IMDB: item.Id}}
+			} else {
+				season, episode, _ := parseEpisodeVideoId(item.Id, item.State.VideoId)
+				sp.Show = &trakt.SyncMedia{Ids: trakt.SyncIds{IMDB: item.Id}}
+				sp.Episode = &trakt.SyncEpisode{Season: season, Number: episode}
+			}
+			toTrakt = append(toTrakt, sp)
+		}
+
+		for i, sp := range toTrakt {
+			if i > 0 {
+				// trakt.tv allows 1 POST request per second
+				time.Sleep(1 * time.Second)
+			}
+			if _, err := client.ScrobblePause(sp); err != nil {
+				log.Warn("failed to save trakt.tv playback progress", "error", err, "email", link.StremioEmail)
+			}
+		}
+	}
+
+	if len(changes) > 0 {
+		items := make([]stremio_api.LibraryItem, 0, len(changes))
+		for _, item := range changes {
+			item.MTime = now
+			items = append(items, *item)
+		}
+		log.Debug("updating stremio library items", "email", link.StremioEmail, "count", len(items))
+		params := &stremio_api.UpdateLibraryItemsParams{Changes: items}
+		params.APIKey = link.StremioAuthKey
+		updateRes, err := stremioClient.UpdateLibraryItems(params)
+		if err != nil {
+			return errors.New("failed to update stremio library: " + err.Error())
+		}
+		if !updateRes.Data.Success {
+			return errors.New("failed to update stremio library")
+		}
+	}
+
+	return nil
+}
+
+// ProcessTraktLinks syncs all the linked accounts.
+func ProcessTraktLinks() error {
+	links, err := traktLinks.List()
+	if err != nil {
+		return err
+	}
+	for i := range links {
+		link := &links[i].Value
+		if link.TraktTokenId == "" || (!link.SyncWatched && !link.SyncProgress) {
+			continue
+		}
+		if err := SyncTrakt(link); err != nil {
+			log.Warn("failed to sync trakt.tv", "error", err, "email", link.StremioEmail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package stremio_sync
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/cache"
+	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/rodezfranco/stremthru/internal/trakt"
+)
+
+var addonClient = stremio_addon.NewClient(&stremio_addon.ClientConfig{})
+
+var cinemetaBaseUrl = func() *url.URL {
+	url, err := url.Parse("https://v3-cinemeta.strem.io/")
+	if err != nil {
+		panic(err)
+	}
+	return url
+}()
+
+var seriesVideoIdsCache = cache.NewCache[[]string](&cache.CacheConfig{
+	Lifetime: 6 * time.Hour,
+	Name:     "stremio:sync:series-video-ids",
+})
+
+// getSeriesVideoIds returns the video ids of the series, in the same order
+// as Stremio uses for the library item's watched bitfield.
+func getSeriesVideoIds(imdbId string) ([]string, error) {
+	videoIds := []string{}
+	if seriesVideoIdsCache.Get(imdbId, &videoIds) {
+		return videoIds, nil
+	}
+
+	res, err := addonClient.FetchMeta(&stremio_addon.FetchMetaParams{
+		BaseURL: cinemetaBaseUrl,
+		Type:    "series",
+		Id:      imdbId + ".json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Data.Meta.Videos {
+		videoIds = append(videoIds, res.Data.Meta.Videos[i].Id)
+	}
+	seriesVideoIdsCache.Add(imdbId, videoIds)
+	return videoIds, nil
+}
+
+// syncTraktWatchedEpisodes syncs the watched episodes between Trakt history
+// and the watched bitfield of the series in Stremio library. Like movies,
+// only watched episodes are synced, unwatching is not.
+func syncTraktWatchedEpisodes(client *trakt.APIClient, link *TraktLink, items []stremio_api.LibraryItem, changes map[string]*stremio_api.LibraryItem) error {
+	since := link.LastSyncedAt
+
+	watchedRes, err := client.FetchWatched(&trakt.FetchWatchedParams{Type: "shows"})
+	if err != nil {
+		return errors.New("failed to get trakt.tv watched shows: " + err.Error())
+	}
+	traktWatchedAtByShow := map[string]map[string]time.Time{}
+	for i := range watchedRes.Data {
+		item := &watchedRes.Data[i]
+		if item.Show == nil || item.Show.Ids.IMDB == "" {
+			continue
+		}
+		id := item.Show.Ids.IMDB
+		watchedAt := map[string]time.Time{}
+		for _, season := range item.Seasons {
+			for _, episode := range season.Episodes {
+				watchedAt[getEpisodeKey(id, season.Number, episode.Number)] = episode.LastWatchedAt
+			}
+		}
+		traktWatchedAtByShow[id] = watchedAt
+	}
+
+	toTrakt := []trakt.AddToHistoryShow{}
+	for i := range items {
+		item := &items[i]
+		if item.Removed || item.Type != "series" || !strings.HasPrefix(item.Id, "tt") {
+			continue
+		}
+
+		traktWatchedAt := traktWatchedAtByShow[item.Id]
+		stremioChanged := item.MTime.After(since)
+		if len(traktWatchedAt) == 0 && (item.State.Watched == "" || !stremioChanged) {
+			continue
+		}
+
+		videoIds, err := getSeriesVideoIds(item.Id)
+		if err != nil {
+			log.Warn("failed to get series videos", "error", err, "id", item.Id)
+			continue
+		}
+		wb, err := stremio_api.NewWatchedBitField(item.State.Watched, videoIds)
+		if err != nil {
+			log.Warn("failed to parse watched bitfield", "error", err, "id", item.Id)
+			continue
+		}
+
+		if stremioChanged {
+			watchedAt := item.State.LastWatched
+			if watchedAt.IsZero() {
+				watchedAt = item.MTime
+			}
+			show := trakt.AddToHistoryShow{Ids: trakt.SyncIds{IMDB: item.Id}}
+			seasonIdx := map[int]int{}
+			for _, videoId := range wb.ListWatched() {
+				if _, isTraktWatched := traktWatchedAt[videoId]; isTraktWatched {
+					continue
+				}
+				season, episode, ok := parseEpisodeVideoId(item.Id, videoId)
+				if !ok {
+					continue
+				}
+				idx, ok := seasonIdx[season]
+				if !ok {
+					idx = len(show.Seasons)
+					seasonIdx[season] = idx
+					show.Seasons = append(show.Seasons, trakt.AddToHistorySeason{Number: season})
+				}
+				show.Seasons[idx].Episodes = append(show.Seasons[idx].Episodes, trakt.AddToHistoryEpisode{
+					WatchedAt: watchedAt.UTC(),
+					Number:    episode,
+				})
+			}
+			if len(show.Seasons) > 0 {
+				toTrakt = append(toTrakt, show)
+			}
+		}
+
+		isChanged := false
+		for videoId, watchedAt := range traktWatchedAt {
+			if wb.IsWatched(videoId) {
+				continue
+			}
+			// newly added library item has no watched state of its own
+			if !item.CTime.After(since) && !shouldApplyTrakt(link.ConflictRule, since, item.MTime, watchedAt) {
+				continue
+			}
+			wb.SetWatched(videoId, true)
+			if !wb.IsWatched(videoId) {
+				// not in the meta's videos
+				continue
+			}
+			isChanged = true
+			if watchedAt.After(item.State.LastWatched) {
+				item.State.LastWatched = watchedAt
+			}
+		}
+		if isChanged {
+			item.State.Watched = wb.String()
+			changes[item.Id] = item
+		}
+	}
+
+	if len(toTrakt) > 0 {
+		log.Debug("adding watched episodes to trakt.tv", "email", link.StremioEmail, "count", len(toTrakt))
+		if _, err := client.AddToHistory(&trakt.AddToHistoryParams{Shows: toTrakt}); err != nil {
+			return errors.New("failed to add trakt.tv history: " + err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package stremio_sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/stremio/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldApplyTrakt(t *testing.T) {
+	lastSyncedAt := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	before := lastSyncedAt.Add(-time.Hour)
+	after := lastSyncedAt.Add(time.Hour)
+	later := lastSyncedAt.Add(2 * time.Hour)
+
+	for _, tc := range []struct {
+		name      string
+		rule      TraktConflictRule
+		stremioAt time.Time
+		traktAt   time.Time
+		result    bool
+	}{
+		{"only trakt changed", TraktConflictRuleStremio, before, after, true},
+		{"only stremio changed", TraktConflictRuleTrakt, after, before, false},
+		{"none changed", TraktConflictRuleTrakt, before, before, false},
+		{"both changed, newest trakt", TraktConflictRuleNewest, after, later, true},
+		{"both changed, newest stremio", TraktConflictRuleNewest, later, after, false},
+		{"both changed, stremio wins", TraktConflictRuleStremio, after, later, false},
+		{"both changed, trakt wins", TraktConflictRuleTrakt, later, after, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.result, shouldApplyTrakt(tc.rule, lastSyncedAt, tc.stremioAt, tc.traktAt))
+		})
+	}
+}
+
+func TestGetStremioVideoKey(t *testing.T) {
+	for _, tc := range []struct {
+		item   stremio_api.LibraryItem
+		result string
+	}{
+		{stremio_api.LibraryItem{Id: "tt0111161", Type: "movie"}, "tt0111161"},
+		{stremio_api.LibraryItem{Id: "tt0903747", Type: "series", State: stremio_api.LibraryItemState{VideoId: "tt0903747:2:5"}}, "tt0903747:2:5"},
+		{stremio_api.LibraryItem{Id: "tt0903747", Type: "series", State: stremio_api.LibraryItemState{VideoId: "tt0000001:2:5"}}, ""},
+		{stremio_api.LibraryItem{Id: "tt0903747", Type: "series"}, ""},
+		{stremio_api.LibraryItem{Id: "kitsu:1", Type: "movie"}, ""},
+	} {
+		assert.Equal(t, tc.result, getStremioVideoKey(&tc.item))
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/rodezfranco/stremthru/internal/shared"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
+	stremio_transformer "github.com/rodezfranco/stremthru/internal/stremio/transformer"
 )
 
 func handleConfigure(w http.ResponseWriter, r *http.Request) {
@@ -28,6 +29,11 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 			if ud.CachedOnly {
 				conf.Default = "checked"
 			}
+		case "filter":
+			conf.Default = ud.Filter
+			if _, err := stremio_transformer.StreamFilterBlob(ud.Filter).Parse(); err != nil {
+				conf.Error = err.Error()
+			}
 		}
 	}
 
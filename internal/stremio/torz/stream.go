@@ -33,20 +33,8 @@ func (s WrappedStream) IsSortable() bool {
 	return s.R != nil
 }
 
-func (s WrappedStream) GetQuality() string {
-	return s.R.Quality
-}
-
-func (s WrappedStream) GetResolution() string {
-	return s.R.Resolution
-}
-
-func (s WrappedStream) GetSize() string {
-	return s.R.Size
-}
-
-func (s WrappedStream) GetHDR() string {
-	return strings.Join(s.R.HDR, "|")
+func (s WrappedStream) GetExtractorResult() *stremio_transformer.StreamExtractorResult {
+	return s.R
 }
 
 func GetStreamsForHashes(stremType, stremId string, hashes []string) ([]WrappedStream, error) {
@@ -265,8 +253,27 @@ func handleStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := stremio_transformer.StreamFilterBlob(ud.Filter).Parse()
+	if err != nil {
+		log.Warn("failed to parse stream filter", "error", err)
+		filter = nil
+	}
+	if !filter.IsEmpty() {
+		if !isP2P {
+			for i := range wrappedStreams {
+				if r := wrappedStreams[i].R; r != nil {
+					storeCode, isCached := isCachedByHash[r.Hash]
+					r.Store.IsCached = isCached && storeCode != ""
+				}
+			}
+		}
+		wrappedStreams = stremio_transformer.FilterStreams(wrappedStreams, filter)
+	}
+
 	stremio_transformer.SortStreams(wrappedStreams, "")
 
+	wrappedStreams = stremio_transformer.LimitStreams(wrappedStreams, filter)
+
 	streamBaseUrl := ExtractRequestBaseURL(r).JoinPath("/stremio/torz", eud, "_/strem", id)
 
 	cachedStreams := []stremio.Stream{}
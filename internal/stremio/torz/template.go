@@ -9,6 +9,7 @@ import (
 	"github.com/rodezfranco/stremthru/internal/stremio/configure"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
 	stremio_template "github.com/rodezfranco/stremthru/internal/stremio/template"
+	stremio_transformer "github.com/rodezfranco/stremthru/internal/stremio/transformer"
 	stremio_userdata "github.com/rodezfranco/stremthru/internal/stremio/userdata"
 )
 
@@ -78,6 +79,12 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 				Type:  configure.ConfigTypeCheckbox,
 				Title: "Only Show Cached Content",
 			},
+			{
+				Key:         "filter",
+				Type:        configure.ConfigTypeTextarea,
+				Title:       "Stream Filter",
+				Description: template.HTML(stremio_transformer.GetFilterDescription()),
+			},
 		},
 		Script: configure.GetScriptStoreTokenDescription("", ""),
 	}
@@ -20,7 +20,8 @@ type UserDataStore struct {
 
 type UserData struct {
 	stremio_userdata.UserDataStores
-	CachedOnly bool `json:"cached,omitempty"`
+	CachedOnly bool   `json:"cached,omitempty"`
+	Filter     string `json:"filter,omitempty"`
 
 	encoded string `json:"-"` // correctly configured
 }
@@ -161,6 +162,7 @@ func getUserData(r *http.Request) (*UserData, error) {
 		}
 
 		data.CachedOnly = r.Form.Get("cached") == "on"
+		data.Filter = r.Form.Get("filter")
 	}
 
 	if IsPublicInstance && len(data.Stores) > MaxPublicInstanceStoreCount {
@@ -34,6 +34,7 @@ const (
 	StreamExtractorFieldQuality       StreamExtractorField = "quality"
 	StreamExtractorFieldResolution    StreamExtractorField = "resolution"
 	StreamExtractorFieldSeason        StreamExtractorField = "season"
+	StreamExtractorFieldSeeders       StreamExtractorField = "seeders"
 	StreamExtractorFieldSite          StreamExtractorField = "site"
 	StreamExtractorFieldSize          StreamExtractorField = "size"
 	StreamExtractorFieldStoreCode     StreamExtractorField = "store_code"
@@ -133,6 +134,7 @@ type StreamExtractorResult struct {
 	Hash     string
 	Raw      StreamExtractorResultRaw
 	Season   int
+	Seeders  int
 	Store    StreamExtractorResultStore
 	TTitle   string
 }
@@ -357,6 +359,10 @@ func (se StreamExtractor) Parse(stream *stremio.Stream, sType string) *StreamExt
 								r.Seasons = []int{season}
 							}
 						}
+					case StreamExtractorFieldSeeders:
+						if seeders, err := strconv.Atoi(value); err == nil {
+							r.Seeders = seeders
+						}
 					case StreamExtractorFieldSite:
 						r.Site = value
 					case StreamExtractorFieldSize:
@@ -30,6 +30,7 @@ func TestStreamExtractorPeerflixTorrent(t *testing.T) {
 					Site:       "Peerflix",
 					Size:       "20.38 GB",
 				},
+				Seeders: 1,
 				Addon: StreamExtractorResultAddon{
 					Name: "Peerflix",
 				},
@@ -55,6 +56,7 @@ func TestStreamExtractorPeerflixTorrent(t *testing.T) {
 					Site:       "Peerflix",
 					Size:       "410.26 MB",
 				},
+				Seeders: 89,
 				Addon: StreamExtractorResultAddon{
 					Name: "Peerflix",
 				},
@@ -99,6 +101,7 @@ func TestStreamExtractorPeerflixDebrid(t *testing.T) {
 					Site:       "Peerflix",
 					Size:       "20.38 GB",
 				},
+				Seeders: 1,
 				Addon: StreamExtractorResultAddon{
 					Name: "Peerflix",
 				},
@@ -127,6 +130,7 @@ func TestStreamExtractorPeerflixDebrid(t *testing.T) {
 					Site:       "Peerflix",
 					Size:       "381.53 MB",
 				},
+				Seeders: 89,
 				Addon: StreamExtractorResultAddon{
 					Name: "Peerflix",
 				},
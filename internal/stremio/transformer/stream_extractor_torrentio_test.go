@@ -37,6 +37,7 @@ func TestStreamExtractorTorrentioTorrent(t *testing.T) {
 					Site:       "TorrentGalaxy",
 					Size:       "40.33 GB",
 				},
+				Seeders: 47,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -68,6 +69,7 @@ func TestStreamExtractorTorrentioTorrent(t *testing.T) {
 					Site:    "ThePirateBay",
 					Size:    "864.57 MB",
 				},
+				Seeders: 5,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -100,6 +102,7 @@ func TestStreamExtractorTorrentioTorrent(t *testing.T) {
 					Site:       "TorrentGalaxy",
 					Size:       "3.65 GB",
 				},
+				Seeders: 20,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -131,6 +134,7 @@ func TestStreamExtractorTorrentioTorrent(t *testing.T) {
 					Site:       "TorrentGalaxy",
 					Size:       "3.65 GB",
 				},
+				Seeders: 20,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -162,6 +166,7 @@ func TestStreamExtractorTorrentioTorrent(t *testing.T) {
 					Site:       "1337x",
 					Size:       "934.8 MB",
 				},
+				Seeders: 3,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -210,6 +215,7 @@ func TestStreamExtractorTorrentioDebrid(t *testing.T) {
 					Site:       "1337x",
 					Size:       "22.42 GB",
 				},
+				Seeders: 16,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -246,6 +252,7 @@ func TestStreamExtractorTorrentioDebrid(t *testing.T) {
 					Site:       "TorrentGalaxy",
 					Size:       "3.65 GB",
 				},
+				Seeders: 20,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
@@ -282,6 +289,7 @@ func TestStreamExtractorTorrentioDebrid(t *testing.T) {
 					Site:       "1337x",
 					Size:       "934.8 MB",
 				},
+				Seeders: 3,
 				Addon: StreamExtractorResultAddon{
 					Name: "Torrentio",
 				},
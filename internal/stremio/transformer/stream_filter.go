@@ -0,0 +1,332 @@
+package stremio_transformer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type StreamFilterField = string
+
+const (
+	StreamFilterFieldBitDepth      StreamFilterField = "bitdepth"
+	StreamFilterFieldCodec         StreamFilterField = "codec"
+	StreamFilterFieldHDR           StreamFilterField = "hdr"
+	StreamFilterFieldLanguage      StreamFilterField = "language"
+	StreamFilterFieldQuality       StreamFilterField = "quality"
+	StreamFilterFieldResolution    StreamFilterField = "resolution"
+	StreamFilterFieldSite          StreamFilterField = "site"
+	StreamFilterFieldSize          StreamFilterField = "size"
+	StreamFilterFieldStoreIsCached StreamFilterField = "store_is_cached"
+)
+
+var streamFilterFields = []StreamFilterField{
+	StreamFilterFieldBitDepth,
+	StreamFilterFieldCodec,
+	StreamFilterFieldHDR,
+	StreamFilterFieldLanguage,
+	StreamFilterFieldQuality,
+	StreamFilterFieldResolution,
+	StreamFilterFieldSite,
+	StreamFilterFieldSize,
+	StreamFilterFieldStoreIsCached,
+}
+
+type streamFilterOperator string
+
+const (
+	streamFilterOperatorEq       streamFilterOperator = "="
+	streamFilterOperatorNotEq    streamFilterOperator = "!="
+	streamFilterOperatorIn       streamFilterOperator = "in"
+	streamFilterOperatorNotIn    streamFilterOperator = "!in"
+	streamFilterOperatorMatch    streamFilterOperator = "~"
+	streamFilterOperatorNotMatch streamFilterOperator = "!~"
+	streamFilterOperatorLt       streamFilterOperator = "<"
+	streamFilterOperatorLte      streamFilterOperator = "<="
+	streamFilterOperatorGt       streamFilterOperator = ">"
+	streamFilterOperatorGte      streamFilterOperator = ">="
+)
+
+type streamFilterCondition struct {
+	field    StreamFilterField
+	operator streamFilterOperator
+	values   []string
+	regex    *regexp.Regexp
+	rank     int64
+}
+
+func getFilterFieldValues(r *StreamExtractorResult, field StreamFilterField) []string {
+	if r.Result == nil && field != StreamFilterFieldStoreIsCached {
+		return nil
+	}
+	switch field {
+	case StreamFilterFieldBitDepth:
+		return []string{r.BitDepth}
+	case StreamFilterFieldCodec:
+		return []string{r.Codec}
+	case StreamFilterFieldHDR:
+		return r.HDR
+	case StreamFilterFieldLanguage:
+		return r.Languages
+	case StreamFilterFieldQuality:
+		return []string{r.Quality}
+	case StreamFilterFieldResolution:
+		return []string{r.Resolution}
+	case StreamFilterFieldSite:
+		return []string{r.Site}
+	case StreamFilterFieldSize:
+		if r.Size == "" {
+			return []string{r.File.Size}
+		}
+		return []string{r.Size}
+	case StreamFilterFieldStoreIsCached:
+		return []string{strconv.FormatBool(r.Store.IsCached)}
+	default:
+		return nil
+	}
+}
+
+func getFilterFieldRank(field StreamFilterField, value string) int64 {
+	switch field {
+	case StreamFilterFieldResolution:
+		return getResolutionRank(strings.ToLower(value))
+	case StreamFilterFieldSize:
+		return getSizeRank(value)
+	default:
+		return -1
+	}
+}
+
+func (c streamFilterCondition) match(r *StreamExtractorResult) bool {
+	values := getFilterFieldValues(r, c.field)
+	switch c.operator {
+	case streamFilterOperatorEq, streamFilterOperatorIn:
+		return slices.ContainsFunc(values, func(v string) bool {
+			return v != "" && slices.ContainsFunc(c.values, func(cv string) bool {
+				return strings.EqualFold(v, cv)
+			})
+		})
+	case streamFilterOperatorNotEq, streamFilterOperatorNotIn:
+		return !streamFilterCondition{field: c.field, operator: streamFilterOperatorIn, values: c.values}.match(r)
+	case streamFilterOperatorMatch:
+		return slices.ContainsFunc(values, func(v string) bool {
+			return v != "" && c.regex.MatchString(v)
+		})
+	case streamFilterOperatorNotMatch:
+		return !streamFilterCondition{field: c.field, operator: streamFilterOperatorMatch, regex: c.regex}.match(r)
+	}
+
+	// numeric comparison, unknown values never match
+	if len(values) == 0 {
+		return false
+	}
+	rank := getFilterFieldRank(c.field, values[0])
+	if rank <= 0 {
+		return false
+	}
+	switch c.operator {
+	case streamFilterOperatorLt:
+		return rank < c.rank
+	case streamFilterOperatorLte:
+		return rank <= c.rank
+	case streamFilterOperatorGt:
+		return rank > c.rank
+	case streamFilterOperatorGte:
+		return rank >= c.rank
+	}
+	return false
+}
+
+type streamFilterRule struct {
+	exclude    bool
+	conditions []streamFilterCondition
+}
+
+func (rule streamFilterRule) match(r *StreamExtractorResult) bool {
+	for i := range rule.conditions {
+		if !rule.conditions[i].match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type streamFilterLimit struct {
+	count int
+	field StreamFilterField
+}
+
+// StreamFilterBlob contains one rule per line (or separated by `;`):
+//
+//	include <condition> [and <condition>...]
+//	exclude <condition> [and <condition>...]
+//	limit <count> per <field>
+//
+// condition is `<field> <operator> <value>`, supported operators:
+// `=`, `!=`, `in`, `!in` (comma separated values), `~`, `!~` (regex),
+// `<`, `<=`, `>`, `>=` (only for resolution and size).
+type StreamFilterBlob string
+
+type StreamFilter struct {
+	Blob   StreamFilterBlob
+	rules  []streamFilterRule
+	limits []streamFilterLimit
+}
+
+var streamFilterConditionRegex = regexp.MustCompile(`^(\w+)\s*(!=|<=|>=|!~|=|<|>|~|!in\s|in\s)\s*(.+)$`)
+var streamFilterAndRegex = regexp.MustCompile(`(?i)\s+and\s+`)
+var streamFilterLimitRegex = regexp.MustCompile(`(?i)^(\d+)\s+per\s+(\w+)$`)
+
+func parseStreamFilterCondition(input string) (streamFilterCondition, error) {
+	c := streamFilterCondition{}
+	match := streamFilterConditionRegex.FindStringSubmatch(strings.TrimSpace(input))
+	if match == nil {
+		return c, fmt.Errorf("invalid condition: %s", input)
+	}
+	c.field = strings.ToLower(match[1])
+	if !slices.Contains(streamFilterFields, c.field) {
+		return c, fmt.Errorf("unsupported field: %s", c.field)
+	}
+	c.operator = streamFilterOperator(strings.TrimSpace(match[2]))
+	value := strings.TrimSpace(match[3])
+
+	switch c.operator {
+	case streamFilterOperatorEq, streamFilterOperatorNotEq:
+		c.values = []string{value}
+	case streamFilterOperatorIn, streamFilterOperatorNotIn:
+		for v := range strings.SplitSeq(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				c.values = append(c.values, v)
+			}
+		}
+	case streamFilterOperatorMatch, streamFilterOperatorNotMatch:
+		re, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			return c, fmt.Errorf("invalid regex: %s", value)
+		}
+		c.regex = re
+	default:
+		if c.field != StreamFilterFieldResolution && c.field != StreamFilterFieldSize {
+			return c, fmt.Errorf("unsupported operator for %s: %s", c.field, c.operator)
+		}
+		c.rank = getFilterFieldRank(c.field, value)
+		if c.rank <= 0 {
+			return c, fmt.Errorf("invalid %s: %s", c.field, value)
+		}
+	}
+	return c, nil
+}
+
+func (sfb StreamFilterBlob) Parse() (*StreamFilter, error) {
+	sf := &StreamFilter{Blob: sfb}
+	for line := range strings.FieldsFuncSeq(string(sfb), func(c rune) bool {
+		return c == '\n' || c == ';'
+	}) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		action, body, _ := strings.Cut(line, " ")
+		body = strings.TrimSpace(body)
+		switch strings.ToLower(action) {
+		case "include", "exclude":
+			rule := streamFilterRule{exclude: strings.ToLower(action) == "exclude"}
+			for _, part := range streamFilterAndRegex.Split(body, -1) {
+				c, err := parseStreamFilterCondition(part)
+				if err != nil {
+					return sf, err
+				}
+				rule.conditions = append(rule.conditions, c)
+			}
+			sf.rules = append(sf.rules, rule)
+		case "limit":
+			match := streamFilterLimitRegex.FindStringSubmatch(body)
+			if match == nil {
+				return sf, fmt.Errorf("invalid limit: %s", body)
+			}
+			count, _ := strconv.Atoi(match[1])
+			field := strings.ToLower(match[2])
+			if count < 1 || !slices.Contains(streamFilterFields, field) {
+				return sf, fmt.Errorf("invalid limit: %s", body)
+			}
+			sf.limits = append(sf.limits, streamFilterLimit{count: count, field: field})
+		default:
+			return sf, errors.New("invalid rule: " + line)
+		}
+	}
+	return sf, nil
+}
+
+func (sf *StreamFilter) IsEmpty() bool {
+	return sf == nil || (len(sf.rules) == 0 && len(sf.limits) == 0)
+}
+
+// match checks include/exclude rules, stream is kept only if it matches any
+// of the include rules (if there are any) and none of the exclude rules.
+func (sf *StreamFilter) match(r *StreamExtractorResult) bool {
+	hasInclude, isIncluded := false, false
+	for i := range sf.rules {
+		rule := &sf.rules[i]
+		if rule.exclude {
+			if rule.match(r) {
+				return false
+			}
+			continue
+		}
+		hasInclude = true
+		if !isIncluded {
+			isIncluded = rule.match(r)
+		}
+	}
+	return !hasInclude || isIncluded
+}
+
+type StreamFilterable interface {
+	GetExtractorResult() *StreamExtractorResult
+}
+
+// FilterStreams drops the streams not passing the include/exclude rules.
+// Streams without extracted data are kept as is.
+func FilterStreams[T StreamFilterable](items []T, filter *StreamFilter) []T {
+	if filter.IsEmpty() || len(filter.rules) == 0 {
+		return items
+	}
+	return slices.DeleteFunc(items, func(item T) bool {
+		r := item.GetExtractorResult()
+		return r != nil && !filter.match(r)
+	})
+}
+
+// LimitStreams keeps the first N streams per field value, so it should be
+// called after sorting.
+func LimitStreams[T StreamFilterable](items []T, filter *StreamFilter) []T {
+	if filter.IsEmpty() || len(filter.limits) == 0 {
+		return items
+	}
+	for _, limit := range filter.limits {
+		countByValue := map[string]int{}
+		items = slices.DeleteFunc(items, func(item T) bool {
+			r := item.GetExtractorResult()
+			if r == nil {
+				return false
+			}
+			value := strings.ToLower(strings.Join(getFilterFieldValues(r, limit.field), ","))
+			countByValue[value]++
+			return countByValue[value] > limit.count
+		})
+	}
+	return items
+}
+
+// GetFilterDescription is the help text for configure pages.
+func GetFilterDescription() string {
+	return "One rule per line: <code>include &lt;condition&gt;</code>, <code>exclude &lt;condition&gt;</code> or <code>limit &lt;count&gt; per &lt;field&gt;</code>. " +
+		"Stream is kept if it matches any <code>include</code> rule and no <code>exclude</code> rule. " +
+		"Conditions can be joined with <code>and</code>, e.g. <code>exclude resolution = 2160p and size &gt; 20GB</code>. " +
+		"Fields: " + "<code>" + strings.Join(streamFilterFields, "</code>, <code>") + "</code>. " +
+		"Operators: <code>=</code>, <code>!=</code>, <code>in</code>, <code>!in</code>, <code>~</code>, <code>!~</code>, <code>&lt;</code>, <code>&lt;=</code>, <code>&gt;</code>, <code>&gt;=</code>."
+}
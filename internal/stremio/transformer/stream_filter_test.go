@@ -0,0 +1,108 @@
+package stremio_transformer
+
+import (
+	"testing"
+
+	"github.com/MunifTanjim/go-ptt"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFilterableStream struct {
+	name string
+	r    *StreamExtractorResult
+}
+
+func (s testFilterableStream) GetExtractorResult() *StreamExtractorResult {
+	return s.r
+}
+
+func newTestFilterableStream(name, resolution, size string, languages []string, isCached bool) testFilterableStream {
+	r := &StreamExtractorResult{
+		Result: &ptt.Result{
+			Resolution: resolution,
+			Size:       size,
+			Languages:  languages,
+		},
+	}
+	r.Store.IsCached = isCached
+	return testFilterableStream{name: name, r: r}
+}
+
+func getTestFilterableStreamNames(items []testFilterableStream) []string {
+	names := make([]string, len(items))
+	for i := range items {
+		names[i] = items[i].name
+	}
+	return names
+}
+
+func TestStreamFilterBlobParse(t *testing.T) {
+	for _, tc := range []struct {
+		blob  string
+		error string
+	}{
+		{"", ""},
+		{"# comment\ninclude resolution in 1080p, 2160p; limit 2 per resolution", ""},
+		{"exclude size > 20GB and resolution = 2160p", ""},
+		{"drop resolution = 720p", "invalid rule: drop resolution = 720p"},
+		{"include title = foo", "unsupported field: title"},
+		{"include codec > x264", "unsupported operator for codec: >"},
+		{"include size > huge", "invalid size: huge"},
+		{"include site ~ (", "invalid regex: ("},
+		{"limit 0 per resolution", "invalid limit: 0 per resolution"},
+	} {
+		t.Run(tc.blob, func(t *testing.T) {
+			_, err := StreamFilterBlob(tc.blob).Parse()
+			if tc.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.error)
+			}
+		})
+	}
+}
+
+func TestFilterStreams(t *testing.T) {
+	newItems := func() []testFilterableStream {
+		return []testFilterableStream{
+			newTestFilterableStream("a", "2160p", "40 GB", []string{"en"}, true),
+			newTestFilterableStream("b", "2160p", "15 GB", []string{"en", "fr"}, false),
+			newTestFilterableStream("c", "1080p", "8 GB", []string{"fr"}, true),
+			newTestFilterableStream("d", "720p", "2 GB", nil, false),
+			{name: "e"},
+		}
+	}
+
+	for _, tc := range []struct {
+		blob  string
+		names []string
+	}{
+		{"", []string{"a", "b", "c", "d", "e"}},
+		{"exclude resolution = 2160p and size > 20GB", []string{"b", "c", "d", "e"}},
+		{"include resolution >= 1080p", []string{"a", "b", "c", "e"}},
+		{"include language in en, de", []string{"a", "b", "e"}},
+		{"exclude language !in fr", []string{"b", "c", "e"}},
+		{"include store_is_cached = true", []string{"a", "c", "e"}},
+		{"include size >= 5GB and size < 20GB", []string{"b", "c", "e"}},
+		{"include resolution = 720p; include store_is_cached = true", []string{"a", "c", "d", "e"}},
+	} {
+		t.Run(tc.blob, func(t *testing.T) {
+			filter, err := StreamFilterBlob(tc.blob).Parse()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.names, getTestFilterableStreamNames(FilterStreams(newItems(), filter)))
+		})
+	}
+}
+
+func TestLimitStreams(t *testing.T) {
+	items := []testFilterableStream{
+		newTestFilterableStream("a", "2160p", "40 GB", nil, true),
+		newTestFilterableStream("b", "2160p", "15 GB", nil, false),
+		newTestFilterableStream("c", "1080p", "8 GB", nil, true),
+		newTestFilterableStream("d", "1080p", "6 GB", nil, true),
+		newTestFilterableStream("e", "2160p", "10 GB", nil, true),
+	}
+	filter, err := StreamFilterBlob("limit 1 per resolution").Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, getTestFilterableStreamNames(LimitStreams(items, filter)))
+}
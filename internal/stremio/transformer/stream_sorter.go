@@ -1,6 +1,7 @@
 package stremio_transformer
 
 import (
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,13 +16,33 @@ const (
 	StreamSortableFieldQuality    StreamSortableField = "quality"
 	StreamSortableFieldSize       StreamSortableField = "size"
 	StreamSortableFieldHDR        StreamSortableField = "hdr"
+	StreamSortableFieldLanguage   StreamSortableField = "language"
+	StreamSortableFieldCodec      StreamSortableField = "codec"
+	StreamSortableFieldAudio      StreamSortableField = "audio"
+	StreamSortableFieldBitDepth   StreamSortableField = "bitdepth"
+	StreamSortableFieldSeeders    StreamSortableField = "seeders"
+	StreamSortableFieldCached     StreamSortableField = "cached"
+	StreamSortableFieldStore      StreamSortableField = "store"
+	StreamSortableFieldGroup      StreamSortableField = "group"
 )
 
+var streamSortableFields = []StreamSortableField{
+	StreamSortableFieldResolution,
+	StreamSortableFieldQuality,
+	StreamSortableFieldSize,
+	StreamSortableFieldHDR,
+	StreamSortableFieldLanguage,
+	StreamSortableFieldCodec,
+	StreamSortableFieldAudio,
+	StreamSortableFieldBitDepth,
+	StreamSortableFieldSeeders,
+	StreamSortableFieldCached,
+	StreamSortableFieldStore,
+	StreamSortableFieldGroup,
+}
+
 type StreamSortable interface {
-	GetQuality() string
-	GetResolution() string
-	GetSize() string
-	GetHDR() string
+	StreamFilterable
 	IsSortable() bool
 }
 
@@ -109,20 +130,166 @@ func getSizeRank(input string) int64 {
 	return util.ToBytes(input)
 }
 
-func getHDRRank(input string) int64 {
-	return int64(len(input))
+func getHDRRank(input []string) int64 {
+	return int64(len(strings.Join(input, "|")))
+}
+
+func normalizeCodec(input string) string {
+	codec := strings.ToLower(input)
+	switch {
+	case strings.Contains(codec, "av1"):
+		return "av1"
+	case strings.Contains(codec, "hevc"), strings.Contains(codec, "265"):
+		return "hevc"
+	case strings.Contains(codec, "avc"), strings.Contains(codec, "264"):
+		return "avc"
+	}
+	return codec
 }
 
-func getFieldRank(str StreamSortable, field StreamSortableField) int64 {
+func getCodecRank(input string) int64 {
+	switch normalizeCodec(input) {
+	case "av1":
+		return 4
+	case "hevc":
+		return 3
+	case "avc":
+		return 2
+	case "":
+		return 0
+	}
+	return 1
+}
+
+func getAudioRank(input string) int64 {
+	audio := strings.ToLower(input)
+	switch {
+	case strings.Contains(audio, "atmos"):
+		return 8
+	case strings.Contains(audio, "truehd"):
+		return 7
+	case strings.Contains(audio, "dts") && (strings.Contains(audio, "lossless") || strings.Contains(audio, "hd") || strings.Contains(audio, "x")):
+		return 6
+	case strings.Contains(audio, "flac"):
+		return 5
+	case strings.Contains(audio, "dts"):
+		return 4
+	case strings.Contains(audio, "ddp"), strings.Contains(audio, "dd+"), strings.Contains(audio, "eac3"), strings.Contains(audio, "e-ac3"):
+		return 3
+	case strings.Contains(audio, "dd"), strings.Contains(audio, "ac3"):
+		return 2
+	case audio != "":
+		return 1
+	}
+	return 0
+}
+
+func getBitDepthRank(input string) int64 {
+	bitDepth, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(input), "bit"))
+	if err != nil {
+		return 0
+	}
+	return int64(bitDepth)
+}
+
+func getMaxRank(values []string, getRank func(value string) int64) int64 {
+	rank := int64(0)
+	for _, value := range values {
+		rank = max(rank, getRank(value))
+	}
+	return rank
+}
+
+func getSortFieldValues(r *StreamExtractorResult, field StreamSortableField) []string {
+	switch field {
+	case StreamSortableFieldStore:
+		return []string{r.Store.Code, r.Store.Name}
+	case StreamSortableFieldCached:
+		return []string{strconv.FormatBool(r.Store.IsCached)}
+	}
+	if r.Result == nil {
+		return nil
+	}
 	switch field {
 	case StreamSortableFieldResolution:
-		return getResolutionRank(str.GetResolution())
+		return []string{r.Resolution}
 	case StreamSortableFieldQuality:
-		return getQualityRank(str.GetQuality())
+		return []string{r.Quality}
+	case StreamSortableFieldHDR:
+		return r.HDR
+	case StreamSortableFieldLanguage:
+		return r.Languages
+	case StreamSortableFieldCodec:
+		return []string{normalizeCodec(r.Codec)}
+	case StreamSortableFieldAudio:
+		return r.Audio
+	case StreamSortableFieldBitDepth:
+		return []string{r.BitDepth}
+	case StreamSortableFieldGroup:
+		return []string{r.Group}
+	}
+	return nil
+}
+
+// getCustomRank ranks the values by their position in the user provided
+// list, earlier ones are ranked higher and missing ones are ranked lowest.
+func getCustomRank(r *StreamExtractorResult, field StreamSortableField, ranks []string) int64 {
+	return getMaxRank(getSortFieldValues(r, field), func(value string) int64 {
+		if value == "" {
+			return 0
+		}
+		idx := slices.IndexFunc(ranks, func(rank string) bool {
+			return strings.EqualFold(rank, value)
+		})
+		if idx == -1 {
+			return 0
+		}
+		return int64(len(ranks) - idx)
+	})
+}
+
+func getFieldRank(str StreamSortable, config *StreamSorterConfig) int64 {
+	r := str.GetExtractorResult()
+	if len(config.Ranks) > 0 {
+		return getCustomRank(r, config.Field, config.Ranks)
+	}
+
+	switch config.Field {
 	case StreamSortableFieldSize:
-		return getSizeRank(str.GetSize())
+		if r.Result == nil {
+			return 0
+		}
+		return getSizeRank(r.Size)
+	case StreamSortableFieldSeeders:
+		return int64(r.Seeders)
+	case StreamSortableFieldCached:
+		if r.Store.IsCached {
+			return 1
+		}
+		return 0
+	case StreamSortableFieldStore, StreamSortableFieldGroup:
+		// only sortable with custom ranks
+		return 0
+	}
+
+	values := getSortFieldValues(r, config.Field)
+	switch config.Field {
+	case StreamSortableFieldResolution:
+		return getMaxRank(values, func(value string) int64 {
+			return getResolutionRank(strings.ToLower(value))
+		})
+	case StreamSortableFieldQuality:
+		return getMaxRank(values, getQualityRank)
 	case StreamSortableFieldHDR:
-		return getHDRRank(str.GetHDR())
+		return getHDRRank(values)
+	case StreamSortableFieldLanguage:
+		return int64(len(values))
+	case StreamSortableFieldCodec:
+		return getMaxRank(values, getCodecRank)
+	case StreamSortableFieldAudio:
+		return getMaxRank(values, getAudioRank)
+	case StreamSortableFieldBitDepth:
+		return getMaxRank(values, getBitDepthRank)
 	default:
 		panic("Unsupported field for sorting")
 	}
@@ -131,18 +298,32 @@ func getFieldRank(str StreamSortable, field StreamSortableField) int64 {
 type StreamSorterConfig struct {
 	Field StreamSortableField
 	Desc  bool
+	Ranks []string
 }
 
+// parseSortConfig parses comma separated fields, e.g. `-language:en|hi,-codec,-resolution`.
+// Prefix with `-` for reverse sort, and suffix with `:<value>|<value>...` for
+// custom rank table.
 func parseSortConfig(config string) []StreamSorterConfig {
 	sortConfigs := []StreamSorterConfig{}
 	for part := range strings.SplitSeq(config, ",") {
 		part = strings.TrimSpace(part)
 		desc := strings.HasPrefix(part, "-")
-		field := StreamSortableField(strings.TrimPrefix(part, "-"))
-		switch field {
-		case StreamSortableFieldResolution, StreamSortableFieldQuality, StreamSortableFieldSize, StreamSortableFieldHDR:
-			sortConfigs = append(sortConfigs, StreamSorterConfig{Field: field, Desc: desc})
+		part, ranksPart, _ := strings.Cut(strings.TrimPrefix(part, "-"), ":")
+		field := StreamSortableField(strings.ToLower(strings.TrimSpace(part)))
+		if !slices.Contains(streamSortableFields, field) {
+			continue
+		}
+		sortConfig := StreamSorterConfig{Field: field, Desc: desc}
+		for rank := range strings.SplitSeq(ranksPart, "|") {
+			if rank = strings.TrimSpace(rank); rank != "" {
+				if field == StreamSortableFieldCodec {
+					rank = normalizeCodec(rank)
+				}
+				sortConfig.Ranks = append(sortConfig.Ranks, rank)
+			}
 		}
+		sortConfigs = append(sortConfigs, sortConfig)
 	}
 	return sortConfigs
 }
@@ -167,9 +348,10 @@ func (ss streamSorter[StreamSortable]) Less(a, b int) bool {
 		return false
 	}
 
-	for _, config := range ss.config {
-		va := getFieldRank(aData, config.Field)
-		vb := getFieldRank(bData, config.Field)
+	for i := range ss.config {
+		config := &ss.config[i]
+		va := getFieldRank(aData, config)
+		vb := getFieldRank(bData, config)
 
 		if va == vb {
 			continue
@@ -198,3 +380,15 @@ func SortStreams[T StreamSortable](items []T, config string) {
 	sorter := streamSorter[T]{items: items, config: sortConfigs}
 	sort.Stable(sorter)
 }
+
+// GetSortDescription is the help text for configure pages.
+func GetSortDescription() string {
+	fields := make([]string, len(streamSortableFields))
+	for i, field := range streamSortableFields {
+		fields[i] = string(field)
+	}
+	return "Comma separated fields: <code>" + strings.Join(fields, "</code>, <code>") + "</code>. " +
+		"Prefix with <code>-</code> for reverse sort. " +
+		"Add custom ranks with <code>:</code>, e.g. <code>-language:en|hi</code> (earlier values are ranked higher; <code>store</code> and <code>group</code> need custom ranks). " +
+		"Default: <code>" + StreamDefaultSortConfig + "</code>"
+}
@@ -0,0 +1,55 @@
+package stremio_transformer
+
+import (
+	"testing"
+
+	"github.com/MunifTanjim/go-ptt"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s testFilterableStream) IsSortable() bool {
+	return s.r != nil
+}
+
+func TestParseSortConfig(t *testing.T) {
+	assert.Equal(t, []StreamSorterConfig{
+		{Field: StreamSortableFieldLanguage, Desc: true, Ranks: []string{"en", "hi"}},
+		{Field: StreamSortableFieldCodec, Desc: true, Ranks: []string{"hevc", "avc"}},
+		{Field: StreamSortableFieldResolution, Desc: false},
+		{Field: StreamSortableFieldCached, Desc: true},
+	}, parseSortConfig("-language:en|hi, -codec:x265|h264, resolution, -unknown, -cached"))
+}
+
+func TestSortStreams(t *testing.T) {
+	newStream := func(name string, result ptt.Result, isCached bool) testFilterableStream {
+		r := &StreamExtractorResult{Result: &result}
+		r.Store.IsCached = isCached
+		return testFilterableStream{name: name, r: r}
+	}
+	newItems := func() []testFilterableStream {
+		return []testFilterableStream{
+			newStream("fr-hevc-2160p", ptt.Result{Languages: []string{"fr"}, Codec: "x265", Resolution: "2160p", BitDepth: "10bit", Audio: []string{"DD"}}, true),
+			{name: "unknown"},
+			newStream("en-avc-1080p", ptt.Result{Languages: []string{"en"}, Codec: "avc", Resolution: "1080p", BitDepth: "8bit", Audio: []string{"AAC"}}, false),
+			newStream("en-hevc-1080p", ptt.Result{Languages: []string{"en"}, Codec: "HEVC", Resolution: "1080p", BitDepth: "10bit", Audio: []string{"TrueHD", "Atmos"}, Group: "FraMeSToR"}, false),
+			newStream("en-hevc-720p", ptt.Result{Languages: []string{"en"}, Codec: "hevc", Resolution: "720p", Audio: []string{"DTS Lossy"}}, true),
+		}
+	}
+
+	for _, tc := range []struct {
+		config string
+		names  []string
+	}{
+		{"-language:en,-codec:hevc,-resolution:1080p,-cached", []string{"en-hevc-1080p", "en-hevc-720p", "en-avc-1080p", "fr-hevc-2160p", "unknown"}},
+		{"-cached,-resolution", []string{"fr-hevc-2160p", "en-hevc-720p", "en-avc-1080p", "en-hevc-1080p", "unknown"}},
+		{"-audio", []string{"en-hevc-1080p", "en-hevc-720p", "fr-hevc-2160p", "en-avc-1080p", "unknown"}},
+		{"bitdepth,-resolution", []string{"en-hevc-720p", "en-avc-1080p", "fr-hevc-2160p", "en-hevc-1080p", "unknown"}},
+		{"-group:framestor", []string{"en-hevc-1080p", "fr-hevc-2160p", "en-avc-1080p", "en-hevc-720p", "unknown"}},
+	} {
+		t.Run(tc.config, func(t *testing.T) {
+			items := newItems()
+			SortStreams(items, tc.config)
+			assert.Equal(t, tc.names, getTestFilterableStreamNames(items))
+		})
+	}
+}
@@ -5,12 +5,124 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/rodezfranco/stremthru/internal/context"
 	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
 	"github.com/rodezfranco/stremthru/stremio"
 )
 
+type CatalogCombineMode string
+
+const (
+	CatalogCombineModeNone       CatalogCombineMode = ""
+	CatalogCombineModeInterleave CatalogCombineMode = "interleave"
+	CatalogCombineModeDedupe     CatalogCombineMode = "dedupe"
+)
+
+func (m CatalogCombineMode) IsValid() bool {
+	switch m {
+	case CatalogCombineModeNone, CatalogCombineModeInterleave, CatalogCombineModeDedupe:
+		return true
+	default:
+		return false
+	}
+}
+
+const combinedCatalogIdPrefix = "combined::"
+
+type combinedCatalogSource struct {
+	idx       int
+	catalogId string
+}
+
+func isCatalogExtraRequired(c *stremio.Catalog) bool {
+	if len(c.ExtraRequired) > 0 {
+		return true
+	}
+	for i := range c.Extra {
+		if c.Extra[i].IsRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// getCombinedCatalogSources groups the upstream catalogs by type, only the
+// catalogs that can be fetched without extra are combined.
+func getCombinedCatalogSources(manifests []stremio.Manifest) (types []string, sourcesByType map[string][]combinedCatalogSource) {
+	sourcesByType = map[string][]combinedCatalogSource{}
+	for mIdx := range manifests {
+		m := &manifests[mIdx]
+		for i := range m.Catalogs {
+			c := &m.Catalogs[i]
+			if isCatalogExtraRequired(c) {
+				continue
+			}
+			if _, found := sourcesByType[c.Type]; !found {
+				types = append(types, c.Type)
+			}
+			sourcesByType[c.Type] = append(sourcesByType[c.Type], combinedCatalogSource{idx: mIdx, catalogId: c.Id})
+		}
+	}
+	return types, sourcesByType
+}
+
+func getCombinedCatalogs(manifests []stremio.Manifest) []stremio.Catalog {
+	catalogs := []stremio.Catalog{}
+	types, sourcesByType := getCombinedCatalogSources(manifests)
+	for _, cType := range types {
+		if len(sourcesByType[cType]) < 2 {
+			continue
+		}
+		catalogs = append(catalogs, stremio.Catalog{
+			Type: cType,
+			Id:   combinedCatalogIdPrefix + cType,
+			Name: "Combined",
+		})
+	}
+	return catalogs
+}
+
+func getCatalogItemKey(item *stremio.MetaPreview) string {
+	if strings.HasPrefix(item.Id, "tt") {
+		imdbId, _, _ := strings.Cut(item.Id, ":")
+		return imdbId
+	}
+	return item.Id
+}
+
+// combineCatalogItems picks the items from each list in turn, duplicates are
+// dropped by IMDB id when deduplicating.
+func combineCatalogItems(lists [][]stremio.MetaPreview, mode CatalogCombineMode) []stremio.MetaPreview {
+	total := 0
+	maxLen := 0
+	for i := range lists {
+		total += len(lists[i])
+		maxLen = max(maxLen, len(lists[i]))
+	}
+
+	items := make([]stremio.MetaPreview, 0, total)
+	seen := map[string]struct{}{}
+	for pos := range maxLen {
+		for i := range lists {
+			if pos >= len(lists[i]) {
+				continue
+			}
+			item := lists[i][pos]
+			if mode == CatalogCombineModeDedupe {
+				key := getCatalogItemKey(&item)
+				if _, found := seen[key]; found {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseCatalogId(id string, ud *UserData) (idx int, catalogId string, err error) {
 	if len(ud.Upstreams) == 1 {
 		return 0, id, nil
@@ -45,22 +157,79 @@ func (ud UserData) fetchAddonCatalog(ctx *context.StoreContext, w http.ResponseW
 	})
 }
 
-func (ud UserData) fetchCatalog(ctx *context.StoreContext, w http.ResponseWriter, r *http.Request, rType, id, extra string) (*stremio.CatalogHandlerResponse, error) {
-	idx, catalogId, err := parseCatalogId(id, &ud)
-	if err != nil {
-		SendError(w, r, err)
-		return nil, err
+func (ud UserData) fetchCombinedCatalog(ctx *context.StoreContext, rType string) (*stremio.CatalogHandlerResponse, error) {
+	log := ctx.Log
+
+	manifests, errs := ud.getUpstreamManifests(ctx)
+	if errs != nil {
+		return nil, errors.Join(errs...)
 	}
 
-	res, err := addon.FetchCatalog(&stremio_addon.FetchCatalogParams{
-		BaseURL:  ud.Upstreams[idx].baseUrl,
-		Type:     rType,
-		Id:       catalogId,
-		Extra:    extra,
-		ClientIP: ctx.ClientIP,
-	})
-	if err != nil {
-		return nil, err
+	_, sourcesByType := getCombinedCatalogSources(manifests)
+	sources := sourcesByType[rType]
+
+	lists := make([][]stremio.MetaPreview, len(sources))
+	errs = make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			source := sources[i]
+			res, err := addon.FetchCatalog(&stremio_addon.FetchCatalogParams{
+				BaseURL:  ud.Upstreams[source.idx].baseUrl,
+				Type:     rType,
+				Id:       source.catalogId,
+				ClientIP: ctx.ClientIP,
+			})
+			lists[i] = res.Data.Metas
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] != nil {
+			log.Error("failed to fetch catalog", "error", errs[i], "catalog_id", sources[i].catalogId)
+			lists[i] = nil
+		}
+	}
+
+	return &stremio.CatalogHandlerResponse{
+		Metas: combineCatalogItems(lists, ud.CombineCatalogs),
+	}, nil
+}
+
+func (ud UserData) fetchCatalog(ctx *context.StoreContext, w http.ResponseWriter, r *http.Request, rType, id, extra string) (*stremio.CatalogHandlerResponse, error) {
+	var res *stremio.CatalogHandlerResponse
+
+	if ud.CombineCatalogs != CatalogCombineModeNone && len(ud.Upstreams) > 1 && strings.HasPrefix(id, combinedCatalogIdPrefix) {
+		if extra != "" {
+			return &stremio.CatalogHandlerResponse{Metas: []stremio.MetaPreview{}}, nil
+		}
+		combinedRes, err := ud.fetchCombinedCatalog(ctx, rType)
+		if err != nil {
+			return nil, err
+		}
+		res = combinedRes
+	} else {
+		idx, catalogId, err := parseCatalogId(id, &ud)
+		if err != nil {
+			return nil, err
+		}
+
+		upstreamRes, err := addon.FetchCatalog(&stremio_addon.FetchCatalogParams{
+			BaseURL:  ud.Upstreams[idx].baseUrl,
+			Type:     rType,
+			Id:       catalogId,
+			Extra:    extra,
+			ClientIP: ctx.ClientIP,
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = &upstreamRes.Data
 	}
 
 	rpdbPosterBaseUrl := ""
@@ -68,12 +237,12 @@ func (ud UserData) fetchCatalog(ctx *context.StoreContext, w http.ResponseWriter
 		rpdbPosterBaseUrl = "https://api.ratingposterdb.com/" + ud.RPDBAPIKey + "/imdb/poster-default/"
 	}
 
-	for i := range res.Data.Metas {
-		item := &res.Data.Metas[i]
+	for i := range res.Metas {
+		item := &res.Metas[i]
 		if rpdbPosterBaseUrl != "" && strings.HasPrefix(item.Id, "tt") {
 			item.Poster = rpdbPosterBaseUrl + item.Id + ".jpg?fallback=true"
 		}
 	}
 
-	return &res.Data, nil
+	return res, nil
 }
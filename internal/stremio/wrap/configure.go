@@ -32,6 +32,24 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 			if ud.CachedOnly {
 				conf.Default = "checked"
 			}
+		case "merge_meta":
+			if ud.MergeMeta {
+				conf.Default = "checked"
+			}
+		case "combine_catalogs":
+			conf.Default = string(ud.CombineCatalogs)
+		case "sub_langs":
+			conf.Default = ud.SubtitleLangs
+		case "sub_langs_only":
+			if ud.SubtitleLangsOnly {
+				conf.Default = "checked"
+			}
+		case "sub_proxy":
+			if ud.SubtitleProxy {
+				conf.Default = "checked"
+			}
+		case "compat":
+			conf.Default = string(ud.CompatStream)
 		}
 	}
 
@@ -123,7 +141,7 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 				}
 				if up.ExtractorError == "" {
 					if value == "" {
-						if err := extractorStore.Del(id); err != nil {
+						if err := TransformerExtractors.Delete(id); err != nil {
 							LogError(r, "failed to delete extractor", err)
 							up.ExtractorError = "Failed to delete extractor"
 						}
@@ -142,7 +160,7 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 							}
 						}
 					} else {
-						if err := extractorStore.Set(id, value); err != nil {
+						if _, err := TransformerExtractors.Save(id, value); err != nil {
 							LogError(r, "failed to save extractor", err)
 							up.ExtractorError = "Failed to save extractor"
 						} else {
@@ -200,7 +218,7 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 				}
 				if td.TemplateError.IsEmpty() {
 					if value.Name == "" && value.Description == "" {
-						if err := templateStore.Del(id); err != nil {
+						if err := TransformerTemplates.Delete(id); err != nil {
 							LogError(r, "failed to delete template", err)
 							td.TemplateError.Name = "Failed to delete template"
 							td.TemplateError.Description = "Failed to delete template"
@@ -215,7 +233,7 @@ func handleConfigure(w http.ResponseWriter, r *http.Request) {
 						td.TemplateId = ""
 						td.Template = stremio_transformer.StreamTemplateBlob{}
 					} else {
-						if err := templateStore.Set(id, value); err != nil {
+						if _, err := TransformerTemplates.Save(id, value); err != nil {
 							LogError(r, "failed to save template", err)
 							td.TemplateError.Name = "Failed to save template"
 							td.TemplateError.Description = "Failed to save template"
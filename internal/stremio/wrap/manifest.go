@@ -158,6 +158,12 @@ func GetManifest(r *http.Request, upstreamManifests []stremio.Manifest, ud *User
 		}
 	}
 
+	if ud.CombineCatalogs != CatalogCombineModeNone {
+		if combinedCatalogs := getCombinedCatalogs(upstreamManifests); len(combinedCatalogs) > 0 {
+			manifest.Catalogs = append(combinedCatalogs, manifest.Catalogs...)
+		}
+	}
+
 	for rName := range resourceByName {
 		r := resourceByName[rName]
 
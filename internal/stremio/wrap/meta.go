@@ -1,7 +1,9 @@
 package stremio_wrap
 
 import (
+	"errors"
 	"net/http"
+	"sync"
 
 	"github.com/rodezfranco/stremthru/internal/context"
 	"github.com/rodezfranco/stremthru/internal/shared"
@@ -9,6 +11,149 @@ import (
 	"github.com/rodezfranco/stremthru/stremio"
 )
 
+func mergeMetaLinks(a, b []stremio.MetaLink) []stremio.MetaLink {
+	seen := map[stremio.MetaLink]struct{}{}
+	links := make([]stremio.MetaLink, 0, len(a)+len(b))
+	for _, list := range [][]stremio.MetaLink{a, b} {
+		for _, link := range list {
+			if _, found := seen[link]; found {
+				continue
+			}
+			seen[link] = struct{}{}
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func mergeMetaVideos(a, b []stremio.MetaVideo) []stremio.MetaVideo {
+	idxById := make(map[string]int, len(a))
+	videos := make([]stremio.MetaVideo, 0, len(a)+len(b))
+	for i := range a {
+		idxById[a[i].Id] = i
+		videos = append(videos, a[i])
+	}
+	for i := range b {
+		video := &b[i]
+		if idx, found := idxById[video.Id]; found {
+			existing := &videos[idx]
+			if existing.Thumbnail == "" {
+				existing.Thumbnail = video.Thumbnail
+			}
+			if existing.Overview == "" {
+				existing.Overview = video.Overview
+			}
+			existing.Streams = append(existing.Streams, video.Streams...)
+			continue
+		}
+		idxById[video.Id] = len(videos)
+		videos = append(videos, *video)
+	}
+	return videos
+}
+
+// mergeMeta fills the missing fields of the first meta from the rest, in
+// upstream order. Videos and links are combined.
+func mergeMeta(metas []stremio.Meta) stremio.Meta {
+	merged := metas[0]
+	for i := 1; i < len(metas); i++ {
+		m := &metas[i]
+		if merged.Name == "" {
+			merged.Name = m.Name
+		}
+		if merged.Poster == "" {
+			merged.Poster = m.Poster
+			merged.PosterShape = m.PosterShape
+		}
+		if merged.Background == "" {
+			merged.Background = m.Background
+		}
+		if merged.Logo == "" {
+			merged.Logo = m.Logo
+		}
+		if merged.Description == "" {
+			merged.Description = m.Description
+		}
+		if merged.ReleaseInfo == "" {
+			merged.ReleaseInfo = m.ReleaseInfo
+		}
+		if merged.IMDBRating == "" {
+			merged.IMDBRating = m.IMDBRating
+		}
+		if merged.Released == nil {
+			merged.Released = m.Released
+		}
+		if merged.Runtime == "" {
+			merged.Runtime = m.Runtime
+		}
+		if merged.Language == "" {
+			merged.Language = m.Language
+		}
+		if merged.Country == "" {
+			merged.Country = m.Country
+		}
+		if merged.Website == "" {
+			merged.Website = m.Website
+		}
+		if len(merged.Genres) == 0 {
+			merged.Genres = m.Genres
+		}
+		if len(merged.Trailers) == 0 {
+			merged.Trailers = m.Trailers
+		}
+		if merged.BehaviorHints == nil {
+			merged.BehaviorHints = m.BehaviorHints
+		}
+		merged.Links = mergeMetaLinks(merged.Links, m.Links)
+		merged.Videos = mergeMetaVideos(merged.Videos, m.Videos)
+	}
+	return merged
+}
+
+func (ud UserData) fetchMergedMeta(ctx *context.StoreContext, upstreams []UserDataUpstream, rType, id string) (*stremio.MetaHandlerResponse, error) {
+	log := ctx.Log
+
+	metas := make([]stremio.Meta, len(upstreams))
+	errs := make([]error, len(upstreams))
+
+	var wg sync.WaitGroup
+	for i := range upstreams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := addon.FetchMeta(&stremio_addon.FetchMetaParams{
+				BaseURL:  upstreams[i].baseUrl,
+				Type:     rType,
+				Id:       id,
+				ClientIP: ctx.ClientIP,
+			})
+			metas[i] = res.Data.Meta
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	found := []stremio.Meta{}
+	for i := range metas {
+		if errs[i] != nil {
+			log.Error("failed to fetch meta", "error", errs[i], "hostname", upstreams[i].baseUrl.Hostname())
+			continue
+		}
+		if metas[i].Id == "" {
+			continue
+		}
+		found = append(found, metas[i])
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &stremio.MetaHandlerResponse{
+		Meta: mergeMeta(found),
+	}, nil
+}
+
 func (ud UserData) fetchMeta(ctx *context.StoreContext, w http.ResponseWriter, r *http.Request, rType, id, extra string) error {
 	upstreams, err := ud.getUpstreams(ctx, stremio.ResourceNameMeta, rType, id)
 	if err != nil {
@@ -20,6 +165,19 @@ func (ud UserData) fetchMeta(ctx *context.StoreContext, w http.ResponseWriter, r
 		return nil
 	}
 
+	if ud.MergeMeta && len(upstreams) > 1 {
+		res, err := ud.fetchMergedMeta(ctx, upstreams, rType, id)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			shared.ErrorNotFound(r).Send(w, r)
+			return nil
+		}
+		SendResponse(w, r, 200, res)
+		return nil
+	}
+
 	upstream := upstreams[0]
 
 	addon.ProxyResource(w, r, &stremio_addon.ProxyResourceParams{
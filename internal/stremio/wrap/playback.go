@@ -10,6 +10,7 @@ import (
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/buddy"
 	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_hls"
 	"github.com/rodezfranco/stremthru/internal/server"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	store_video "github.com/rodezfranco/stremthru/internal/store/video"
@@ -213,6 +214,15 @@ func handleStrem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	link := strem.link
+	if variant := content_proxy_hls.Variant(query.Get("hls")); variant.IsValid() {
+		if hlsLink, err := shared.GetHLSProxyLink(link, variant); err == nil {
+			link = hlsLink
+		} else {
+			log.Debug("failed to get hls link", "error", err)
+		}
+	}
+
 	log.Debug("redirecting to stream link")
-	http.Redirect(w, r, strem.link, http.StatusFound)
+	http.Redirect(w, r, link, http.StatusFound)
 }
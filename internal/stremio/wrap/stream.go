@@ -25,6 +25,21 @@ import (
 
 var lazyPullTorz = config.Stremio.Torz.LazyPull
 
+// getCompatStream returns a copy of the stream, for the HLS variant.
+func getCompatStream(stream *stremio.Stream, url string) stremio.Stream {
+	compat := *stream
+	compat.URL = url
+	compat.Name = "🎞️ " + stream.Name
+	if stream.BehaviorHints != nil {
+		behaviorHints := *stream.BehaviorHints
+		behaviorHints.NotWebReady = false
+		behaviorHints.ProxyHeaders = nil
+		behaviorHints.Filename = ""
+		compat.BehaviorHints = &behaviorHints
+	}
+	return compat
+}
+
 func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType, id string) (*stremio.StreamHandlerResponse, error) {
 	log := ctx.Log
 
@@ -109,13 +124,12 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 		go func() {
 			defer wg.Done()
 			up := &upstreams[i]
-			res, err := addon.FetchStream(&stremio_addon.FetchStreamParams{
+			streams, isFromCache, err := getUpstreamStreams(&stremio_addon.FetchStreamParams{
 				BaseURL:  up.baseUrl,
 				Type:     rType,
 				Id:       id,
 				ClientIP: ctx.ClientIP,
 			})
-			streams := res.Data.Streams
 			wstreams := make([]WrappedStream, len(streams))
 			errs[idx] = err
 			tInfos := []torrent_info.TorrentInfoInsertData{}
@@ -131,7 +145,7 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 					}
 					for i := range streams {
 						stream := streams[i]
-						if isImdbStremId {
+						if isImdbStremId && !isFromCache {
 							if cData := torrent_info.ExtractCreateDataFromStream(addonHostname, stremId, &stream); cData != nil {
 								tInfos = append(tInfos, *cData)
 							}
@@ -180,10 +194,19 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 		allStreams = dedupeStreams(allStreams)
 	}
 
+	filter, err := stremio_transformer.StreamFilterBlob(ud.Filter).Parse()
+	if err != nil {
+		log.Warn("failed to parse stream filter", "error", err)
+		filter = nil
+	}
+	allStreams = stremio_transformer.FilterStreams(allStreams, filter)
+
 	if template != nil {
 		stremio_transformer.SortStreams(allStreams, ud.Sort)
 	}
 
+	allStreams = stremio_transformer.LimitStreams(allStreams, filter)
+
 	if !ud.IncludeTorz {
 		allStreams = dedupeStreams(allStreams)
 	}
@@ -246,11 +269,17 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 				stream.URL = surl.String()
 				stream.Name = "⚡ [" + storeCode + "] " + stream.Name
 
-				if ctx.IsProxyAuthorized && config.StoreContentProxy.IsEnabled(string(ud.GetStoreByCode(storeCode).Store.GetName())) {
+				hasContentProxy := ctx.IsProxyAuthorized && config.StoreContentProxy.IsEnabled(string(ud.GetStoreByCode(storeCode).Store.GetName()))
+				if hasContentProxy {
 					stream.Name = "✨ " + stream.Name
 				}
 
 				cachedStreams = append(cachedStreams, *stream.Stream)
+
+				if hasContentProxy && ud.hasCompatStream() {
+					surl.RawQuery += "&hls=" + string(ud.CompatStream)
+					cachedStreams = append(cachedStreams, getCompatStream(stream.Stream, surl.String()))
+				}
 			} else if !ud.CachedOnly {
 				surlRawQuery := surl.RawQuery
 				stores := ud.GetStores()
@@ -275,6 +304,7 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 				}
 			}
 		} else if stream.URL != "" {
+			compatURL := ""
 			if !stream.noContentProxy {
 				var headers map[string]string
 				if stream.BehaviorHints != nil && stream.BehaviorHints.ProxyHeaders != nil && stream.BehaviorHints.ProxyHeaders.Request != nil {
@@ -285,13 +315,21 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 					if url, err := shared.CreateProxyLink(r, stream.URL, headers, config.TUNNEL_TYPE_AUTO, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, ""); err == nil && url != stream.URL {
 						stream.URL = url
 						stream.Name = "✨ " + stream.Name
+						if ud.hasCompatStream() {
+							if url, err := shared.GetHLSProxyLink(url, ud.CompatStream); err == nil {
+								compatURL = url
+							}
+						}
 					}
 				}
 			}
-			if stream.r == nil || stream.r.Store.IsCached {
-				cachedStreams = append(cachedStreams, *stream.Stream)
-			} else {
-				uncachedStreams = append(uncachedStreams, *stream.Stream)
+			streams := &cachedStreams
+			if stream.r != nil && !stream.r.Store.IsCached {
+				streams = &uncachedStreams
+			}
+			*streams = append(*streams, *stream.Stream)
+			if compatURL != "" {
+				*streams = append(*streams, getCompatStream(stream.Stream, compatURL))
 			}
 		}
 	}
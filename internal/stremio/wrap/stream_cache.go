@@ -0,0 +1,127 @@
+package stremio_wrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/config"
+	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+var streamCacheTTL = config.Stremio.Wrap.StreamCacheTTL
+var streamCacheStaleTTL = config.Stremio.Wrap.StreamCacheStaleTTL
+var streamCacheErrorTTL = config.Stremio.Wrap.StreamCacheErrorTTL
+var upstreamTimeout = config.Stremio.Wrap.UpstreamTimeout
+
+var errUpstreamTimeout = errors.New("upstream timed out")
+
+type upstreamStreamCacheEntry struct {
+	Streams   []stremio.Stream `json:"streams"`
+	Error     string           `json:"error,omitempty"`
+	FetchedAt time.Time        `json:"fetched_at"`
+}
+
+func (e *upstreamStreamCacheEntry) isFresh() bool {
+	return time.Since(e.FetchedAt) < streamCacheTTL
+}
+
+func (e *upstreamStreamCacheEntry) isExpired() bool {
+	return time.Since(e.FetchedAt) >= streamCacheTTL+streamCacheStaleTTL
+}
+
+var upstreamStreamCache = cache.NewCache[upstreamStreamCacheEntry](&cache.CacheConfig{
+	Name:     "stremio:wrap:upstream-stream",
+	Lifetime: streamCacheTTL + streamCacheStaleTTL,
+})
+
+var upstreamStreamRevalidating sync.Map
+
+// client ip is part of the key, as it is forwarded to the upstream, which
+// can respond with links locked to it.
+func getUpstreamStreamCacheKey(baseUrl *url.URL, rType, id, clientIp string) string {
+	hash := sha256.Sum256([]byte(baseUrl.JoinPath("stream", rType, id).String() + "\n" + clientIp))
+	return hex.EncodeToString(hash[:])
+}
+
+type upstreamStreamResult struct {
+	streams []stremio.Stream
+	err     error
+}
+
+// fetchUpstreamStreams fetches the streams from the upstream and caches them.
+// Failed fetches are also cached for a shorter duration, unless `isRevalidate`,
+// so that the stale entry keeps being served.
+func fetchUpstreamStreams(cacheKey string, params *stremio_addon.FetchStreamParams, isRevalidate bool) upstreamStreamResult {
+	res, err := addon.FetchStream(params)
+	if streamCacheTTL == 0 {
+		return upstreamStreamResult{streams: res.Data.Streams, err: err}
+	}
+	entry := upstreamStreamCacheEntry{FetchedAt: time.Now()}
+	if err != nil {
+		if streamCacheErrorTTL > 0 && !isRevalidate {
+			entry.Error = err.Error()
+			if cErr := upstreamStreamCache.AddWithLifetime(cacheKey, entry, streamCacheErrorTTL); cErr != nil {
+				log.Warn("failed to cache upstream stream error", "error", cErr)
+			}
+		}
+		return upstreamStreamResult{err: err}
+	}
+	entry.Streams = res.Data.Streams
+	if cErr := upstreamStreamCache.Add(cacheKey, entry); cErr != nil {
+		log.Warn("failed to cache upstream streams", "error", cErr)
+	}
+	return upstreamStreamResult{streams: entry.Streams}
+}
+
+func revalidateUpstreamStreams(cacheKey string, params *stremio_addon.FetchStreamParams) {
+	if _, loaded := upstreamStreamRevalidating.LoadOrStore(cacheKey, struct{}{}); loaded {
+		return
+	}
+	go func() {
+		defer upstreamStreamRevalidating.Delete(cacheKey)
+		if res := fetchUpstreamStreams(cacheKey, params, true); res.err != nil {
+			log.Warn("failed to revalidate upstream streams", "error", res.err, "hostname", params.BaseURL.Hostname())
+		}
+	}()
+}
+
+// getUpstreamStreams serves the streams from cache when possible. Stale
+// entries are served while being revalidated in background, and fetches
+// taking longer than the timeout are left to populate the cache for the
+// next request.
+func getUpstreamStreams(params *stremio_addon.FetchStreamParams) (streams []stremio.Stream, isFromCache bool, err error) {
+	cacheKey := getUpstreamStreamCacheKey(params.BaseURL, params.Type, params.Id, params.ClientIP)
+
+	if streamCacheTTL > 0 {
+		entry := upstreamStreamCacheEntry{}
+		if upstreamStreamCache.Get(cacheKey, &entry) && (entry.Error != "" || !entry.isExpired()) {
+			if entry.Error != "" {
+				return nil, true, errors.New(entry.Error)
+			}
+			if !entry.isFresh() {
+				revalidateUpstreamStreams(cacheKey, params)
+			}
+			return entry.Streams, true, nil
+		}
+	}
+
+	resultCh := make(chan upstreamStreamResult, 1)
+	go func() {
+		resultCh <- fetchUpstreamStreams(cacheKey, params, false)
+	}()
+
+	timer := time.NewTimer(upstreamTimeout)
+	defer timer.Stop()
+	select {
+	case res := <-resultCh:
+		return res.streams, false, res.err
+	case <-timer.C:
+		return nil, false, errUpstreamTimeout
+	}
+}
@@ -0,0 +1,226 @@
+package stremio_wrap
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/shared"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const maxSubtitleSize = 10 * 1024 * 1024
+
+var subtitleCache = cache.NewCache[string](&cache.CacheConfig{
+	Name:     "stremio:wrap:subtitle",
+	Lifetime: 6 * time.Hour,
+})
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeSubtitleEncoding converts the subtitle content to UTF-8. The
+// encoding is detected from BOM, then the hinted encoding is used, and
+// invalid UTF-8 content falls back to Windows-1252.
+func normalizeSubtitleEncoding(content []byte, encodingHint string) (string, error) {
+	var enc encoding.Encoding
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return string(content[len(utf8BOM):]), nil
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}), bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	case utf8.Valid(content):
+		return string(content), nil
+	case encodingHint != "":
+		if hinted, err := htmlindex.Get(encodingHint); err == nil {
+			enc = hinted
+		}
+	}
+	if enc == nil {
+		enc = charmap.Windows1252
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+var srtTimingPattern = regexp.MustCompile(`(\d{1,2}:\d{2}:\d{2}),(\d{3})`)
+
+// convertSRTToVTT converts SRT subtitle to WebVTT, other formats are returned
+// as is.
+func convertSRTToVTT(content string) (string, bool) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	if strings.HasPrefix(strings.TrimSpace(content), "WEBVTT") {
+		return content, true
+	}
+	if !strings.Contains(content, "-->") || strings.Contains(content, "[Script Info]") {
+		return content, false
+	}
+
+	var vtt strings.Builder
+	vtt.Grow(len(content) + 8)
+	vtt.WriteString("WEBVTT\n\n")
+	for line := range strings.Lines(strings.TrimLeft(content, "\n")) {
+		if strings.Contains(line, "-->") {
+			line = srtTimingPattern.ReplaceAllString(line, "$1.$2")
+		}
+		vtt.WriteString(line)
+	}
+	return vtt.String(), true
+}
+
+// signSubtitleUrl signs the subtitle url with the user's proxy auth
+// password, so that only the urls from the subtitles response are served.
+func signSubtitleUrl(password string, subUrl string) string {
+	mac := hmac.New(sha256.New, []byte(password))
+	mac.Write([]byte(subUrl))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func isValidSubtitleUrlSignature(password string, subUrl string, sig string) bool {
+	return hmac.Equal([]byte(signSubtitleUrl(password, subUrl)), []byte(sig))
+}
+
+var errSubtitleAddressNotAllowed = errors.New("address not allowed")
+
+func isAllowedSubtitleIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// subtitleHTTPClient connects directly, and refuses to connect to loopback,
+// private and link-local addresses, including after redirects.
+var subtitleHTTPClient = func() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isAllowedSubtitleIP(ip) {
+				return errSubtitleAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	transport := config.DefaultHTTPTransport.Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}
+}()
+
+func fetchSubtitle(subUrl *url.URL, encodingHint string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, subUrl.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := subtitleHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", errors.New("unexpected status code: " + strconv.Itoa(res.StatusCode))
+	}
+	content, err := io.ReadAll(io.LimitReader(res.Body, maxSubtitleSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(content) > maxSubtitleSize {
+		return "", errors.New("subtitle too large")
+	}
+	return normalizeSubtitleEncoding(content, encodingHint)
+}
+
+func handleSubtitle(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodHead) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ud, err := getUserData(r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if !ud.SubtitleProxy {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	ctx, err := ud.GetRequestContext(r)
+	if err != nil || !ctx.IsProxyAuthorized {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	rawSubUrl, err := base64.RawURLEncoding.DecodeString(r.PathValue("subUrl"))
+	if err != nil {
+		shared.ErrorBadRequest(r, "invalid subtitle url").Send(w, r)
+		return
+	}
+	subUrl, err := url.Parse(string(rawSubUrl))
+	if err != nil || (subUrl.Scheme != "http" && subUrl.Scheme != "https") {
+		shared.ErrorBadRequest(r, "invalid subtitle url").Send(w, r)
+		return
+	}
+
+	if !isValidSubtitleUrlSignature(ctx.ProxyAuthPassword, string(rawSubUrl), r.URL.Query().Get("sig")) {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+
+	encodingHint := r.URL.Query().Get("enc")
+
+	hash := sha256.Sum256([]byte(subUrl.String() + ":" + encodingHint))
+	cacheKey := hex.EncodeToString(hash[:])
+
+	content := ""
+	if !subtitleCache.Get(cacheKey, &content) {
+		content, err = fetchSubtitle(subUrl, encodingHint)
+		if err != nil {
+			LogError(r, "failed to fetch subtitle", err)
+			shared.ErrorBadGateway(r, "failed to fetch subtitle").Send(w, r)
+			return
+		}
+		if err := subtitleCache.Add(cacheKey, content); err != nil {
+			LogError(r, "failed to cache subtitle", err)
+		}
+	}
+
+	content, isVTT := convertSRTToVTT(content)
+
+	if isVTT {
+		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("Cache-Control", "public, max-age=21600")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		io.WriteString(w, content)
+	}
+}
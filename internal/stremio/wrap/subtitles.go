@@ -1,14 +1,84 @@
 package stremio_wrap
 
 import (
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/rodezfranco/stremthru/internal/context"
+	"github.com/rodezfranco/stremthru/internal/shared"
 	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
 	"github.com/rodezfranco/stremthru/stremio"
+	"golang.org/x/text/language"
 )
 
-func (ud UserData) fetchSubtitles(ctx *context.StoreContext, rType, id, extra string) (*stremio.SubtitlesHandlerResponse, error) {
+// normalizeSubtitleLang converts the language to ISO 639-1 code when
+// possible, so that `eng`, `en` and `en-US` are treated the same.
+func normalizeSubtitleLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if tag, err := language.Parse(lang); err == nil {
+		base, _ := tag.Base()
+		return base.String()
+	}
+	return lang
+}
+
+func parseSubtitleLangs(value string) []string {
+	langs := []string{}
+	for lang := range strings.SplitSeq(value, ",") {
+		if lang = normalizeSubtitleLang(lang); lang != "" && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// processSubtitles drops duplicate subtitles and orders them by the preferred
+// languages. With onlyPreferred, subtitles in other languages are dropped.
+func processSubtitles(subtitles []stremio.Subtitle, preferredLangs []string, onlyPreferred bool) []stremio.Subtitle {
+	seen := map[string]struct{}{}
+	items := make([]stremio.Subtitle, 0, len(subtitles))
+	ranks := make([]int, 0, len(subtitles))
+	for i := range subtitles {
+		sub := subtitles[i]
+		if sub.Url == "" {
+			continue
+		}
+		lang := normalizeSubtitleLang(sub.Lang)
+		rank := slices.Index(preferredLangs, lang)
+		if rank == -1 {
+			if onlyPreferred && len(preferredLangs) > 0 {
+				continue
+			}
+			rank = len(preferredLangs)
+		}
+		key := lang + ":" + sub.Url
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+		items = append(items, sub)
+		ranks = append(ranks, rank)
+	}
+
+	indices := make([]int, len(items))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(a, b int) int {
+		return ranks[a] - ranks[b]
+	})
+
+	sorted := make([]stremio.Subtitle, len(items))
+	for i, idx := range indices {
+		sorted[i] = items[idx]
+	}
+	return sorted
+}
+
+func (ud UserData) fetchSubtitles(ctx *context.StoreContext, r *http.Request, rType, id, extra string) (*stremio.SubtitlesHandlerResponse, error) {
 	log := ctx.Log
 
 	upstreams, err := ud.getUpstreams(ctx, stremio.ResourceNameSubtitles, rType, id)
@@ -49,6 +119,24 @@ func (ud UserData) fetchSubtitles(ctx *context.StoreContext, rType, id, extra st
 		subtitles = append(subtitles, chunks[i]...)
 	}
 
+	subtitles = processSubtitles(subtitles, parseSubtitleLangs(ud.SubtitleLangs), ud.SubtitleLangsOnly)
+
+	if ud.SubtitleProxy && ctx.IsProxyAuthorized {
+		baseUrl := shared.ExtractRequestBaseURL(r).JoinPath("/stremio/wrap/" + ud.GetEncoded() + "/_/subtitle/")
+		for i := range subtitles {
+			sub := &subtitles[i]
+			surl := baseUrl.JoinPath(base64.RawURLEncoding.EncodeToString([]byte(sub.Url)), "subtitle.vtt")
+			query := surl.Query()
+			query.Set("sig", signSubtitleUrl(ctx.ProxyAuthPassword, sub.Url))
+			if sub.SubEncoding != "" {
+				query.Set("enc", sub.SubEncoding)
+				sub.SubEncoding = ""
+			}
+			surl.RawQuery = query.Encode()
+			sub.Url = surl.String()
+		}
+	}
+
 	return &stremio.SubtitlesHandlerResponse{
 		Subtitles: subtitles,
 	}, nil
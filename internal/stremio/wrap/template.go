@@ -2,11 +2,15 @@ package stremio_wrap
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
 	"net/http"
 	"regexp"
+	"time"
 
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_hls"
+	stremio_addon "github.com/rodezfranco/stremthru/internal/stremio/addon"
 	"github.com/rodezfranco/stremthru/internal/stremio/configure"
 	stremio_shared "github.com/rodezfranco/stremthru/internal/stremio/shared"
 	stremio_template "github.com/rodezfranco/stremthru/internal/stremio/template"
@@ -38,6 +42,40 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 				Type:  configure.ConfigTypeCheckbox,
 				Title: "Only Show Cached Content",
 			},
+			{
+				Key:         "merge_meta",
+				Type:        configure.ConfigTypeCheckbox,
+				Title:       "Merge Meta",
+				Description: "Merge meta from all matching upstreams, instead of using the first one",
+			},
+			{
+				Key:         "combine_catalogs",
+				Type:        configure.ConfigTypeSelect,
+				Title:       "Combined Catalogs",
+				Description: "Add a catalog per type combining the catalogs from all upstreams",
+				Options: []configure.ConfigOption{
+					{Value: string(CatalogCombineModeNone), Label: "Disabled"},
+					{Value: string(CatalogCombineModeInterleave), Label: "Interleave"},
+					{Value: string(CatalogCombineModeDedupe), Label: "Interleave & Deduplicate by IMDB ID"},
+				},
+			},
+			{
+				Key:         "sub_langs",
+				Type:        configure.ConfigTypeText,
+				Title:       "Subtitle Languages",
+				Description: "Comma separated language codes, in order of preference, e.g. <code>en,hi</code>",
+			},
+			{
+				Key:   "sub_langs_only",
+				Type:  configure.ConfigTypeCheckbox,
+				Title: "Only Show Subtitles in Preferred Languages",
+			},
+			{
+				Key:         "sub_proxy",
+				Type:        configure.ConfigTypeCheckbox,
+				Title:       "Proxy Subtitles",
+				Description: "Serve subtitles through StremThru, converted to WebVTT and UTF-8",
+			},
 		},
 		Script: configure.GetScriptStoreTokenDescription("", ""),
 
@@ -46,7 +84,15 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 			Type:        "text",
 			Default:     ud.Sort,
 			Title:       "Stream Sort",
-			Description: "Comma separated fields: <code>resolution</code>, <code>quality</code>, <code>size</code>, <code>hdr</code>. Prefix with <code>-</code> for reverse sort. Default: <code>" + stremio_transformer.StreamDefaultSortConfig + "</code>",
+			Description: template.HTML(stremio_transformer.GetSortDescription()),
+		},
+
+		FilterConfig: configure.Config{
+			Key:         "filter",
+			Type:        configure.ConfigTypeTextarea,
+			Default:     ud.Filter,
+			Title:       "Stream Filter",
+			Description: template.HTML(stremio_transformer.GetFilterDescription()),
 		},
 
 		RPDBAPIKey: configure.Config{
@@ -62,6 +108,20 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 		TemplateIds:  []string{},
 	}
 
+	if config.ContentProxyHLS.IsEnabled() {
+		td.Configs = append(td.Configs, configure.Config{
+			Key:         "compat",
+			Type:        configure.ConfigTypeSelect,
+			Title:       "Compatible Streams",
+			Description: "Add HLS variant for proxied streams, for browsers and devices that can't play MKV / HEVC",
+			Options: []configure.ConfigOption{
+				{Value: "", Label: "Disabled"},
+				{Value: string(content_proxy_hls.VariantRemux), Label: "Remux"},
+				{Value: string(content_proxy_hls.VariantAAC), Label: "Remux & Transcode Audio to AAC"},
+			},
+		})
+	}
+
 	if cookie, err := stremio_shared.GetAdminCookieValue(w, r); err == nil && !cookie.IsExpired {
 		td.IsAuthed = config.ProxyAuthPassword.GetPassword(cookie.User()) == cookie.Pass()
 	}
@@ -112,6 +172,10 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 		}
 	}
 
+	if _, err := stremio_transformer.StreamFilterBlob(ud.Filter).Parse(); err != nil {
+		td.FilterConfig.Error = err.Error()
+	}
+
 	hasExtractor := false
 
 	for _, up := range ud.Upstreams {
@@ -149,6 +213,8 @@ func getTemplateData(ud *UserData, w http.ResponseWriter, r *http.Request) *Temp
 			NoContentProxy:   up.NoContentProxy,
 			ReconfigureStore: up.ReconfigureStore,
 		})
+		upstream := &td.Upstreams[len(td.Upstreams)-1]
+		upstream.Health, upstream.IsUnhealthy = getUpstreamHealthStatus(up.URL)
 	}
 
 	if len(td.Upstreams) == 0 {
@@ -205,6 +271,31 @@ type UpstreamAddon struct {
 	ExtractorError   string
 	NoContentProxy   bool
 	ReconfigureStore bool
+	Health           string
+	IsUnhealthy      bool
+}
+
+func getUpstreamHealthStatus(manifestUrl string) (status string, isUnhealthy bool) {
+	baseUrl, err := stremio_addon.ExtractBaseURL(manifestUrl)
+	if err != nil {
+		return "", false
+	}
+	h := stremio_addon.GetUpstreamHealth(baseUrl)
+	if h.RequestCount == 0 {
+		return "", false
+	}
+	status = "Healthy"
+	switch h.State {
+	case stremio_addon.CircuitStateOpen:
+		status = "Unavailable, skipped until " + h.OpenUntil.UTC().Format(time.TimeOnly) + " UTC"
+	case stremio_addon.CircuitStateHalfOpen:
+		status = "Recovering"
+	}
+	status += fmt.Sprintf(" · Latency: %s · Error Rate: %.0f%%", h.Latency.Round(time.Millisecond), h.ErrorRate*100)
+	if h.LastError != "" && !h.IsHealthy() {
+		status += " · Last Error: " + h.LastError
+	}
+	return status, !h.IsHealthy()
 }
 
 type StoreConfig struct {
@@ -246,6 +337,7 @@ type TemplateData struct {
 	Template      stremio_transformer.StreamTemplateBlob
 	TemplateError stremio_transformer.StreamTemplateBlob
 	SortConfig    configure.Config
+	FilterConfig  configure.Config
 	RPDBAPIKey    configure.Config
 
 	stremio_userdata.TemplateDataUserData
@@ -279,6 +371,9 @@ func (td *TemplateData) HasFieldError() bool {
 	if !td.TemplateError.IsEmpty() {
 		return true
 	}
+	if td.FilterConfig.Error != "" {
+		return true
+	}
 	for i := range td.Configs {
 		if td.Configs[i].Error != "" {
 			return true
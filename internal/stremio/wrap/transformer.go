@@ -25,20 +25,8 @@ func (ws WrappedStream) IsSortable() bool {
 	return ws.r != nil
 }
 
-func (ws WrappedStream) GetQuality() string {
-	return ws.r.Quality
-}
-
-func (ws WrappedStream) GetResolution() string {
-	return ws.r.Resolution
-}
-
-func (ws WrappedStream) GetSize() string {
-	return ws.r.Size
-}
-
-func (ws WrappedStream) GetHDR() string {
-	return strings.Join(ws.r.HDR, "|")
+func (ws WrappedStream) GetExtractorResult() *stremio_transformer.StreamExtractorResult {
+	return ws.r
 }
 
 func (st StreamTransformer) Do(stream *stremio.Stream, sType string, tryReconfigure bool) (*WrappedStream, error) {
@@ -0,0 +1,287 @@
+package stremio_wrap
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/kv"
+	stremio_transformer "github.com/rodezfranco/stremthru/internal/stremio/transformer"
+	"github.com/rodezfranco/stremthru/stremio"
+)
+
+const maxTransformerEntityVersionCount = 20
+
+var ErrTransformerEntityBuiltIn = errors.New("✨-prefixed ids are reserved")
+var ErrTransformerEntityNotFound = errors.New("transformer entity not found")
+
+type TransformerEntity[V any] struct {
+	Id        string    `json:"id"`
+	Value     V         `json:"value"`
+	Version   int       `json:"version"`
+	IsBuiltIn bool      `json:"is_builtin"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+type TransformerEntityVersion[V any] struct {
+	Version   int       `json:"version"`
+	Value     V         `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TransformerEntityStore keeps the current value in the same kv store used by
+// the configure page, and the previous values in a separate version store.
+type TransformerEntityStore[V any] struct {
+	builtIn      map[string]V
+	store        kv.KVStore[V]
+	versionStore kv.KVStore[V]
+	validate     func(value V) error
+}
+
+func newTransformerEntityStore[V any](kvType string, builtIn map[string]V, store kv.KVStore[V], validate func(value V) error) *TransformerEntityStore[V] {
+	return &TransformerEntityStore[V]{
+		builtIn: builtIn,
+		store:   store,
+		versionStore: kv.NewKVStore[V](&kv.KVStoreConfig{
+			Type: kvType + ":version",
+		}),
+		validate: validate,
+	}
+}
+
+func IsBuiltInTransformerEntityId(id string) bool {
+	return strings.HasPrefix(id, BUILTIN_TRANSFORMER_ENTITY_ID_EMOJI)
+}
+
+func getTransformerEntityVersionKey(id string, version int) string {
+	return id + ":" + strconv.Itoa(version)
+}
+
+func parseTransformerEntityVersionKey(key string) (id string, version int) {
+	idx := strings.LastIndex(key, ":")
+	if idx == -1 {
+		return key, 0
+	}
+	version, err := strconv.Atoi(key[idx+1:])
+	if err != nil {
+		return key, 0
+	}
+	return key[:idx], version
+}
+
+func (s *TransformerEntityStore[V]) Validate(value V) error {
+	return s.validate(value)
+}
+
+func (s *TransformerEntityStore[V]) List() ([]TransformerEntity[V], error) {
+	items, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.versionStore.List()
+	if err != nil {
+		return nil, err
+	}
+	versionById := map[string]int{}
+	for i := range versions {
+		id, version := parseTransformerEntityVersionKey(versions[i].Key)
+		versionById[id] = max(versionById[id], version)
+	}
+
+	entities := make([]TransformerEntity[V], 0, len(s.builtIn)+len(items))
+	for id, value := range s.builtIn {
+		entities = append(entities, TransformerEntity[V]{Id: id, Value: value, IsBuiltIn: true})
+	}
+	for i := range items {
+		item := &items[i]
+		entities = append(entities, TransformerEntity[V]{
+			Id:        item.Key,
+			Value:     item.Value,
+			Version:   versionById[item.Key],
+			UpdatedAt: item.UpdatedAt,
+		})
+	}
+	slices.SortFunc(entities, func(a, b TransformerEntity[V]) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return entities, nil
+}
+
+func (s *TransformerEntityStore[V]) Get(id string) (*TransformerEntity[V], error) {
+	if IsBuiltInTransformerEntityId(id) {
+		value, ok := s.builtIn[id]
+		if !ok {
+			return nil, ErrTransformerEntityNotFound
+		}
+		return &TransformerEntity[V]{Id: id, Value: value, IsBuiltIn: true}, nil
+	}
+
+	items, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(items, func(item kv.ParsedKV[V]) bool {
+		return item.Key == id
+	})
+	if idx == -1 {
+		return nil, ErrTransformerEntityNotFound
+	}
+	versions, err := s.ListVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	entity := &TransformerEntity[V]{
+		Id:        id,
+		Value:     items[idx].Value,
+		UpdatedAt: items[idx].UpdatedAt,
+	}
+	if len(versions) > 0 {
+		entity.Version = versions[0].Version
+	}
+	return entity, nil
+}
+
+// ListVersions returns the saved versions, latest first.
+func (s *TransformerEntityStore[V]) ListVersions(id string) ([]TransformerEntityVersion[V], error) {
+	items, err := s.versionStore.List()
+	if err != nil {
+		return nil, err
+	}
+	versions := []TransformerEntityVersion[V]{}
+	for i := range items {
+		item := &items[i]
+		if itemId, version := parseTransformerEntityVersionKey(item.Key); itemId == id && version > 0 {
+			versions = append(versions, TransformerEntityVersion[V]{
+				Version:   version,
+				Value:     item.Value,
+				CreatedAt: item.CreatedAt,
+			})
+		}
+	}
+	slices.SortFunc(versions, func(a, b TransformerEntityVersion[V]) int {
+		return b.Version - a.Version
+	})
+	return versions, nil
+}
+
+func (s *TransformerEntityStore[V]) GetVersion(id string, version int) (*TransformerEntityVersion[V], error) {
+	versions, err := s.ListVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			return &versions[i], nil
+		}
+	}
+	return nil, ErrTransformerEntityNotFound
+}
+
+// Save sets the current value and records it as a new version, older
+// versions beyond the limit are dropped.
+func (s *TransformerEntityStore[V]) Save(id string, value V) (*TransformerEntity[V], error) {
+	if IsBuiltInTransformerEntityId(id) {
+		return nil, ErrTransformerEntityBuiltIn
+	}
+	if err := s.validate(value); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.ListVersions(id)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if len(versions) > 0 {
+		version = versions[0].Version + 1
+	}
+
+	if err := s.store.Set(id, value); err != nil {
+		return nil, err
+	}
+	if err := s.versionStore.Set(getTransformerEntityVersionKey(id, version), value); err != nil {
+		return nil, err
+	}
+	for i := maxTransformerEntityVersionCount - 1; i < len(versions); i++ {
+		if err := s.versionStore.Del(getTransformerEntityVersionKey(id, versions[i].Version)); err != nil {
+			log.Warn("failed to cleanup transformer entity version", "error", err, "id", id, "version", versions[i].Version)
+		}
+	}
+
+	return &TransformerEntity[V]{Id: id, Value: value, Version: version, UpdatedAt: time.Now()}, nil
+}
+
+func (s *TransformerEntityStore[V]) Delete(id string) error {
+	if IsBuiltInTransformerEntityId(id) {
+		return ErrTransformerEntityBuiltIn
+	}
+	versions, err := s.ListVersions(id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Del(id); err != nil {
+		return err
+	}
+	for i := range versions {
+		if err := s.versionStore.Del(getTransformerEntityVersionKey(id, versions[i].Version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var TransformerExtractors = newTransformerEntityStore(
+	"st:wrap:transformer:extractor",
+	builtInExtractors,
+	extractorStore,
+	func(value stremio_transformer.StreamExtractorBlob) error {
+		_, err := value.Parse()
+		return err
+	},
+)
+
+var TransformerTemplates = newTransformerEntityStore(
+	"st:wrap:transformer:template",
+	builtInTemplates,
+	templateStore,
+	func(value stremio_transformer.StreamTemplateBlob) error {
+		_, err := value.Parse()
+		return err
+	},
+)
+
+type TransformerDryRunResult struct {
+	Extracted *stremio_transformer.StreamExtractorResult `json:"extracted"`
+	Stream    *stremio.Stream                            `json:"stream"`
+	Error     string                                     `json:"error,omitempty"`
+}
+
+// DryRunTransformer runs the extractor and template against the streams
+// without touching the stored entities.
+func DryRunTransformer(extractorBlob stremio_transformer.StreamExtractorBlob, templateBlob stremio_transformer.StreamTemplateBlob, sType string, streams []stremio.Stream) ([]TransformerDryRunResult, error) {
+	extractor, err := extractorBlob.Parse()
+	if err != nil {
+		return nil, err
+	}
+	template, err := templateBlob.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if template.IsEmpty() {
+		template = stremio_transformer.StreamTemplateDefault
+	}
+
+	results := make([]TransformerDryRunResult, len(streams))
+	for i := range streams {
+		result := &results[i]
+		stream := streams[i]
+		result.Extracted = extractor.Parse(&stream, sType)
+		result.Stream, err = template.Execute(&stream, result.Extracted)
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+	return results, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/rodezfranco/stremthru/internal/cache"
 	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/content_proxy_hls"
 	"github.com/rodezfranco/stremthru/internal/context"
 	"github.com/rodezfranco/stremthru/internal/server"
 	"github.com/rodezfranco/stremthru/internal/shared"
@@ -75,10 +76,20 @@ type UserData struct {
 	TemplateId string                                 `json:"template,omitempty"`
 	template   stremio_transformer.StreamTemplateBlob `json:"-"`
 
-	Sort string `json:"sort,omitempty"`
+	Sort   string `json:"sort,omitempty"`
+	Filter string `json:"filter,omitempty"`
 
 	RPDBAPIKey string `json:"rpdb_akey,omitempty"`
 
+	MergeMeta       bool               `json:"merge_meta,omitempty"`
+	CombineCatalogs CatalogCombineMode `json:"combine_catalogs,omitempty"`
+
+	SubtitleLangs     string `json:"sub_langs,omitempty"`
+	SubtitleLangsOnly bool   `json:"sub_langs_only,omitempty"`
+	SubtitleProxy     bool   `json:"sub_proxy,omitempty"`
+
+	CompatStream content_proxy_hls.Variant `json:"compat,omitempty"`
+
 	encoded   string             `json:"-"` // correctly configured
 	manifests []stremio.Manifest `json:"-"`
 	resolver  upstreamsResolver  `json:"-"`
@@ -88,6 +99,10 @@ var udManager = stremio_userdata.NewManager[UserData](&stremio_userdata.ManagerC
 	AddonName: "wrap",
 })
 
+func (ud UserData) hasCompatStream() bool {
+	return ud.CompatStream != "" && content_proxy_hls.Default != nil
+}
+
 func (ud UserData) HasRequiredValues() bool {
 	if len(ud.Upstreams) == 0 {
 		return false
@@ -419,6 +434,7 @@ func getUserData(r *http.Request) (*UserData, error) {
 
 		data.IncludeTorz = r.Form.Get("torz") == "on"
 		data.Sort = r.Form.Get("sort")
+		data.Filter = r.Form.Get("filter")
 		data.RPDBAPIKey = r.Form.Get("rpdb_akey")
 
 		data.TemplateId = r.Form.Get("transformer.template_id")
@@ -476,6 +492,18 @@ func getUserData(r *http.Request) (*UserData, error) {
 		}
 
 		data.CachedOnly = r.Form.Get("cached") == "on"
+		data.MergeMeta = r.Form.Get("merge_meta") == "on"
+		data.CombineCatalogs = CatalogCombineMode(r.Form.Get("combine_catalogs"))
+		if !data.CombineCatalogs.IsValid() {
+			data.CombineCatalogs = CatalogCombineModeNone
+		}
+		data.SubtitleLangs = strings.Join(parseSubtitleLangs(r.Form.Get("sub_langs")), ",")
+		data.SubtitleLangsOnly = r.Form.Get("sub_langs_only") == "on"
+		data.SubtitleProxy = r.Form.Get("sub_proxy") == "on"
+		data.CompatStream = content_proxy_hls.Variant(r.Form.Get("compat"))
+		if !data.CompatStream.IsValid() {
+			data.CompatStream = ""
+		}
 
 		isStoreStremThru := false
 		for i := range data.Stores {
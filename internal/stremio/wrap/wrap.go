@@ -16,7 +16,9 @@ var MaxPublicInstanceUpstreamCount = config.Stremio.Wrap.PublicMaxUpstreamCount
 var MaxPublicInstanceStoreCount = config.Stremio.Wrap.PublicMaxStoreCount
 
 var addon = func() *stremio_addon.Client {
-	return stremio_addon.NewClient(&stremio_addon.ClientConfig{})
+	return stremio_addon.NewClient(&stremio_addon.ClientConfig{
+		TrackHealth: true,
+	})
 }()
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
@@ -110,7 +112,7 @@ func handleResource(w http.ResponseWriter, r *http.Request) {
 		return
 
 	case stremio.ResourceNameSubtitles:
-		res, err := ud.fetchSubtitles(ctx, contentType, id, extra)
+		res, err := ud.fetchSubtitles(ctx, r, contentType, id, extra)
 		if err != nil {
 			SendError(w, r, err)
 			return
@@ -159,5 +161,7 @@ func AddStremioWrapEndpoints(mux *http.ServeMux) {
 	router.HandleFunc("/{userData}/_/strem/{magnetHash}/{fileIdx}/{$}", withCors(handleStrem))
 	router.HandleFunc("/{userData}/_/strem/{magnetHash}/{fileIdx}/{fileName}", withCors(handleStrem))
 
+	router.HandleFunc("/{userData}/_/subtitle/{subUrl}/{fileName}", withCors(handleSubtitle))
+
 	mux.Handle("/stremio/wrap/", http.StripPrefix("/stremio/wrap", commonMiddleware(router)))
 }
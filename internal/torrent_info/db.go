@@ -89,6 +89,7 @@ const (
 	TorrentInfoSourcePremiumize  TorrentInfoSource = "pm"
 	TorrentInfoSourceRealDebrid  TorrentInfoSource = "rd"
 	TorrentInfoSourceTorBox      TorrentInfoSource = "tb"
+	TorrentInfoSourceTorznab     TorrentInfoSource = "tzn"
 	TorrentInfoSourceUnknown     TorrentInfoSource = ""
 )
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/rodezfranco/stremthru/internal/imdb_title"
 	"github.com/rodezfranco/stremthru/internal/imdb_torrent"
+	"github.com/rodezfranco/stremthru/internal/meta"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
 )
 
@@ -48,9 +49,61 @@ var lastMappedIMDBIdCached struct {
 	staleAt time.Time
 }
 
+func (q Query) GetIdType() meta.IdType {
+	switch q.Type {
+	case "tvsearch":
+		return meta.IdTypeShow
+	case "movie":
+		return meta.IdTypeMovie
+	}
+	hasMovieCat, hasTvCat := q.HasMovies(), q.HasTVShows()
+	if hasMovieCat && !hasTvCat {
+		return meta.IdTypeMovie
+	}
+	if !hasMovieCat && hasTvCat {
+		return meta.IdTypeShow
+	}
+	return meta.IdTypeUnknown
+}
+
+// ResolveIMDBId maps the TVDB/TMDB/Trakt id in the query to IMDB id.
+func (q Query) ResolveIMDBId() (string, error) {
+	id := ""
+	switch {
+	case q.TVDBId != "":
+		id = "tvdb:" + q.TVDBId
+	case q.TMDBId != "":
+		id = "tmdb:" + q.TMDBId
+	case q.TraktId != "":
+		id = "trakt:" + q.TraktId
+	default:
+		return "", nil
+	}
+	idMap, err := meta.GetIdMap(q.GetIdType(), id)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(idMap.IMDB, "tt") {
+		return "", nil
+	}
+	return idMap.IMDB, nil
+}
+
 func (sti stremThruIndexer) Search(q Query) ([]ResultItem, error) {
 	imdbIds := []string{}
 
+	if q.IMDBId == "" && (q.TVDBId != "" || q.TMDBId != "" || q.TraktId != "") {
+		imdbId, err := q.ResolveIMDBId()
+		if err != nil {
+			return nil, err
+		}
+		if imdbId == "" {
+			log.Debug("no imdb id found for query", "tvdbid", q.TVDBId, "tmdbid", q.TMDBId, "traktid", q.TraktId)
+			return []ResultItem{}, nil
+		}
+		q.IMDBId = imdbId
+	}
+
 	if q.IMDBId == "" && q.Q == "" {
 		if lastMappedIMDBIdCached.staleAt.Before(time.Now()) {
 			imdbId, err := imdb_torrent.GetLastMappedIMDBId()
@@ -292,17 +345,17 @@ var StremThruIndexer = stremThruIndexer{
 			{
 				Name:            "search",
 				Available:       true,
-				SupportedParams: []string{"q"},
+				SupportedParams: []string{"q", "year"},
 			},
 			{
 				Name:            "tv-search",
 				Available:       true,
-				SupportedParams: []string{"q,imdbid,season,ep"},
+				SupportedParams: []string{"q", "year", "season", "ep", "imdbid", "tvdbid", "tmdbid", "traktid"},
 			},
 			{
 				Name:            "movie-search",
 				Available:       true,
-				SupportedParams: []string{"q,imdbid"},
+				SupportedParams: []string{"q", "year", "imdbid", "tmdbid", "traktid"},
 			},
 		},
 		Categories: []CapsCategory{
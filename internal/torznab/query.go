@@ -22,6 +22,7 @@ type Query struct {
 	TVDBId   string
 	TVRageId string
 	IMDBId   string
+	TMDBId   string
 	TVMazeId string
 	TraktId  string
 }
@@ -107,6 +108,10 @@ func (query Query) Encode() string {
 		v.Set("tvdbid", query.TVDBId)
 	}
 
+	if query.TMDBId != "" {
+		v.Set("tmdbid", query.TMDBId)
+	}
+
 	if query.TVRageId != "" {
 		v.Set("rid", query.TVRageId)
 	}
@@ -158,19 +163,21 @@ func ParseQuery(q url.Values) (Query, error) {
 			if len(vals) > 1 {
 				return query, errors.New("Multiple ep parameters not allowed")
 			}
-			if _, err := strconv.Atoi(vals[0]); err != nil {
+			ep, err := strconv.Atoi(vals[0])
+			if err != nil {
 				return query, errors.New("Invalid ep")
 			}
-			query.Ep = vals[0]
+			query.Ep = strconv.Itoa(ep)
 
 		case "season":
 			if len(vals) > 1 {
 				return query, errors.New("Multiple season parameters not allowed")
 			}
-			if _, err := strconv.Atoi(vals[0]); err != nil {
+			season, err := strconv.Atoi(vals[0])
+			if err != nil {
 				return query, errors.New("Invalid season")
 			}
-			query.Season = vals[0]
+			query.Season = strconv.Itoa(season)
 
 		case "apikey":
 			if len(vals) > 1 {
@@ -216,6 +223,26 @@ func ParseQuery(q url.Values) (Query, error) {
 			if !strings.HasPrefix(query.IMDBId, "tt") {
 				query.IMDBId = "tt" + query.IMDBId
 			}
+
+		case "tvdbid", "tmdbid", "traktid", "tvmazeid", "rid":
+			if len(vals) > 1 {
+				return query, errors.New("Multiple " + key + " parameters not allowed")
+			}
+			if _, err := strconv.Atoi(vals[0]); err != nil {
+				return query, errors.New("Invalid " + key)
+			}
+			switch strings.ToLower(key) {
+			case "tvdbid":
+				query.TVDBId = vals[0]
+			case "tmdbid":
+				query.TMDBId = vals[0]
+			case "traktid":
+				query.TraktId = vals[0]
+			case "tvmazeid":
+				query.TVMazeId = vals[0]
+			case "rid":
+				query.TVRageId = vals[0]
+			}
 		}
 	}
 
@@ -1,6 +1,7 @@
 package torznab
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +17,14 @@ func TestQueryEncode(t *testing.T) {
 		assert.Equal(t, row.left.Encode(), row.right.Encode())
 	}
 }
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(url.Values{"t": {"tvsearch"}, "tvdbid": {"81189"}, "season": {"01"}, "ep": {"05"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "81189", q.TVDBId)
+	assert.Equal(t, "1", q.Season)
+	assert.Equal(t, "5", q.Ep)
+
+	_, err = ParseQuery(url.Values{"tmdbid": {"abc"}})
+	assert.Error(t, err)
+}
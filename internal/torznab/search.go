@@ -0,0 +1,136 @@
+package torznab
+
+import (
+	"sync"
+
+	"github.com/rodezfranco/stremthru/internal/imdb_torrent"
+	"github.com/rodezfranco/stremthru/internal/torrent_info"
+)
+
+func toTorrentInfoCategory(c Category) torrent_info.TorrentInfoCategory {
+	switch ParentCategory(c) {
+	case CategoryMovies:
+		return torrent_info.TorrentInfoCategoryMovie
+	case CategoryTV:
+		return torrent_info.TorrentInfoCategorySeries
+	case CategoryXXX:
+		return torrent_info.TorrentInfoCategoryXXX
+	default:
+		return torrent_info.TorrentInfoCategoryUnknown
+	}
+}
+
+func recordUpstreamItems(items []ResultItem) {
+	tInfos := make([]torrent_info.TorrentInfoInsertData, 0, len(items))
+	imdbTorrents := []imdb_torrent.IMDBTorrent{}
+	for i := range items {
+		item := &items[i]
+		size := item.Size
+		if size <= 0 {
+			size = -1
+		}
+		tInfos = append(tInfos, torrent_info.TorrentInfoInsertData{
+			Hash:         item.InfoHash,
+			TorrentTitle: item.Title,
+			Size:         size,
+			Source:       torrent_info.TorrentInfoSourceTorznab,
+			Category:     toTorrentInfoCategory(item.Category),
+		})
+		if item.IMDB != "" {
+			imdbTorrents = append(imdbTorrents, imdb_torrent.IMDBTorrent{
+				TId:  item.IMDB,
+				Hash: item.InfoHash,
+			})
+		}
+	}
+	torrent_info.Upsert(tInfos, "", false)
+	if err := imdb_torrent.Insert(imdbTorrents); err != nil {
+		log.Error("failed to record upstream imdb torrents", "error", err)
+	}
+}
+
+// Search queries StremThruIndexer and the upstream indexers in parallel, and
+// merges the results by info hash. Results from upstream indexers are recorded
+// in the local index.
+func Search(q Query) ([]ResultItem, error) {
+	if len(UpstreamIndexers) == 0 {
+		return StremThruIndexer.Search(q)
+	}
+
+	localQuery := q
+	localQuery.Offset, localQuery.Limit = 0, 0
+
+	upstreamQuery := q
+	upstreamQuery.Offset = 0
+	if q.Limit > 0 {
+		upstreamQuery.Limit = q.Offset + q.Limit
+	}
+
+	var localItems []ResultItem
+	var localErr error
+	upstreamItems := make([][]ResultItem, len(UpstreamIndexers))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		localItems, localErr = StremThruIndexer.Search(localQuery)
+	}()
+	for i, indexer := range UpstreamIndexers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, err := indexer.Search(upstreamQuery)
+			if err != nil {
+				log.Warn("failed to search upstream indexer", "error", err, "indexer", indexer.Info().ID)
+				return
+			}
+			upstreamItems[i] = items
+		}()
+	}
+	wg.Wait()
+
+	if localErr != nil {
+		log.Error("failed to search local index", "error", localErr)
+	}
+
+	items := []ResultItem{}
+	idxByHash := map[string]int{}
+	for _, item := range localItems {
+		idxByHash[item.InfoHash] = len(items)
+		items = append(items, item)
+	}
+
+	newItems := []ResultItem{}
+	for _, uItems := range upstreamItems {
+		for _, item := range uItems {
+			if idx, seen := idxByHash[item.InfoHash]; seen {
+				existing := &items[idx]
+				existing.Seeders = max(existing.Seeders, item.Seeders)
+				existing.Peers = max(existing.Peers, item.Peers)
+				continue
+			}
+			idxByHash[item.InfoHash] = len(items)
+			items = append(items, item)
+			newItems = append(newItems, item)
+		}
+	}
+
+	if len(newItems) > 0 {
+		go recordUpstreamItems(newItems)
+	}
+
+	if localErr != nil && len(items) == 0 {
+		return nil, localErr
+	}
+
+	if q.Offset > 0 {
+		items = items[min(q.Offset, len(items)):]
+	}
+
+	if q.Limit > 0 {
+		items = items[:min(q.Limit, len(items))]
+	}
+
+	return items, nil
+}
@@ -0,0 +1,235 @@
+package torznab
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/config"
+)
+
+const upstreamIndexerTimeout = 20 * time.Second
+
+type upstreamItemAttribute struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type upstreamItem struct {
+	Title       string `xml:"title"`
+	GUID        string `xml:"guid"`
+	Link        string `xml:"link"`
+	PublishDate string `xml:"pubDate"`
+	Size        int64  `xml:"size"`
+	Description string `xml:"description"`
+	Enclosure   struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+	// matches both torznab:attr and newznab:attr
+	Attributes []upstreamItemAttribute `xml:"attr"`
+}
+
+type upstreamResponse struct {
+	XMLName     xml.Name
+	Code        int    `xml:"code,attr"`
+	Description string `xml:"description,attr"`
+	Channel     struct {
+		Items []upstreamItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+func parseUpstreamPublishDate(value string) time.Time {
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, rfc822} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func getCategory(id int) Category {
+	if cats := AllCategories.Subset(id); len(cats) > 0 {
+		return cats[0]
+	}
+	return ParentCategory(Category{ID: id})
+}
+
+// toResultItem only accepts items with info hash, the upstream links are not
+// exposed since they can contain the upstream api key.
+func (item upstreamItem) toResultItem() (ResultItem, bool) {
+	ri := ResultItem{
+		Category:    CategoryOther,
+		Description: item.Description,
+		PublishDate: parseUpstreamPublishDate(item.PublishDate),
+		Title:       item.Title,
+		Size:        item.Size,
+	}
+	if ri.Size == 0 {
+		ri.Size = item.Enclosure.Length
+	}
+
+	hasCategory := false
+	magnetLink := ""
+	for _, attr := range item.Attributes {
+		switch attr.Name {
+		case "infohash":
+			ri.InfoHash = strings.ToLower(attr.Value)
+		case "magneturl":
+			magnetLink = attr.Value
+		case "category":
+			if id, err := strconv.Atoi(attr.Value); err == nil && id < CustomCategoryOffset && !hasCategory {
+				ri.Category = getCategory(id)
+				hasCategory = true
+			}
+		case "imdb", "imdbid":
+			if attr.Value != "" && attr.Value != "0" {
+				id := strings.TrimPrefix(attr.Value, "tt")
+				if len(id) < 7 {
+					id = strings.Repeat("0", 7-len(id)) + id
+				}
+				ri.IMDB = "tt" + id
+			}
+		case "size":
+			if size, err := strconv.ParseInt(attr.Value, 10, 64); err == nil && ri.Size == 0 {
+				ri.Size = size
+			}
+		case "files":
+			if files, err := strconv.Atoi(attr.Value); err == nil {
+				ri.Files = files
+			}
+		case "seeders":
+			if seeders, err := strconv.Atoi(attr.Value); err == nil {
+				ri.Seeders = seeders
+			}
+		case "peers":
+			if peers, err := strconv.Atoi(attr.Value); err == nil {
+				ri.Peers = peers
+			}
+		}
+	}
+
+	if ri.InfoHash == "" {
+		for _, link := range []string{magnetLink, item.Link, item.GUID, item.Enclosure.URL} {
+			if strings.HasPrefix(link, "magnet:") {
+				if magnet, err := core.ParseMagnetLink(link); err == nil {
+					ri.InfoHash = magnet.Hash
+					break
+				}
+			}
+		}
+	}
+	if len(ri.InfoHash) != 40 {
+		return ri, false
+	}
+	return ri, true
+}
+
+type upstreamIndexer struct {
+	info    Info
+	baseURL *url.URL
+	client  *http.Client
+}
+
+func newUpstreamIndexer(conf config.TorznabIndexerConfig) *upstreamIndexer {
+	return &upstreamIndexer{
+		info: Info{
+			ID:    conf.Name,
+			Title: conf.Name,
+		},
+		baseURL: conf.URL,
+		client:  config.DefaultHTTPClient,
+	}
+}
+
+func (ui upstreamIndexer) Info() Info {
+	return ui.info
+}
+
+func (ui upstreamIndexer) request(query url.Values) (*http.Response, context.CancelFunc, error) {
+	u := *ui.baseURL
+	v := u.Query()
+	for key, vals := range query {
+		// upstream api key is part of the base url
+		if key == "apikey" {
+			continue
+		}
+		v[key] = vals
+	}
+	u.RawQuery = v.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamIndexerTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	res, err := ui.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return res, cancel, nil
+}
+
+func (ui upstreamIndexer) Search(q Query) ([]ResultItem, error) {
+	query, err := url.ParseQuery(q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	res, cancel, err := ui.request(query)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return nil, errors.New("unexpected status: " + res.Status)
+	}
+
+	var data upstreamResponse
+	if err := xml.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+	if data.XMLName.Local == "error" {
+		return nil, Error{Code: data.Code, Description: data.Description}
+	}
+
+	items := []ResultItem{}
+	for i := range data.Channel.Items {
+		if item, ok := data.Channel.Items[i].toResultItem(); ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Download is not supported, results only carry the info hash as the
+// upstream links can contain the upstream api key.
+func (ui upstreamIndexer) Download(urlStr string) (io.ReadCloser, http.Header, error) {
+	return nil, nil, ErrorFunctionNotAvailable
+}
+
+func (ui upstreamIndexer) Capabilities() Caps {
+	return Caps{
+		Server: &CapsServer{
+			Title: ui.info.Title,
+		},
+	}
+}
+
+var UpstreamIndexers = func() []Indexer {
+	indexers := []Indexer{}
+	for _, conf := range config.Torznab.Indexers {
+		indexers = append(indexers, newUpstreamIndexer(conf))
+	}
+	return indexers
+}()
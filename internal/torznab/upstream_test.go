@@ -0,0 +1,88 @@
+package torznab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUpstreamFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torznab="http://torznab.com/schemas/2015/feed">
+  <channel>
+    <item>
+      <title>The.Movie.2020.1080p.WEB-DL</title>
+      <guid>https://indexer.local/details/1</guid>
+      <link>https://indexer.local/download/1?apikey=secret</link>
+      <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+      <size>1073741824</size>
+      <torznab:attr name="category" value="2040" />
+      <torznab:attr name="category" value="100001" />
+      <torznab:attr name="infohash" value="0123456789ABCDEF0123456789ABCDEF01234567" />
+      <torznab:attr name="imdb" value="111161" />
+      <torznab:attr name="seeders" value="42" />
+    </item>
+    <item>
+      <title>Another.Movie.2020.720p</title>
+      <link>magnet:?xt=urn:btih:89abcdef0123456789abcdef0123456789abcdef</link>
+      <torznab:attr name="size" value="1024" />
+    </item>
+    <item>
+      <title>No.Hash.2020</title>
+      <link>https://indexer.local/download/3?apikey=secret</link>
+    </item>
+  </channel>
+</rss>`
+
+func TestUpstreamIndexerSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("apikey"))
+		assert.Equal(t, "movie", r.URL.Query().Get("t"))
+		assert.Equal(t, "0111161", r.URL.Query().Get("imdbid"))
+		w.Write([]byte(testUpstreamFeed))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/api?apikey=secret")
+	indexer := newUpstreamIndexer(config.TorznabIndexerConfig{Name: "test", URL: u})
+
+	items, err := indexer.Search(Query{Type: "movie", IMDBId: "tt0111161", APIKey: "client"})
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", items[0].InfoHash)
+	assert.Equal(t, CategoryMovies_HD, items[0].Category)
+	assert.Equal(t, "tt0111161", items[0].IMDB)
+	assert.Equal(t, int64(1073741824), items[0].Size)
+	assert.Equal(t, 42, items[0].Seeders)
+	assert.Empty(t, items[0].Link)
+	assert.Equal(t, 2006, items[0].PublishDate.Year())
+
+	assert.Equal(t, "89abcdef0123456789abcdef0123456789abcdef", items[1].InfoHash)
+	assert.Equal(t, int64(1024), items[1].Size)
+}
+
+func TestUpstreamIndexerSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><error code="100" description="Invalid API Key" />`))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	indexer := newUpstreamIndexer(config.TorznabIndexerConfig{Name: "test", URL: u})
+
+	_, err := indexer.Search(Query{Type: "search", Q: "test"})
+	assert.Equal(t, Error{Code: 100, Description: "Invalid API Key"}, err)
+}
+
+func TestUpstreamIndexerDownload(t *testing.T) {
+	u, _ := url.Parse("https://indexer.local/api?apikey=secret")
+	indexer := newUpstreamIndexer(config.TorznabIndexerConfig{Name: "test", URL: u})
+
+	body, _, err := indexer.Download("https://indexer.local/download/1?apikey=secret")
+	assert.Nil(t, body)
+	assert.Equal(t, ErrorFunctionNotAvailable, err)
+}
@@ -0,0 +1,147 @@
+package trakt
+
+import (
+	"time"
+)
+
+type SyncIds struct {
+	Trakt int    `json:"trakt,omitempty"`
+	Slug  string `json:"slug,omitempty"`
+	IMDB  string `json:"imdb,omitempty"`
+	TMDB  int    `json:"tmdb,omitempty"`
+	TVDB  int    `json:"tvdb,omitempty"`
+}
+
+type SyncMedia struct {
+	Title string  `json:"title,omitempty"`
+	Year  int     `json:"year,omitempty"`
+	Ids   SyncIds `json:"ids"`
+}
+
+type SyncEpisode struct {
+	Season int     `json:"season"`
+	Number int     `json:"number"`
+	Title  string  `json:"title,omitempty"`
+	Ids    SyncIds `json:"ids,omitzero"`
+}
+
+type SyncWatchedEpisode struct {
+	Number        int       `json:"number"`
+	Plays         int       `json:"plays"`
+	LastWatchedAt time.Time `json:"last_watched_at"`
+}
+
+type SyncWatchedSeason struct {
+	Number   int                  `json:"number"`
+	Episodes []SyncWatchedEpisode `json:"episodes"`
+}
+
+type SyncWatchedItem struct {
+	Plays         int                 `json:"plays"`
+	LastWatchedAt time.Time           `json:"last_watched_at"`
+	LastUpdatedAt time.Time           `json:"last_updated_at"`
+	Movie         *SyncMedia          `json:"movie,omitempty"`
+	Show          *SyncMedia          `json:"show,omitempty"`
+	Seasons       []SyncWatchedSeason `json:"seasons,omitempty"`
+}
+
+type FetchWatchedData = listResponseData[SyncWatchedItem]
+
+type FetchWatchedParams struct {
+	Ctx
+	Type string // movies / shows
+}
+
+func (c APIClient) FetchWatched(params *FetchWatchedParams) (APIResponse[[]SyncWatchedItem], error) {
+	response := FetchWatchedData{}
+	res, err := c.Request("GET", "/sync/watched/"+params.Type, params, &response)
+	return newAPIResponse(res, response.data), err
+}
+
+type SyncPlaybackItem struct {
+	Id       int64        `json:"id"`
+	Progress float64      `json:"progress"` // 0.0 - 100.0
+	PausedAt time.Time    `json:"paused_at"`
+	Type     ItemType     `json:"type"`
+	Movie    *SyncMedia   `json:"movie,omitempty"`
+	Episode  *SyncEpisode `json:"episode,omitempty"`
+	Show     *SyncMedia   `json:"show,omitempty"`
+}
+
+type FetchPlaybackData = listResponseData[SyncPlaybackItem]
+
+type FetchPlaybackParams struct {
+	Ctx
+	Type string // movies / episodes
+}
+
+func (c APIClient) FetchPlayback(params *FetchPlaybackParams) (APIResponse[[]SyncPlaybackItem], error) {
+	response := FetchPlaybackData{}
+	res, err := c.Request("GET", "/sync/playback/"+params.Type, params, &response)
+	return newAPIResponse(res, response.data), err
+}
+
+type AddToHistoryMovie struct {
+	WatchedAt time.Time `json:"watched_at"`
+	Ids       SyncIds   `json:"ids"`
+}
+
+type AddToHistoryEpisode struct {
+	WatchedAt time.Time `json:"watched_at"`
+	Number    int       `json:"number"`
+}
+
+type AddToHistorySeason struct {
+	Number   int                   `json:"number"`
+	Episodes []AddToHistoryEpisode `json:"episodes"`
+}
+
+type AddToHistoryShow struct {
+	Ids     SyncIds              `json:"ids"`
+	Seasons []AddToHistorySeason `json:"seasons"`
+}
+
+type AddToHistoryData struct {
+	ResponseError
+	Added struct {
+		Movies   int `json:"movies"`
+		Episodes int `json:"episodes"`
+	} `json:"added"`
+}
+
+type AddToHistoryParams struct {
+	Ctx
+	Movies []AddToHistoryMovie `json:"movies,omitempty"`
+	Shows  []AddToHistoryShow  `json:"shows,omitempty"`
+}
+
+func (c APIClient) AddToHistory(params *AddToHistoryParams) (APIResponse[AddToHistoryData], error) {
+	params.JSON = params
+	response := AddToHistoryData{}
+	res, err := c.Request("POST", "/sync/history", params, &response)
+	return newAPIResponse(res, response), err
+}
+
+type ScrobbleData struct {
+	ResponseError
+	Id       int64   `json:"id"`
+	Action   string  `json:"action"`
+	Progress float64 `json:"progress"`
+}
+
+type ScrobblePauseParams struct {
+	Ctx
+	Movie    *SyncMedia   `json:"movie,omitempty"`
+	Show     *SyncMedia   `json:"show,omitempty"`
+	Episode  *SyncEpisode `json:"episode,omitempty"`
+	Progress float64      `json:"progress"`
+}
+
+// ScrobblePause saves the playback progress, it shows up in the
+// playback list.
+func (c APIClient) ScrobblePause(params *ScrobblePauseParams) (APIResponse[ScrobbleData], error) {
+	params.JSON = params
+	response := ScrobbleData{}
+	res, err := c.Request("POST", "/scrobble/pause", params, &response)
+	return newAPIResponse(res, response), err
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/rodezfranco/stremthru/core"
 	"github.com/rodezfranco/stremthru/internal/buddy"
+	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/magnet_cache"
 	"github.com/rodezfranco/stremthru/internal/peer"
 	"github.com/rodezfranco/stremthru/internal/shared"
@@ -38,10 +39,19 @@ func InitMagnetCachePullerWorker(conf *WorkerConfig) *Worker {
 					clientIps = append(clientIps, item.ClientIP)
 					seenClientIp[item.ClientIP] = struct{}{}
 				}
-				if _, seen := seenStoreToken[item.StoreToken]; !seen {
-					storeTokens = append(storeTokens, item.StoreToken)
-					seenStoreToken[item.StoreToken] = struct{}{}
+				storeToken := config.StoreAuthToken.GetToken(item.StoreUser, string(s.GetName()))
+				if storeToken == "" {
+					continue
 				}
+				if _, seen := seenStoreToken[storeToken]; !seen {
+					storeTokens = append(storeTokens, storeToken)
+					seenStoreToken[storeToken] = struct{}{}
+				}
+			}
+
+			if len(storeTokens) == 0 {
+				w.Log.Warn("store token not found", "store", s.GetName())
+				return nil
 			}
 
 			for i, cHashes := range slices.Collect(slices.Chunk(hashes, 500)) {
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"github.com/rodezfranco/stremthru/internal/proxy_token"
+)
+
+func InitCleanProxyTokenWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		count, err := proxy_token.DeleteExpired()
+		if err != nil {
+			return err
+		}
+		w.Log.Debug("deleted expired proxy tokens", "count", count)
+		return nil
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
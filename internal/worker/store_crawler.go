@@ -3,6 +3,7 @@ package worker
 import (
 	"time"
 
+	"github.com/rodezfranco/stremthru/internal/config"
 	"github.com/rodezfranco/stremthru/internal/shared"
 	"github.com/rodezfranco/stremthru/internal/torrent_info"
 	"github.com/rodezfranco/stremthru/internal/worker/worker_queue"
@@ -19,6 +20,12 @@ func InitCrawlStoreWorker(conf *WorkerConfig) *Worker {
 				return nil
 			}
 
+			storeToken := config.StoreAuthToken.GetToken(item.StoreUser, string(s.GetName()))
+			if storeToken == "" {
+				log.Warn("store token not found", "store", s.GetName(), "user", item.StoreUser)
+				return nil
+			}
+
 			tSource := torrent_info.TorrentInfoSource(item.StoreCode)
 			discardFileIdx := s.GetName().Code() != store.StoreCodeRealDebrid
 
@@ -30,7 +37,7 @@ func InitCrawlStoreWorker(conf *WorkerConfig) *Worker {
 					Limit:  limit,
 					Offset: offset,
 				}
-				params.APIKey = item.StoreToken
+				params.APIKey = storeToken
 				res, err := s.ListMagnets(params)
 				if err != nil {
 					log.Error("failed to list magnets", "err", err)
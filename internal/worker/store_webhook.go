@@ -0,0 +1,15 @@
+package worker
+
+import (
+	store_webhook "github.com/rodezfranco/stremthru/internal/store/webhook"
+)
+
+func InitStoreWebhookWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		return store_webhook.Process()
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
@@ -0,0 +1,15 @@
+package worker
+
+import (
+	stremio_backup "github.com/rodezfranco/stremthru/internal/stremio/backup"
+)
+
+func InitStremioBackupWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		return stremio_backup.ProcessSchedules()
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
@@ -0,0 +1,15 @@
+package worker
+
+import (
+	stremio_sync "github.com/rodezfranco/stremthru/internal/stremio/sync"
+)
+
+func InitSyncStremioTraktWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		return stremio_sync.ProcessTraktLinks()
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
@@ -0,0 +1,15 @@
+package worker
+
+import (
+	"github.com/rodezfranco/stremthru/internal/config"
+)
+
+func InitCheckTunnelPoolHealthWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		return config.StoreTunnelPool.CheckHealth()
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
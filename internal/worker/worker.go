@@ -581,6 +581,74 @@ func InitWorkers() func() {
 		workers = append(workers, worker)
 	}
 
+	if worker := InitStoreWebhookWorker(&WorkerConfig{
+		Disabled:     !config.StoreWebhook.IsEnabled(),
+		Interval:     1 * time.Minute,
+		Name:         "store-webhook",
+		OnEnd:        func() {},
+		OnStart:      func() {},
+		RunExclusive: true,
+		ShouldWait: func() (bool, string) {
+			return false, ""
+		},
+	}); worker != nil {
+		workers = append(workers, worker)
+	}
+
+	if worker := InitCheckTunnelPoolHealthWorker(&WorkerConfig{
+		Disabled: len(config.StoreTunnelPool) == 0,
+		Interval: 1 * time.Minute,
+		Name:     "check-tunnel-pool-health",
+		OnEnd:    func() {},
+		OnStart:  func() {},
+		ShouldWait: func() (bool, string) {
+			return false, ""
+		},
+	}); worker != nil {
+		workers = append(workers, worker)
+	}
+
+	if worker := InitCleanProxyTokenWorker(&WorkerConfig{
+		Interval:     1 * time.Hour,
+		Name:         "clean-proxy-token",
+		RunExclusive: true,
+		OnEnd:        func() {},
+		OnStart:      func() {},
+		ShouldWait: func() (bool, string) {
+			return false, ""
+		},
+	}); worker != nil {
+		workers = append(workers, worker)
+	}
+
+	if worker := InitSyncStremioTraktWorker(&WorkerConfig{
+		Disabled:     !config.Feature.IsEnabled(config.FeatureStremioSidekick) || !config.Integration.Trakt.IsEnabled(),
+		Interval:     15 * time.Minute,
+		Name:         "sync-stremio-trakt",
+		OnEnd:        func() {},
+		OnStart:      func() {},
+		RunExclusive: true,
+		ShouldWait: func() (bool, string) {
+			return false, ""
+		},
+	}); worker != nil {
+		workers = append(workers, worker)
+	}
+
+	if worker := InitStremioBackupWorker(&WorkerConfig{
+		Disabled:     !config.Feature.IsEnabled(config.FeatureStremioSidekick),
+		Interval:     1 * time.Hour,
+		Name:         "stremio-backup",
+		OnEnd:        func() {},
+		OnStart:      func() {},
+		RunExclusive: true,
+		ShouldWait: func() (bool, string) {
+			return false, ""
+		},
+	}); worker != nil {
+		workers = append(workers, worker)
+	}
+
 	return func() {
 		for _, worker := range workers {
 			worker.scheduler.Stop()
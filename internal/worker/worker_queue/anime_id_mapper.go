@@ -11,7 +11,8 @@ type AnimeIdMapperQueueItem struct {
 	Id      string
 }
 
-var AnimeIdMapperQueue = WorkerQueue[AnimeIdMapperQueueItem]{
+var AnimeIdMapperQueue = newQueue(WorkerQueue[AnimeIdMapperQueueItem]{
+	name:         "anime-id-mapper",
 	debounceTime: 1 * time.Minute,
 	getKey: func(item AnimeIdMapperQueueItem) string {
 		return item.Service + ":" + item.Id
@@ -23,4 +24,4 @@ var AnimeIdMapperQueue = WorkerQueue[AnimeIdMapperQueueItem]{
 		return item
 	},
 	Disabled: !config.Feature.IsEnabled("anime"),
-}
+})
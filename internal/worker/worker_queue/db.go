@@ -0,0 +1,292 @@
+package worker_queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/db"
+)
+
+const TableName = "worker_queue_item"
+
+type ColumnStruct struct {
+	Queue     string
+	Key       string
+	GroupKey  string
+	Value     string
+	Status    string
+	Attempts  string
+	Error     string
+	RunAt     string
+	CreatedAt string
+	UpdatedAt string
+}
+
+var Column = ColumnStruct{
+	Queue:     "queue",
+	Key:       "item_key",
+	GroupKey:  "group_key",
+	Value:     "value",
+	Status:    "status",
+	Attempts:  "attempts",
+	Error:     "error",
+	RunAt:     "run_at",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+type ItemStatus string
+
+const (
+	ItemStatusPending    ItemStatus = "pending"
+	ItemStatusProcessing ItemStatus = "processing"
+	ItemStatusDead       ItemStatus = "dead"
+)
+
+var query_enqueue = fmt.Sprintf(
+	"INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, 0, '', ?) ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = 0, %s = '', %s = %s",
+	TableName,
+	Column.Queue,
+	Column.Key,
+	Column.GroupKey,
+	Column.Value,
+	Column.Status,
+	Column.Attempts,
+	Column.Error,
+	Column.RunAt,
+	Column.Queue,
+	Column.Key,
+	Column.GroupKey,
+	Column.GroupKey,
+	Column.Value,
+	Column.Value,
+	Column.Status,
+	Column.Status,
+	Column.RunAt,
+	Column.RunAt,
+	Column.Attempts,
+	Column.Error,
+	Column.UpdatedAt,
+	db.CurrentTimestamp,
+)
+
+func enqueue(queue, key, groupKey, value string, runAt time.Time) error {
+	_, err := db.Exec(query_enqueue, queue, key, groupKey, value, ItemStatusPending, db.Timestamp{Time: runAt})
+	return err
+}
+
+type claimedItem struct {
+	key      string
+	groupKey string
+	value    string
+	attempts int
+}
+
+// items in processing status with expired visibility timeout are claimed
+// again, the previous claimer is assumed to be dead.
+var query_claim = fmt.Sprintf(
+	"UPDATE %s SET %s = ?, %s = %s + 1, %s = ?, %s = %s WHERE %s = ? AND %s IN (SELECT %s FROM %s WHERE %s = ? AND %s IN (?, ?) AND %s <= ? ORDER BY %s LIMIT ?) RETURNING %s, %s, %s, %s",
+	TableName,
+	Column.Status,
+	Column.Attempts,
+	Column.Attempts,
+	Column.RunAt,
+	Column.UpdatedAt,
+	db.CurrentTimestamp,
+	Column.Queue,
+	Column.Key,
+	Column.Key,
+	TableName,
+	Column.Queue,
+	Column.Status,
+	Column.RunAt,
+	Column.RunAt,
+	Column.Key,
+	Column.GroupKey,
+	Column.Value,
+	Column.Attempts,
+)
+
+func claim(queue string, visibilityTimeout time.Duration, limit int) ([]claimedItem, error) {
+	lock := db.NewAdvisoryLock("worker_queue", queue)
+	if lock == nil {
+		return nil, fmt.Errorf("failed to create advisory lock for %s", queue)
+	}
+	if !lock.TryAcquire() {
+		log.Debug("skipping claim, another instance is claiming", "queue", queue)
+		return nil, nil
+	}
+	defer lock.Release()
+
+	now := time.Now()
+	rows, err := db.Query(
+		query_claim,
+		ItemStatusProcessing,
+		db.Timestamp{Time: now.Add(visibilityTimeout)},
+		queue,
+		queue,
+		ItemStatusPending,
+		ItemStatusProcessing,
+		db.Timestamp{Time: now},
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []claimedItem{}
+	for rows.Next() {
+		var item claimedItem
+		if err := rows.Scan(&item.key, &item.groupKey, &item.value, &item.attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+var query_extend = fmt.Sprintf(
+	"UPDATE %s SET %s = ? WHERE %s = ? AND %s = ? AND %s = ?",
+	TableName,
+	Column.RunAt,
+	Column.Queue,
+	Column.Key,
+	Column.Status,
+)
+
+// extend keeps the processing item hidden from other processors until
+// `runAt`.
+func extend(queue, key string, runAt time.Time) error {
+	_, err := db.Exec(query_extend, db.Timestamp{Time: runAt}, queue, key, ItemStatusProcessing)
+	return err
+}
+
+// only processing items are touched, the item might have been queued again
+// while it was being processed.
+var query_complete = fmt.Sprintf(
+	"DELETE FROM %s WHERE %s = ? AND %s = ? AND %s = ?",
+	TableName,
+	Column.Queue,
+	Column.Key,
+	Column.Status,
+)
+
+func complete(queue, key string) error {
+	_, err := db.Exec(query_complete, queue, key, ItemStatusProcessing)
+	return err
+}
+
+var query_release = fmt.Sprintf(
+	"UPDATE %s SET %s = ?, %s = %s + ?, %s = ?, %s = ?, %s = %s WHERE %s = ? AND %s = ? AND %s = ?",
+	TableName,
+	Column.Status,
+	Column.Attempts,
+	Column.Attempts,
+	Column.Error,
+	Column.RunAt,
+	Column.UpdatedAt,
+	db.CurrentTimestamp,
+	Column.Queue,
+	Column.Key,
+	Column.Status,
+)
+
+func release(queue, key string, status ItemStatus, attemptsDelta int, errStr string, runAt time.Time) error {
+	_, err := db.Exec(query_release, status, attemptsDelta, errStr, db.Timestamp{Time: runAt}, queue, key, ItemStatusProcessing)
+	return err
+}
+
+var query_purge_dead = fmt.Sprintf(
+	"DELETE FROM %s WHERE %s = ? AND %s = ? AND %s < ?",
+	TableName,
+	Column.Queue,
+	Column.Status,
+	Column.UpdatedAt,
+)
+
+func purgeDead(queue string, olderThan time.Duration) error {
+	_, err := db.Exec(query_purge_dead, queue, ItemStatusDead, db.Timestamp{Time: time.Now().Add(-olderThan)})
+	return err
+}
+
+type QueueStats struct {
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Dead       int `json:"dead"`
+}
+
+var query_get_stats = fmt.Sprintf(
+	"SELECT %s, %s, COUNT(*) FROM %s GROUP BY %s, %s",
+	Column.Queue,
+	Column.Status,
+	TableName,
+	Column.Queue,
+	Column.Status,
+)
+
+func GetStats() (map[string]QueueStats, error) {
+	rows, err := db.Query(query_get_stats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statsByQueue := map[string]QueueStats{}
+	for rows.Next() {
+		var queue string
+		var status ItemStatus
+		var count int
+		if err := rows.Scan(&queue, &status, &count); err != nil {
+			return nil, err
+		}
+		stats := statsByQueue[queue]
+		switch status {
+		case ItemStatusPending:
+			stats.Pending = count
+		case ItemStatusProcessing:
+			stats.Processing = count
+		case ItemStatusDead:
+			stats.Dead = count
+		}
+		statsByQueue[queue] = stats
+	}
+	return statsByQueue, rows.Err()
+}
+
+type DeadItem struct {
+	Key       string       `json:"key"`
+	Attempts  int          `json:"attempts"`
+	Error     string       `json:"error"`
+	UpdatedAt db.Timestamp `json:"uat"`
+}
+
+var query_list_dead = fmt.Sprintf(
+	"SELECT %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s DESC LIMIT 100",
+	Column.Key,
+	Column.Attempts,
+	Column.Error,
+	Column.UpdatedAt,
+	TableName,
+	Column.Queue,
+	Column.Status,
+	Column.UpdatedAt,
+)
+
+func ListDead(queue string) ([]DeadItem, error) {
+	rows, err := db.Query(query_list_dead, queue, ItemStatusDead)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []DeadItem{}
+	for rows.Next() {
+		var item DeadItem
+		if err := rows.Scan(&item.Key, &item.Attempts, &item.Error, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
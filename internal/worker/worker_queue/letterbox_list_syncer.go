@@ -10,7 +10,8 @@ type LetterboxdListSyncerQueueItem struct {
 	ListId string
 }
 
-var LetterboxdListSyncerQueue = WorkerQueue[LetterboxdListSyncerQueueItem]{
+var LetterboxdListSyncerQueue = newQueue(WorkerQueue[LetterboxdListSyncerQueueItem]{
+	name:         "letterboxd-list-syncer",
 	debounceTime: 60 * time.Second,
 	getKey: func(item LetterboxdListSyncerQueueItem) string {
 		return item.ListId
@@ -19,4 +20,4 @@ var LetterboxdListSyncerQueue = WorkerQueue[LetterboxdListSyncerQueueItem]{
 		return item
 	},
 	Disabled: !config.Integration.Letterboxd.IsEnabled() && !config.HasPeer,
-}
+})
@@ -7,14 +7,17 @@ import (
 )
 
 type MagnetCachePullerQueueItem struct {
-	ClientIP   string
-	Hash       string
-	SId        string
-	StoreCode  string
-	StoreToken string
+	ClientIP  string
+	Hash      string
+	SId       string
+	StoreCode string
+	// StoreUser is the user in `config.StoreAuthToken`, the store token
+	// itself is not persisted in the queue.
+	StoreUser string
 }
 
-var MagnetCachePullerQueue = WorkerQueue[MagnetCachePullerQueueItem]{
+var MagnetCachePullerQueue = newQueue(WorkerQueue[MagnetCachePullerQueueItem]{
+	name:         "magnet-cache-puller",
 	debounceTime: 5 * time.Minute,
 	getKey: func(item MagnetCachePullerQueueItem) string {
 		return item.StoreCode + ":" + item.SId + ":" + item.Hash
@@ -26,4 +29,4 @@ var MagnetCachePullerQueue = WorkerQueue[MagnetCachePullerQueueItem]{
 		return item
 	},
 	Disabled: !config.LazyPeer,
-}
+})
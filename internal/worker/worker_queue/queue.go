@@ -1,86 +1,195 @@
 package worker_queue
 
 import (
+	"encoding/json"
 	"errors"
-	"sync"
 	"time"
 )
 
-type WorkerQueueItem[T any] struct {
-	v T
-	t time.Time
-}
+const defaultVisibilityTimeout = 10 * time.Minute
+const defaultClaimLimit = 100
+const defaultMaxAttempts = 5
+const deadItemRetention = 7 * 24 * time.Hour
+const maxRetryDelay = 1 * time.Hour
 
+// WorkerQueue is persisted in the database, so queued items survive restarts
+// and are shared across replicas.
+//
+// Items are claimed in batches of claim limit. Claimed items are hidden from
+// other processors for the visibility timeout, which is extended when each
+// item is picked for processing. Failed items are retried with backoff, and
+// moved to dead status after max attempts.
 type WorkerQueue[T any] struct {
-	m            sync.Map
-	getKey       func(item T) string
-	getGroupKey  func(item T) string
-	transform    func(item *T) *T
-	debounceTime time.Duration
-	Disabled     bool
+	name              string
+	getKey            func(item T) string
+	getGroupKey       func(item T) string
+	transform         func(item *T) *T
+	debounceTime      time.Duration
+	visibilityTimeout time.Duration
+	claimLimit        int
+	maxAttempts       int
+	Disabled          bool
 }
 
 var ErrWorkerQueueItemDelayed = errors.New("worker queue item delayed")
 
+func (q *WorkerQueue[T]) GetName() string {
+	return q.name
+}
+
+func (q *WorkerQueue[T]) getVisibilityTimeout() time.Duration {
+	if q.visibilityTimeout == 0 {
+		return defaultVisibilityTimeout
+	}
+	return q.visibilityTimeout
+}
+
+func (q *WorkerQueue[T]) getClaimLimit() int {
+	if q.claimLimit == 0 {
+		return defaultClaimLimit
+	}
+	return q.claimLimit
+}
+
+func (q *WorkerQueue[T]) getMaxAttempts() int {
+	if q.maxAttempts == 0 {
+		return defaultMaxAttempts
+	}
+	return q.maxAttempts
+}
+
+func (q *WorkerQueue[T]) getRetryDelay(attempts int) time.Duration {
+	delay := max(q.debounceTime, time.Minute)
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
 func (q *WorkerQueue[T]) Queue(item T) {
 	if q.Disabled {
 		return
 	}
 	item = *q.transform(&item)
-	q.m.Swap(q.getKey(item), WorkerQueueItem[T]{
-		v: item,
-		t: time.Now().Add(q.debounceTime),
-	})
+	value, err := json.Marshal(item)
+	if err != nil {
+		log.Error("WorkerQueue failed to encode item", "error", err, "queue", q.name)
+		return
+	}
+	key, groupKey := q.getKey(item), ""
+	if q.getGroupKey != nil {
+		groupKey = q.getGroupKey(item)
+	}
+	if err := enqueue(q.name, key, groupKey, string(value), time.Now().Add(q.debounceTime)); err != nil {
+		log.Error("WorkerQueue failed to queue item", "error", err, "queue", q.name, "key", key)
+	}
+}
+
+type claimedQueueItem[T any] struct {
+	claimedItem
+	v T
+}
+
+func (q *WorkerQueue[T]) claim() []claimedQueueItem[T] {
+	if err := purgeDead(q.name, deadItemRetention); err != nil {
+		log.Error("WorkerQueue failed to purge dead items", "error", err, "queue", q.name)
+	}
+
+	cItems, err := claim(q.name, q.getVisibilityTimeout(), q.getClaimLimit())
+	if err != nil {
+		log.Error("WorkerQueue failed to claim items", "error", err, "queue", q.name)
+		return nil
+	}
+
+	items := make([]claimedQueueItem[T], 0, len(cItems))
+	for _, cItem := range cItems {
+		item := claimedQueueItem[T]{claimedItem: cItem}
+		if err := json.Unmarshal([]byte(cItem.value), &item.v); err != nil {
+			q.fail(cItem, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
 }
 
-func (q *WorkerQueue[T]) delete(item T) {
-	q.m.Delete(q.getKey(item))
+// extend resets the visibility timeout of the item, so that it is not
+// claimed again while the items before it in the batch were processed.
+func (q *WorkerQueue[T]) extend(item claimedItem) {
+	if err := extend(q.name, item.key, time.Now().Add(q.getVisibilityTimeout())); err != nil {
+		log.Error("WorkerQueue failed to extend item", "error", err, "queue", q.name, "key", item.key)
+	}
+}
+
+func (q *WorkerQueue[T]) done(item claimedItem) {
+	if err := complete(q.name, item.key); err != nil {
+		log.Error("WorkerQueue failed to complete item", "error", err, "queue", q.name, "key", item.key)
+	}
+}
+
+func (q *WorkerQueue[T]) fail(item claimedItem, cause error) {
+	var err error
+	if cause == ErrWorkerQueueItemDelayed {
+		log.Debug("WorkerQueue process delayed", "queue", q.name, "key", item.key)
+		// delayed attempts are not counted
+		err = release(q.name, item.key, ItemStatusPending, -1, "", time.Now().Add(q.debounceTime))
+	} else if item.attempts >= q.getMaxAttempts() {
+		log.Error("WorkerQueue process failed, moved to dead", "error", cause, "queue", q.name, "key", item.key, "attempts", item.attempts)
+		err = release(q.name, item.key, ItemStatusDead, 0, cause.Error(), time.Now())
+	} else {
+		log.Error("WorkerQueue process failed", "error", cause, "queue", q.name, "key", item.key, "attempts", item.attempts)
+		err = release(q.name, item.key, ItemStatusPending, 0, cause.Error(), time.Now().Add(q.getRetryDelay(item.attempts)))
+	}
+	if err != nil {
+		log.Error("WorkerQueue failed to release item", "error", err, "queue", q.name, "key", item.key)
+	}
 }
 
 func (q *WorkerQueue[T]) Process(f func(item T) error) {
-	q.m.Range(func(k, v any) bool {
-		_, keyOk := k.(string)
-		val, valOk := v.(WorkerQueueItem[T])
-		if keyOk && valOk && val.t.Before(time.Now()) {
-			if err := f(val.v); err != nil {
-				if err == ErrWorkerQueueItemDelayed {
-					log.Debug("WorkerQueue process delayed", "key", q.getKey(val.v))
-				} else {
-					log.Error("WorkerQueue process failed", "error", err, "key", q.getKey(val.v))
-				}
-			} else {
-				q.delete(val.v)
-			}
+	for _, item := range q.claim() {
+		q.extend(item.claimedItem)
+		if err := f(item.v); err != nil {
+			q.fail(item.claimedItem, err)
+		} else {
+			q.done(item.claimedItem)
 		}
-		return true
-	})
+	}
 }
 
 func (q *WorkerQueue[T]) ProcessGroup(f func(groupKey string, items []T) error) {
-	byGroupKey := map[string][]T{}
-	q.m.Range(func(k, v any) bool {
-		_, keyOk := k.(string)
-		val, valOk := v.(WorkerQueueItem[T])
-		if keyOk && valOk && val.t.Before(time.Now()) {
-			groupKey := q.getGroupKey(val.v)
-			if _, ok := byGroupKey[groupKey]; !ok {
-				byGroupKey[groupKey] = []T{}
-			}
-			byGroupKey[groupKey] = append(byGroupKey[groupKey], val.v)
+	byGroupKey := map[string][]claimedQueueItem[T]{}
+	for _, item := range q.claim() {
+		byGroupKey[item.groupKey] = append(byGroupKey[item.groupKey], item)
+	}
+	for groupKey, cItems := range byGroupKey {
+		items := make([]T, len(cItems))
+		for i := range cItems {
+			q.extend(cItems[i].claimedItem)
+			items[i] = cItems[i].v
 		}
-		return true
-	})
-	for groupKey, items := range byGroupKey {
 		if err := f(groupKey, items); err != nil {
-			if err == ErrWorkerQueueItemDelayed {
-				log.Debug("WorkerQueue processGroup delayed", "group_key", groupKey)
-			} else {
-				log.Error("WorkerQueue processGroup failed", "error", err, "group_key", groupKey)
+			for i := range cItems {
+				q.fail(cItems[i].claimedItem, err)
 			}
 		} else {
-			for i := range items {
-				q.delete(items[i])
+			for i := range cItems {
+				q.done(cItems[i].claimedItem)
 			}
 		}
 	}
 }
+
+var queueNames = []string{}
+
+// GetQueueNames returns the names of all the worker queues.
+func GetQueueNames() []string {
+	return queueNames
+}
+
+func newQueue[T any](q WorkerQueue[T]) *WorkerQueue[T] {
+	queueNames = append(queueNames, q.name)
+	return &q
+}
@@ -0,0 +1,19 @@
+package worker_queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	q := WorkerQueue[struct{}]{debounceTime: 5 * time.Minute}
+	assert.Equal(t, 5*time.Minute, q.getRetryDelay(1))
+	assert.Equal(t, 10*time.Minute, q.getRetryDelay(2))
+	assert.Equal(t, 40*time.Minute, q.getRetryDelay(4))
+	assert.Equal(t, maxRetryDelay, q.getRetryDelay(5))
+
+	q = WorkerQueue[struct{}]{debounceTime: 10 * time.Second}
+	assert.Equal(t, 1*time.Minute, q.getRetryDelay(1))
+}
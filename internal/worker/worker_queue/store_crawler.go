@@ -5,16 +5,22 @@ import (
 )
 
 type StoreCrawlerQueueItem struct {
-	StoreCode  string
-	StoreToken string
+	StoreCode string
+	// StoreUser is the user in `config.StoreAuthToken`, the store token
+	// itself is not persisted in the queue.
+	StoreUser string
 }
 
-var StoreCrawlerQueue = WorkerQueue[StoreCrawlerQueueItem]{
+var StoreCrawlerQueue = newQueue(WorkerQueue[StoreCrawlerQueueItem]{
+	name:         "store-crawler",
 	debounceTime: 15 * time.Minute,
+	// crawling a large library takes a while
+	visibilityTimeout: 30 * time.Minute,
+	claimLimit:        10,
 	getKey: func(item StoreCrawlerQueueItem) string {
-		return item.StoreCode + ":" + item.StoreToken
+		return item.StoreCode + ":" + item.StoreUser
 	},
 	transform: func(item *StoreCrawlerQueueItem) *StoreCrawlerQueueItem {
 		return item
 	},
-}
+})
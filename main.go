@@ -21,6 +21,7 @@ func main() {
 			string(store.StoreNameOffcloud),
 			string(store.StoreNamePikPak),
 			string(store.StoreNamePremiumize),
+			string(store.StoreNameQBittorrent),
 			string(store.StoreNameRealDebrid),
 			string(store.StoreNameTorBox),
 		},
@@ -45,6 +46,8 @@ func main() {
 	endpoint.AddStremioEndpoints(mux)
 	endpoint.AddTorrentEndpoints(mux)
 	endpoint.AddTorznabEndpoints(mux)
+	endpoint.AddNewznabEndpoints(mux)
+	endpoint.AddAdminEndpoints(mux)
 	endpoint.AddExperimentEndpoints(mux)
 
 	handler := shared.RootServerContext(mux)
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS "public"."nzb_info" (
+    "hash" text NOT NULL,
+    "name" text NOT NULL,
+    "size" bigint NOT NULL DEFAULT -1,
+    "link" text NOT NULL DEFAULT '',
+    "src" text NOT NULL DEFAULT '',
+    "category" text NOT NULL DEFAULT '',
+    "imdb_id" text NOT NULL DEFAULT '',
+    "title" text NOT NULL DEFAULT '',
+    "year" int NOT NULL DEFAULT 0,
+    "seasons" text NOT NULL DEFAULT '',
+    "episodes" text NOT NULL DEFAULT '',
+    "resolution" text NOT NULL DEFAULT '',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("hash")
+);
+
+CREATE INDEX IF NOT EXISTS "nzb_info_idx_imdb_id" ON "public"."nzb_info" ("imdb_id");
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS "nzb_info_idx_imdb_id";
+DROP TABLE IF EXISTS "public"."nzb_info";
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS "public"."worker_queue_item" (
+    "queue" text NOT NULL,
+    "item_key" text NOT NULL,
+    "group_key" text NOT NULL DEFAULT '',
+    "value" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'pending',
+    "attempts" int NOT NULL DEFAULT 0,
+    "error" text NOT NULL DEFAULT '',
+    "run_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("queue", "item_key")
+);
+
+CREATE INDEX IF NOT EXISTS "worker_queue_item_idx_queue_status_run_at" ON "public"."worker_queue_item" ("queue", "status", "run_at");
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS "worker_queue_item_idx_queue_status_run_at";
+DROP TABLE IF EXISTS "public"."worker_queue_item";
+
+-- +goose StatementEnd
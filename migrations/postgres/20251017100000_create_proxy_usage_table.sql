@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS "public"."proxy_usage" (
+    "username" text NOT NULL,
+    "date" date NOT NULL,
+    "bytes" bigint NOT NULL DEFAULT 0,
+    "conns" int NOT NULL DEFAULT 0,
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("username", "date")
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS "public"."proxy_usage";
+
+-- +goose StatementEnd
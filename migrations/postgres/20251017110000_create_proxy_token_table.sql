@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS "public"."proxy_token" (
+    "id" text NOT NULL,
+    "username" text NOT NULL,
+    "device" text NOT NULL DEFAULT '',
+    "link" text NOT NULL DEFAULT '',
+    "ip_range" text NOT NULL DEFAULT '',
+    "eat" timestamptz,
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "proxy_token_idx_username_device" ON "public"."proxy_token" ("username", "device");
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS "proxy_token_idx_username_device";
+DROP TABLE IF EXISTS "public"."proxy_token";
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS `nzb_info` (
+    `hash` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `size` int NOT NULL DEFAULT -1,
+    `link` varchar NOT NULL DEFAULT '',
+    `src` varchar NOT NULL DEFAULT '',
+    `category` varchar NOT NULL DEFAULT '',
+    `imdb_id` varchar NOT NULL DEFAULT '',
+    `title` varchar NOT NULL DEFAULT '',
+    `year` int NOT NULL DEFAULT 0,
+    `seasons` varchar NOT NULL DEFAULT '',
+    `episodes` varchar NOT NULL DEFAULT '',
+    `resolution` varchar NOT NULL DEFAULT '',
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`hash`)
+);
+
+CREATE INDEX IF NOT EXISTS `nzb_info_idx_imdb_id` ON `nzb_info` (`imdb_id`);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS `nzb_info_idx_imdb_id`;
+DROP TABLE IF EXISTS `nzb_info`;
+
+-- +goose StatementEnd
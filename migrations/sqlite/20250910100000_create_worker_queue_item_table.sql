@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS `worker_queue_item` (
+    `queue` varchar NOT NULL,
+    `item_key` varchar NOT NULL,
+    `group_key` varchar NOT NULL DEFAULT '',
+    `value` varchar NOT NULL,
+    `status` varchar NOT NULL DEFAULT 'pending',
+    `attempts` int NOT NULL DEFAULT 0,
+    `error` varchar NOT NULL DEFAULT '',
+    `run_at` datetime NOT NULL DEFAULT (unixepoch()),
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`queue`, `item_key`)
+);
+
+CREATE INDEX IF NOT EXISTS `worker_queue_item_idx_queue_status_run_at` ON `worker_queue_item` (`queue`, `status`, `run_at`);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS `worker_queue_item_idx_queue_status_run_at`;
+DROP TABLE IF EXISTS `worker_queue_item`;
+
+-- +goose StatementEnd
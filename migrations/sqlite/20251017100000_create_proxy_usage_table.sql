@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS `proxy_usage` (
+    `username` varchar NOT NULL,
+    `date` date NOT NULL,
+    `bytes` int NOT NULL DEFAULT 0,
+    `conns` int NOT NULL DEFAULT 0,
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`username`, `date`)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS `proxy_usage`;
+
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS `proxy_token` (
+    `id` varchar NOT NULL,
+    `username` varchar NOT NULL,
+    `device` varchar NOT NULL DEFAULT '',
+    `link` varchar NOT NULL DEFAULT '',
+    `ip_range` varchar NOT NULL DEFAULT '',
+    `eat` datetime,
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE INDEX IF NOT EXISTS `proxy_token_idx_username_device` ON `proxy_token` (`username`, `device`);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS `proxy_token_idx_username_device`;
+DROP TABLE IF EXISTS `proxy_token`;
+
+-- +goose StatementEnd
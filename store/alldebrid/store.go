@@ -233,6 +233,12 @@ func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnet
 		Status:  statusCodeToMagnetStatus(magnet.StatusCode),
 		Files:   []store.MagnetFile{},
 		AddedAt: magnet.GetAddedAt(),
+		Seeders: magnet.Seeders,
+	}
+	if data.Status == store.MagnetStatusDownloaded {
+		data.SetProgress(100, 0, 0)
+	} else if magnet.Size > 0 {
+		data.SetProgress(float64(magnet.Downloaded)*100/float64(magnet.Size), int64(magnet.DownloadSpeed), 0)
 	}
 
 	for _, f := range magnet.GetFiles() {
@@ -335,3 +341,208 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 
 	return data, nil
 }
+
+func (c *StoreClient) listUserLinks(ctx store.Ctx) ([]UserLink, error) {
+	resRecent, errRecent := c.client.GetRecentUserLinks(&GetRecentUserLinksParams{
+		Ctx: ctx,
+	})
+	if errRecent != nil {
+		return nil, errRecent
+	}
+	resSaved, errSaved := c.client.GetSavedUserLinks(&GetSavedUserLinksParams{
+		Ctx: ctx,
+	})
+	if errSaved != nil {
+		return nil, errSaved
+	}
+
+	links := []UserLink{}
+	seenLink := map[string]struct{}{}
+	for _, link := range append(resRecent.Data, resSaved.Data...) {
+		if link.Host == "error" || link.Host == "magnet" {
+			continue
+		}
+		if _, seen := seenLink[link.Link]; seen {
+			continue
+		}
+		seenLink[link.Link] = struct{}{}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (l *UserLink) toStoreWebDLFiles() []store.WebDLFile {
+	if l.LinkDL == "" {
+		return []store.WebDLFile{}
+	}
+	return []store.WebDLFile{
+		{
+			Idx:  0,
+			Link: l.Link,
+			Name: l.Filename,
+			Size: l.GetSize(),
+		},
+	}
+}
+
+func (l *UserLink) getWebDLStatus() store.WebDLStatus {
+	if l.LinkDL != "" {
+		return store.MagnetStatusDownloaded
+	}
+	return store.MagnetStatusUnknown
+}
+
+func (c *StoreClient) AddWebDL(params *store.AddWebDLParams) (*store.AddWebDLData, error) {
+	res, err := c.client.UnlockLink(&UnlockLinkParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+		UserIP:   params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.client.SaveUserLinks(&SaveUserLinksParams{
+		Ctx:   params.Ctx,
+		Links: []string{params.Link},
+	}); err != nil {
+		return nil, err
+	}
+	data := &store.AddWebDLData{
+		Id:      params.Link,
+		Link:    params.Link,
+		Name:    res.Data.Filename,
+		Size:    int64(res.Data.Filesize),
+		Status:  store.MagnetStatusQueued,
+		Files:   []store.WebDLFile{},
+		AddedAt: time.Now().UTC(),
+	}
+	if res.Data.Delayed == 0 {
+		data.Status = store.MagnetStatusDownloaded
+		data.Files = append(data.Files, store.WebDLFile{
+			Idx:  0,
+			Link: params.Link,
+			Name: res.Data.Filename,
+			Path: res.Data.GetPath(),
+			Size: int64(res.Data.Filesize),
+		})
+	}
+	return data, nil
+}
+
+func (c *StoreClient) GetWebDL(params *store.GetWebDLParams) (*store.GetWebDLData, error) {
+	links, err := c.listUserLinks(params.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		link := &links[i]
+		if link.Link != params.Id {
+			continue
+		}
+		data := &store.GetWebDLData{
+			Id:      link.Link,
+			Name:    link.Filename,
+			Size:    link.GetSize(),
+			Status:  link.getWebDLStatus(),
+			Files:   link.toStoreWebDLFiles(),
+			AddedAt: link.GetDate(),
+		}
+		return data, nil
+	}
+	error := core.NewAPIError("not found")
+	error.StatusCode = http.StatusNotFound
+	error.StoreName = string(store.StoreNameAlldebrid)
+	return nil, error
+}
+
+func (c *StoreClient) ListWebDLs(params *store.ListWebDLsParams) (*store.ListWebDLsData, error) {
+	links, err := c.listUserLinks(params.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalItems := len(links)
+	startIdx := min(params.Offset, totalItems)
+	endIdx := min(startIdx+params.Limit, totalItems)
+
+	data := &store.ListWebDLsData{
+		Items:      []store.ListWebDLsDataItem{},
+		TotalItems: totalItems,
+	}
+	for i := range links[startIdx:endIdx] {
+		link := &links[startIdx+i]
+		data.Items = append(data.Items, store.ListWebDLsDataItem{
+			Id:      link.Link,
+			Name:    link.Filename,
+			Size:    link.GetSize(),
+			Status:  link.getWebDLStatus(),
+			Files:   link.toStoreWebDLFiles(),
+			AddedAt: link.GetDate(),
+		})
+	}
+	return data, nil
+}
+
+func (c *StoreClient) RemoveWebDL(params *store.RemoveWebDLParams) (*store.RemoveWebDLData, error) {
+	_, err := c.client.DeleteUserLinks(&DeleteUserLinksParams{
+		Ctx:   params.Ctx,
+		Links: []string{params.Id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveWebDLData{Id: params.Id}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateWebDLLink(params *store.GenerateWebDLLinkParams) (*store.GenerateLinkData, error) {
+	res, err := c.client.UnlockLink(&UnlockLinkParams{
+		Ctx:    params.Ctx,
+		Link:   params.Link,
+		UserIP: params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.GenerateLinkData{}
+	if res.Data.Link != "" {
+		if !core.HasVideoExtension(res.Data.Filename) {
+			error := core.NewAPIError("no video file found")
+			error.StatusCode = http.StatusUnprocessableEntity
+			error.StoreName = string(store.StoreNameAlldebrid)
+			return nil, error
+		}
+		data.Link = res.Data.Link
+		return data, nil
+	}
+
+	if len(res.Data.Streams) > 0 {
+		var stream *UnlockLinkDataStream
+		for i := range res.Data.Streams {
+			s := &res.Data.Streams[i]
+			if !core.HasVideoExtension("." + s.Ext) {
+				continue
+			}
+			stream = s
+		}
+		if stream == nil {
+			error := core.NewAPIError("no video stream found")
+			error.StatusCode = http.StatusUnprocessableEntity
+			error.StoreName = string(store.StoreNameAlldebrid)
+			return nil, error
+		}
+		sRes, err := c.client.GetStreamingLink(&GetStreamingLinkParams{
+			Ctx:    params.Ctx,
+			Id:     res.Data.Id,
+			Stream: stream.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+		data.Link = sRes.Data.Link
+	}
+
+	return data, nil
+}
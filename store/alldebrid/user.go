@@ -2,6 +2,7 @@ package alldebrid
 
 import (
 	"encoding/json"
+	"net/url"
 	"time"
 )
 
@@ -79,3 +80,35 @@ func (c APIClient) GetSavedUserLinks(params *GetSavedUserLinksParams) (APIRespon
 	res, err := c.Request("GET", "/v4/user/links", params, response)
 	return newAPIResponse(res, response.Data.Links), err
 }
+
+type SaveUserLinksData struct {
+	Message string `json:"message"`
+}
+
+type SaveUserLinksParams struct {
+	Ctx
+	Links []string
+}
+
+func (c APIClient) SaveUserLinks(params *SaveUserLinksParams) (APIResponse[SaveUserLinksData], error) {
+	params.Form = &url.Values{"links[]": params.Links}
+	response := &Response[SaveUserLinksData]{}
+	res, err := c.Request("GET", "/v4/user/links/save", params, response)
+	return newAPIResponse(res, response.Data), err
+}
+
+type DeleteUserLinksData struct {
+	Message string `json:"message"`
+}
+
+type DeleteUserLinksParams struct {
+	Ctx
+	Links []string
+}
+
+func (c APIClient) DeleteUserLinks(params *DeleteUserLinksParams) (APIResponse[DeleteUserLinksData], error) {
+	params.Form = &url.Values{"links[]": params.Links}
+	response := &Response[DeleteUserLinksData]{}
+	res, err := c.Request("GET", "/v4/user/links/delete", params, response)
+	return newAPIResponse(res, response.Data), err
+}
@@ -189,6 +189,7 @@ func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnet
 	} else if t.DownloadPercent < 100 {
 		data.Status = store.MagnetStatusDownloading
 	}
+	data.SetProgress(float64(t.DownloadPercent), int64(t.DownloadSpeed), 0)
 	for idx, f := range t.Files {
 		file := &store.MagnetFile{
 			Idx:  idx,
@@ -288,3 +289,149 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 	data := &store.GenerateLinkData{Link: params.Link}
 	return data, nil
 }
+
+func (l *DownloaderLink) getWebDLStatus() store.WebDLStatus {
+	if l.Expired {
+		return store.MagnetStatusFailed
+	}
+	if l.DownloadUrl != "" {
+		return store.MagnetStatusDownloaded
+	}
+	return store.MagnetStatusQueued
+}
+
+func (l *DownloaderLink) toStoreWebDLFiles() []store.WebDLFile {
+	if l.DownloadUrl == "" {
+		return []store.WebDLFile{}
+	}
+	return []store.WebDLFile{
+		{
+			Idx:  0,
+			Link: l.DownloadUrl,
+			Name: l.Name,
+			Size: l.Size,
+		},
+	}
+}
+
+func (c *StoreClient) AddWebDL(params *store.AddWebDLParams) (*store.AddWebDLData, error) {
+	res, err := c.client.AddDownloaderLink(&AddDownloaderLinkParams{
+		Ctx:      params.Ctx,
+		Url:      params.Link,
+		Password: params.Password,
+		IP:       params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	l := &res.Data
+	data := &store.AddWebDLData{
+		Id:      l.Id,
+		Link:    params.Link,
+		Name:    l.Name,
+		Size:    l.Size,
+		Status:  l.getWebDLStatus(),
+		Files:   l.toStoreWebDLFiles(),
+		AddedAt: l.GetAddedAt(),
+	}
+	return data, nil
+}
+
+func (c *StoreClient) GetWebDL(params *store.GetWebDLParams) (*store.GetWebDLData, error) {
+	res, err := c.client.ListDownloaderLinks(&ListDownloaderLinksParams{
+		Ctx: params.Ctx,
+		Ids: []string{params.Id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Data.Value) == 0 {
+		error := core.NewAPIError("not found")
+		error.StatusCode = http.StatusNotFound
+		error.StoreName = string(store.StoreNameDebridLink)
+		return nil, error
+	}
+	l := &res.Data.Value[0]
+	data := &store.GetWebDLData{
+		Id:      l.Id,
+		Name:    l.Name,
+		Size:    l.Size,
+		Status:  l.getWebDLStatus(),
+		Files:   l.toStoreWebDLFiles(),
+		AddedAt: l.GetAddedAt(),
+	}
+	return data, nil
+}
+
+func (c *StoreClient) ListWebDLs(params *store.ListWebDLsParams) (*store.ListWebDLsData, error) {
+	data := &store.ListWebDLsData{
+		Items:      []store.ListWebDLsDataItem{},
+		TotalItems: 0,
+	}
+	totalPages := 0
+
+	limit := LIST_DOWNLOADER_LINKS_PER_PAGE_MAX
+	page := params.Offset / limit
+	offsetInPage := params.Offset % limit
+	remainingItems := params.Limit
+	hasMore := true
+	for hasMore {
+		res, err := c.client.ListDownloaderLinks(&ListDownloaderLinksParams{
+			Ctx:     params.Ctx,
+			PerPage: limit,
+			Page:    page,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resItems := res.Data.Value
+		totalPages = res.Data.Pagination.Pages
+		if len(resItems) == 0 {
+			break
+		}
+
+		if offsetInPage != 0 {
+			resItems = resItems[min(offsetInPage, len(resItems)):]
+			offsetInPage = 0
+		}
+		totalResItems := len(resItems)
+
+		for i := range resItems[:min(totalResItems, remainingItems)] {
+			l := &resItems[i]
+			data.Items = append(data.Items, store.ListWebDLsDataItem{
+				Id:      l.Id,
+				Name:    l.Name,
+				Size:    l.Size,
+				Status:  l.getWebDLStatus(),
+				Files:   l.toStoreWebDLFiles(),
+				AddedAt: l.GetAddedAt(),
+			})
+		}
+
+		page++
+		remainingItems -= totalResItems
+		hasMore = page < totalPages && remainingItems > 0
+	}
+
+	data.TotalItems = totalPages * limit
+
+	return data, nil
+}
+
+func (c *StoreClient) RemoveWebDL(params *store.RemoveWebDLParams) (*store.RemoveWebDLData, error) {
+	_, err := c.client.RemoveDownloaderLinks(&RemoveDownloaderLinksParams{
+		Ctx: params.Ctx,
+		Ids: []string{params.Id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveWebDLData{Id: params.Id}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateWebDLLink(params *store.GenerateWebDLLinkParams) (*store.GenerateLinkData, error) {
+	data := &store.GenerateLinkData{Link: params.Link}
+	return data, nil
+}
@@ -8,3 +8,17 @@ var ErrorInvalidStoreName = func(name string) *core.StoreError {
 	err.StoreName = name
 	return err
 }
+
+var ErrorNewsNotSupported = func(name StoreName) *core.StoreError {
+	err := core.NewStoreError("usenet not supported")
+	err.Code = core.ErrorCodeNotImplemented
+	err.StoreName = string(name)
+	return err
+}
+
+var ErrorWebDLNotSupported = func(name StoreName) *core.StoreError {
+	err := core.NewStoreError("web download not supported")
+	err.Code = core.ErrorCodeNotImplemented
+	err.StoreName = string(name)
+	return err
+}
@@ -0,0 +1,33 @@
+package multi
+
+import (
+	"errors"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+func errorNoMemberStore() *core.StoreError {
+	err := core.NewStoreError("no store configured")
+	err.Code = core.ErrorCodeBadRequest
+	err.StoreName = string(store.StoreNameMulti)
+	return err
+}
+
+func errorFileNotFound(name store.StoreName) *core.StoreError {
+	err := core.NewStoreError("file not found")
+	err.Code = core.ErrorCodeNotFound
+	err.StoreName = string(name)
+	return err
+}
+
+func toStoreError(code store.StoreCode, err error) store.ListMagnetsDataStoreError {
+	serr := store.ListMagnetsDataStoreError{Code: code, Message: err.Error()}
+	var e core.StremThruError
+	if errors.As(err, &e) {
+		e.Pack(nil)
+		serr.ErrorCode = e.GetError().Code
+		serr.Message = e.GetError().Msg
+	}
+	return serr
+}
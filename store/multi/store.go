@@ -0,0 +1,479 @@
+package multi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/buddy"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+type StoreMember struct {
+	Store store.Store
+	Token string
+}
+
+type StoreClientConfig struct {
+	Members []StoreMember
+}
+
+// StoreClient fans out to the configured member stores, in order of
+// preference. Ids and links returned by it carry the code of the member
+// store they belong to.
+type StoreClient struct {
+	Name    store.StoreName
+	members []StoreMember
+}
+
+func NewStoreClient(config *StoreClientConfig) *StoreClient {
+	c := &StoreClient{}
+	c.Name = store.StoreNameMulti
+	c.members = config.Members
+
+	return c
+}
+
+func (c *StoreClient) GetName() store.StoreName {
+	return c.Name
+}
+
+func (c *StoreClient) getMember(code store.StoreCode) (*StoreMember, error) {
+	for i := range c.members {
+		m := &c.members[i]
+		if m.Store.GetName().Code() == code {
+			return m, nil
+		}
+	}
+	return nil, store.ErrorInvalidStoreName(string(code))
+}
+
+func (m *StoreMember) ctx(ctx store.Ctx) store.Ctx {
+	return store.Ctx{APIKey: m.Token, Context: ctx.Context}
+}
+
+func toId(code store.StoreCode, id string) string {
+	return string(code) + ":" + id
+}
+
+func parseId(id string) (code store.StoreCode, memberId string, err error) {
+	c, memberId, ok := strings.Cut(id, ":")
+	if !ok || memberId == "" {
+		err := core.NewAPIError("invalid id")
+		err.StatusCode = http.StatusBadRequest
+		return "", "", err
+	}
+	return store.StoreCode(c), memberId, nil
+}
+
+type lockedFileLinkData struct {
+	Store store.StoreCode `json:"s"`
+	Hash  string          `json:"h"`
+	Name  string          `json:"n"`
+	Size  int64           `json:"z"`
+	Link  string          `json:"l"`
+}
+
+type LockedFileLink string
+
+const lockedFileLinkPrefix = "stremthru://store/multi/"
+
+func (l LockedFileLink) create(code store.StoreCode, hash string, f *store.MagnetFile) string {
+	blob, _ := json.Marshal(lockedFileLinkData{
+		Store: code,
+		Hash:  hash,
+		Name:  f.Name,
+		Size:  f.Size,
+		Link:  f.Link,
+	})
+	return lockedFileLinkPrefix + core.Base64EncodeByte(blob)
+}
+
+func (l LockedFileLink) parse() (*lockedFileLinkData, error) {
+	encoded, ok := strings.CutPrefix(string(l), lockedFileLinkPrefix)
+	if !ok {
+		err := core.NewAPIError("invalid link")
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+	blob, err := core.Base64Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data := &lockedFileLinkData{}
+	if err := json.Unmarshal([]byte(blob), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func lockFiles(code store.StoreCode, hash string, files []store.MagnetFile) []store.MagnetFile {
+	for i := range files {
+		f := &files[i]
+		if f.Link != "" {
+			f.Link = LockedFileLink("").create(code, hash, f)
+		}
+	}
+	return files
+}
+
+// shouldFallback reports whether the next member store should be tried
+// after err. Errors caused by the request itself are not retried.
+func shouldFallback(err error) bool {
+	var e core.StremThruError
+	if !errors.As(err, &e) {
+		return true
+	}
+	e.Pack(nil)
+	switch e.GetError().Code {
+	case core.ErrorCodeBadRequest, core.ErrorCodeStoreMagnetInvalid:
+		return false
+	}
+	return true
+}
+
+func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error) {
+	var lastErr error
+	for i := range c.members {
+		m := &c.members[i]
+		user, err := m.Store.GetUser(&store.GetUserParams{Ctx: m.ctx(params.Ctx)})
+		if err == nil {
+			return user, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errorNoMemberStore()
+	}
+	return nil, lastErr
+}
+
+func (c *StoreClient) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	if len(c.members) == 0 {
+		return nil, errorNoMemberStore()
+	}
+
+	hashes := make([]string, len(params.Magnets))
+	magnetByHash := make(map[string]string, len(params.Magnets))
+	for i, m := range params.Magnets {
+		magnet, err := core.ParseMagnetLink(m)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = magnet.Hash
+		magnetByHash[magnet.Hash] = magnet.Link
+	}
+
+	results := make([]*store.CheckMagnetData, len(c.members))
+	errs := make([]error, len(c.members))
+
+	var wg sync.WaitGroup
+	for i := range c.members {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m := &c.members[i]
+			p := *params
+			p.Ctx = m.ctx(params.Ctx)
+			results[i], errs[i] = m.Store.CheckMagnet(&p)
+		}(i)
+	}
+	wg.Wait()
+
+	itemByHash := make(map[string]*store.CheckMagnetDataItem, len(hashes))
+	data := &store.CheckMagnetData{Items: make([]store.CheckMagnetDataItem, len(hashes))}
+	for i, hash := range hashes {
+		item := &data.Items[i]
+		item.Hash = hash
+		item.Magnet = magnetByHash[hash]
+		item.Status = store.MagnetStatusUnknown
+		item.Files = []store.MagnetFile{}
+		item.Stores = []store.CheckMagnetDataItemStore{}
+		itemByHash[hash] = item
+	}
+
+	hasResult := false
+	for i := range c.members {
+		if errs[i] != nil {
+			continue
+		}
+		hasResult = true
+		code := c.members[i].Store.GetName().Code()
+		for _, mItem := range results[i].Items {
+			item, ok := itemByHash[strings.ToLower(mItem.Hash)]
+			if !ok {
+				continue
+			}
+			item.Stores = append(item.Stores, store.CheckMagnetDataItemStore{
+				Code:   code,
+				Status: mItem.Status,
+			})
+			if item.Status != store.MagnetStatusCached && (mItem.Status == store.MagnetStatusCached || item.Status == store.MagnetStatusUnknown) {
+				item.Status = mItem.Status
+				item.Files = mItem.Files
+			}
+		}
+	}
+	if !hasResult {
+		return nil, errors.Join(errs...)
+	}
+
+	return data, nil
+}
+
+func (c *StoreClient) addMagnet(m *StoreMember, params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	p := *params
+	p.Ctx = m.ctx(params.Ctx)
+	data, err := m.Store.AddMagnet(&p)
+	if err != nil {
+		return nil, err
+	}
+	buddy.TrackMagnet(m.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, m.Token)
+	code := m.Store.GetName().Code()
+	data.Id = toId(code, data.Id)
+	data.Files = lockFiles(code, data.Hash, data.Files)
+	return data, nil
+}
+
+// AddMagnet adds the magnet to the first member store that has it cached,
+// falling back to the first member store that accepts it.
+func (c *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	if len(c.members) == 0 {
+		return nil, errorNoMemberStore()
+	}
+
+	members := make([]*StoreMember, 0, len(c.members))
+	checkParams := &store.CheckMagnetParams{
+		Ctx:      params.Ctx,
+		Magnets:  []string{params.Magnet},
+		ClientIP: params.ClientIP,
+	}
+	if res, err := c.CheckMagnet(checkParams); err == nil && len(res.Items) == 1 {
+		for _, s := range res.Items[0].Stores {
+			if s.Status == store.MagnetStatusCached {
+				if m, err := c.getMember(s.Code); err == nil {
+					members = append(members, m)
+				}
+			}
+		}
+	}
+	for i := range c.members {
+		m := &c.members[i]
+		if !slices.Contains(members, m) {
+			members = append(members, m)
+		}
+	}
+
+	var lastErr error
+	for _, m := range members {
+		data, err := c.addMagnet(m, params)
+		if err == nil {
+			return data, nil
+		}
+		if !shouldFallback(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnetData, error) {
+	code, id, err := parseId(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := c.getMember(code)
+	if err != nil {
+		return nil, err
+	}
+	p := *params
+	p.Ctx = m.ctx(params.Ctx)
+	p.Id = id
+	data, err := m.Store.GetMagnet(&p)
+	if err != nil {
+		return nil, err
+	}
+	buddy.TrackMagnet(m.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, m.Token)
+	data.Id = toId(code, data.Id)
+	data.Files = lockFiles(code, data.Hash, data.Files)
+	return data, nil
+}
+
+// member stores do not list more than this many magnets per request.
+const listMagnetsPageLimit = 500
+
+// listMagnets lists the first count magnets of m, paging through it.
+func (m *StoreMember) listMagnets(params *store.ListMagnetsParams, count int) (*store.ListMagnetsData, error) {
+	data := &store.ListMagnetsData{
+		Items: []store.ListMagnetsDataItem{},
+	}
+	for {
+		p := *params
+		p.Ctx = m.ctx(params.Ctx)
+		p.Offset = len(data.Items)
+		p.Limit = min(count-len(data.Items), listMagnetsPageLimit)
+		page, err := m.Store.ListMagnets(&p)
+		if err != nil {
+			return nil, err
+		}
+		data.Items = append(data.Items, page.Items...)
+		data.TotalItems = page.TotalItems
+		if len(page.Items) < p.Limit || len(data.Items) >= count || len(data.Items) >= page.TotalItems {
+			return data, nil
+		}
+	}
+}
+
+// ListMagnets merges the magnets of all member stores, newest first. Member
+// stores that fail are skipped, and their errors are listed in the data.
+// As the order is across the stores, each store is paged through up to
+// offset+limit magnets.
+func (c *StoreClient) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	data := &store.ListMagnetsData{
+		Items:      []store.ListMagnetsDataItem{},
+		TotalItems: 0,
+	}
+
+	results := make([]*store.ListMagnetsData, len(c.members))
+	errs := make([]error, len(c.members))
+
+	var wg sync.WaitGroup
+	for i := range c.members {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.members[i].listMagnets(params, params.Offset+params.Limit)
+		}(i)
+	}
+	wg.Wait()
+
+	hasResult := len(c.members) == 0
+	for i := range c.members {
+		code := c.members[i].Store.GetName().Code()
+		if err := errs[i]; err != nil {
+			data.Errors = append(data.Errors, toStoreError(code, err))
+			continue
+		}
+		hasResult = true
+		for _, item := range results[i].Items {
+			item.Id = toId(code, item.Id)
+			data.Items = append(data.Items, item)
+		}
+		data.TotalItems += results[i].TotalItems
+	}
+	if !hasResult {
+		return nil, errors.Join(errs...)
+	}
+
+	slices.SortStableFunc(data.Items, func(a, b store.ListMagnetsDataItem) int {
+		return b.AddedAt.Compare(a.AddedAt)
+	})
+	totalItems := len(data.Items)
+	data.Items = data.Items[min(params.Offset, totalItems):min(params.Offset+params.Limit, totalItems)]
+	return data, nil
+}
+
+func (c *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	code, id, err := parseId(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := c.getMember(code)
+	if err != nil {
+		return nil, err
+	}
+	p := *params
+	p.Ctx = m.ctx(params.Ctx)
+	p.Id = id
+	if _, err := m.Store.RemoveMagnet(&p); err != nil {
+		return nil, err
+	}
+	return &store.RemoveMagnetData{Id: params.Id}, nil
+}
+
+func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	data, _, err := c.GenerateLinkWithStore(params)
+	return data, err
+}
+
+// GenerateLinkWithStore generates the link using the member store the file
+// belongs to. If that fails, the magnet is added to the other member stores
+// that have it cached, and the matching file is used instead. The name of
+// the member store that generated the link is also returned.
+func (c *StoreClient) GenerateLinkWithStore(params *store.GenerateLinkParams) (*store.GenerateLinkData, store.StoreName, error) {
+	lf, err := LockedFileLink(params.Link).parse()
+	if err != nil {
+		return nil, "", err
+	}
+
+	m, err := c.getMember(lf.Store)
+	if err != nil {
+		return nil, "", err
+	}
+	p := *params
+	p.Ctx = m.ctx(params.Ctx)
+	p.Link = lf.Link
+	data, err := m.Store.GenerateLink(&p)
+	if err == nil {
+		return data, m.Store.GetName(), nil
+	}
+	if !shouldFallback(err) {
+		return nil, "", err
+	}
+	lastErr := err
+
+	res, err := c.CheckMagnet(&store.CheckMagnetParams{
+		Ctx:      params.Ctx,
+		Magnets:  []string{lf.Hash},
+		ClientIP: params.ClientIP,
+	})
+	if err != nil || len(res.Items) != 1 {
+		return nil, "", lastErr
+	}
+	for _, s := range res.Items[0].Stores {
+		if s.Code == lf.Store || s.Status != store.MagnetStatusCached {
+			continue
+		}
+		m, err := c.getMember(s.Code)
+		if err != nil {
+			continue
+		}
+		data, err := c.generateFallbackLink(m, params, lf)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return data, m.Store.GetName(), nil
+	}
+	return nil, "", lastErr
+}
+
+func (c *StoreClient) generateFallbackLink(m *StoreMember, params *store.GenerateLinkParams, lf *lockedFileLinkData) (*store.GenerateLinkData, error) {
+	magnet, err := m.Store.AddMagnet(&store.AddMagnetParams{
+		Ctx:      m.ctx(params.Ctx),
+		Magnet:   lf.Hash,
+		ClientIP: params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if magnet.Status != store.MagnetStatusDownloaded {
+		return nil, errorFileNotFound(m.Store.GetName())
+	}
+	for i := range magnet.Files {
+		f := &magnet.Files[i]
+		if f.Link == "" || f.Name != lf.Name || (lf.Size > 0 && f.Size > 0 && f.Size != lf.Size) {
+			continue
+		}
+		p := *params
+		p.Ctx = m.ctx(params.Ctx)
+		p.Link = f.Link
+		return m.Store.GenerateLink(&p)
+	}
+	return nil, errorFileNotFound(m.Store.GetName())
+}
@@ -0,0 +1,164 @@
+package multi
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStore struct {
+	store.Store
+	name      store.StoreName
+	status    store.MagnetStatus
+	linkError error
+	listError error
+	// magnetCount lists as many magnets, paged by offset and limit
+	magnetCount int
+	listLimits  []int
+}
+
+func (s *testStore) GetName() store.StoreName {
+	return s.name
+}
+
+func (s *testStore) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	data := &store.CheckMagnetData{}
+	for _, m := range params.Magnets {
+		magnet, _ := core.ParseMagnetLink(m)
+		data.Items = append(data.Items, store.CheckMagnetDataItem{
+			Hash:   magnet.Hash,
+			Magnet: magnet.Link,
+			Status: s.status,
+			Files:  []store.MagnetFile{},
+		})
+	}
+	return data, nil
+}
+
+func (s *testStore) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	magnet, _ := core.ParseMagnetLink(params.Magnet)
+	return &store.AddMagnetData{
+		Id:     "1",
+		Hash:   magnet.Hash,
+		Status: store.MagnetStatusDownloaded,
+		Files: []store.MagnetFile{
+			{Idx: 0, Name: "video.mkv", Size: 100, Link: string(s.name) + "/video.mkv"},
+		},
+	}, nil
+}
+
+func (s *testStore) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	if s.listError != nil {
+		return nil, s.listError
+	}
+	if s.magnetCount > 0 {
+		s.listLimits = append(s.listLimits, params.Limit)
+		data := &store.ListMagnetsData{TotalItems: s.magnetCount}
+		for i := params.Offset; i < min(params.Offset+params.Limit, s.magnetCount); i++ {
+			data.Items = append(data.Items, store.ListMagnetsDataItem{Id: strconv.Itoa(i), Hash: testHash})
+		}
+		return data, nil
+	}
+	return &store.ListMagnetsData{
+		Items:      []store.ListMagnetsDataItem{{Id: "1", Hash: testHash, Status: s.status}},
+		TotalItems: 1,
+	}, nil
+}
+
+func (s *testStore) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	if s.linkError != nil {
+		return nil, s.linkError
+	}
+	return &store.GenerateLinkData{Link: "https://" + params.Link}, nil
+}
+
+const testHash = "0123456789abcdef0123456789abcdef01234567"
+
+func TestCheckMagnet(t *testing.T) {
+	c := NewStoreClient(&StoreClientConfig{
+		Members: []StoreMember{
+			{Store: &testStore{name: store.StoreNameRealDebrid, status: store.MagnetStatusUnknown}},
+			{Store: &testStore{name: store.StoreNameTorBox, status: store.MagnetStatusCached}},
+		},
+	})
+
+	data, err := c.CheckMagnet(&store.CheckMagnetParams{Magnets: []string{testHash}})
+	assert.NoError(t, err)
+	assert.Len(t, data.Items, 1)
+	item := data.Items[0]
+	assert.Equal(t, testHash, item.Hash)
+	assert.Equal(t, store.MagnetStatusCached, item.Status)
+	assert.Equal(t, []store.CheckMagnetDataItemStore{
+		{Code: store.StoreCodeRealDebrid, Status: store.MagnetStatusUnknown},
+		{Code: store.StoreCodeTorBox, Status: store.MagnetStatusCached},
+	}, item.Stores)
+}
+
+func TestGenerateLinkWithStore(t *testing.T) {
+	upstreamErr := core.NewUpstreamError("unavailable")
+	upstreamErr.StatusCode = 503
+
+	c := NewStoreClient(&StoreClientConfig{
+		Members: []StoreMember{
+			{Store: &testStore{name: store.StoreNameRealDebrid, status: store.MagnetStatusCached, linkError: upstreamErr}},
+			{Store: &testStore{name: store.StoreNameTorBox, status: store.MagnetStatusCached}},
+		},
+	})
+
+	link := LockedFileLink("").create(store.StoreCodeRealDebrid, testHash, &store.MagnetFile{
+		Name: "video.mkv",
+		Size: 100,
+		Link: "realdebrid/video.mkv",
+	})
+
+	lf, err := LockedFileLink(link).parse()
+	assert.NoError(t, err)
+	assert.Equal(t, store.StoreCodeRealDebrid, lf.Store)
+	assert.Equal(t, testHash, lf.Hash)
+
+	data, storeName, err := c.GenerateLinkWithStore(&store.GenerateLinkParams{Link: link})
+	assert.NoError(t, err)
+	assert.Equal(t, store.StoreNameTorBox, storeName)
+	assert.Equal(t, "https://torbox/video.mkv", data.Link)
+}
+
+func TestListMagnets(t *testing.T) {
+	upstreamErr := core.NewUpstreamError("unavailable")
+	upstreamErr.StatusCode = 503
+
+	c := NewStoreClient(&StoreClientConfig{
+		Members: []StoreMember{
+			{Store: &testStore{name: store.StoreNameRealDebrid, listError: upstreamErr}},
+			{Store: &testStore{name: store.StoreNameTorBox, status: store.MagnetStatusDownloaded}},
+		},
+	})
+
+	data, err := c.ListMagnets(&store.ListMagnetsParams{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, data.TotalItems)
+	assert.Len(t, data.Items, 1)
+	assert.Equal(t, "tb:1", data.Items[0].Id)
+	assert.Len(t, data.Errors, 1)
+	assert.Equal(t, store.StoreCodeRealDebrid, data.Errors[0].Code)
+	assert.Equal(t, "unavailable", data.Errors[0].Message)
+
+	c.members[1].Store.(*testStore).listError = upstreamErr
+	_, err = c.ListMagnets(&store.ListMagnetsParams{Limit: 10})
+	assert.Error(t, err)
+
+	t.Run("pages through members", func(t *testing.T) {
+		s := &testStore{name: store.StoreNameTorBox, magnetCount: 1200}
+		c := NewStoreClient(&StoreClientConfig{
+			Members: []StoreMember{{Store: s}},
+		})
+
+		data, err := c.ListMagnets(&store.ListMagnetsParams{Offset: 900, Limit: 500})
+		assert.NoError(t, err)
+		assert.Equal(t, 1200, data.TotalItems)
+		assert.Len(t, data.Items, 300)
+		assert.Equal(t, []int{500, 500, 400}, s.listLimits)
+	})
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"time"
+)
+
+type NewsFile struct {
+	Idx       int    `json:"index"`
+	Link      string `json:"link,omitempty"`
+	Name      string `json:"name"`
+	Path      string `json:"path,omitempty"`
+	Size      int64  `json:"size"`
+	VideoHash string `json:"video_hash,omitempty"`
+}
+
+type NewsStatus = MagnetStatus
+
+type AddNewsData struct {
+	Id      string     `json:"id"`
+	Hash    string     `json:"hash"`
+	Name    string     `json:"name"`
+	Size    int64      `json:"size"`
+	Status  NewsStatus `json:"status"`
+	Files   []NewsFile `json:"files"`
+	AddedAt time.Time  `json:"added_at"`
+}
+
+type AddNewsParams struct {
+	Ctx
+	Link     string // url of the nzb file
+	Name     string
+	Password string
+	ClientIP string
+}
+
+type GetNewsData struct {
+	Id      string     `json:"id"`
+	Hash    string     `json:"hash"`
+	Name    string     `json:"name"`
+	Size    int64      `json:"size"`
+	Status  NewsStatus `json:"status"`
+	Files   []NewsFile `json:"files"`
+	AddedAt time.Time  `json:"added_at"`
+}
+
+type GetNewsParams struct {
+	Ctx
+	Id       string
+	ClientIP string
+}
+
+type ListNewsDataItem struct {
+	Id      string     `json:"id"`
+	Hash    string     `json:"hash"`
+	Name    string     `json:"name"`
+	Size    int64      `json:"size"`
+	Status  NewsStatus `json:"status"`
+	Files   []NewsFile `json:"files,omitempty"`
+	AddedAt time.Time  `json:"added_at"`
+}
+
+type ListNewsData struct {
+	Items      []ListNewsDataItem `json:"items"`
+	TotalItems int                `json:"total_items"`
+}
+
+type ListNewsParams struct {
+	Ctx
+	Limit    int // min 1, max 500, default 100
+	Offset   int // default 0
+	ClientIP string
+}
+
+type RemoveNewsData struct {
+	Id string `json:"id"`
+}
+
+type RemoveNewsParams struct {
+	Ctx
+	Id string
+}
+
+type GenerateNewsLinkParams struct {
+	Ctx
+	Link     string
+	ClientIP string
+}
+
+// NewsStore is implemented by stores that support Usenet (NZB) downloads.
+type NewsStore interface {
+	Store
+	AddNews(params *AddNewsParams) (*AddNewsData, error)
+	GetNews(params *GetNewsParams) (*GetNewsData, error)
+	ListNews(params *ListNewsParams) (*ListNewsData, error)
+	RemoveNews(params *RemoveNewsParams) (*RemoveNewsData, error)
+	GenerateNewsLink(params *GenerateNewsLinkParams) (*GenerateLinkData, error)
+}
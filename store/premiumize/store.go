@@ -665,6 +665,11 @@ func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnet
 		Status:  getMagnetStatsForTransfer(transfer),
 		AddedAt: transfer.GetAddedAt(),
 	}
+	if transfer.Status == TransferStatusFinished {
+		data.SetProgress(100, 0, 0)
+	} else {
+		data.SetProgress(float64(transfer.Progress)*100, 0, 0)
+	}
 	if transfer.Status == TransferStatusFinished {
 		files, err := listFolderFlat(c, params.APIKey, transfer.FolderId, nil, &store.MagnetFile{
 			Path: "/",
@@ -831,3 +836,185 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 	data := &store.GenerateLinkData{Link: params.Link}
 	return data, nil
 }
+
+func (item *GetItemData) toStoreWebDLFile() store.WebDLFile {
+	return store.WebDLFile{
+		Idx:       0,
+		Link:      item.Id,
+		Name:      item.Name,
+		Size:      item.Size,
+		VideoHash: item.OpensubtitlesHash,
+	}
+}
+
+func (c *StoreClient) getWebDLItem(ctx store.Ctx, id string) (*GetItemData, error) {
+	res, err := c.client.GetItem(&GetItemParams{
+		Ctx: ctx,
+		Id:  id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res.Data, nil
+}
+
+func (c *StoreClient) AddWebDL(params *store.AddWebDLParams) (*store.AddWebDLData, error) {
+	ct_res, err := c.client.CreateTransfer(&CreateTransferParams{
+		Ctx: params.Ctx,
+		Src: params.Link,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.AddWebDLData{
+		Id:      ct_res.Data.Id,
+		Link:    params.Link,
+		Name:    ct_res.Data.Name,
+		Status:  store.MagnetStatusQueued,
+		Files:   []store.WebDLFile{},
+		AddedAt: time.Now().UTC(),
+	}
+
+	transfer, err := getTransferById(c, params.GetAPIKey(c.client.apiKey), ct_res.Data.Id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return data, nil
+	}
+	if transfer.Status != TransferStatusFinished || transfer.FileId == "" {
+		if status := getMagnetStatsForTransfer(transfer); status != store.MagnetStatusUnknown {
+			data.Status = status
+		}
+		return data, nil
+	}
+
+	item, err := c.getWebDLItem(params.Ctx, transfer.FileId)
+	if err != nil {
+		return nil, err
+	}
+	data.Id = item.Id
+	data.Name = item.Name
+	data.Size = item.Size
+	data.Status = store.MagnetStatusDownloaded
+	data.Files = append(data.Files, item.toStoreWebDLFile())
+	data.AddedAt = item.GetCreatedAt()
+	return data, nil
+}
+
+func (c *StoreClient) GetWebDL(params *store.GetWebDLParams) (*store.GetWebDLData, error) {
+	id := params.Id
+
+	transfer, err := getTransferById(c, params.GetAPIKey(c.client.apiKey), id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer != nil {
+		if transfer.Status != TransferStatusFinished || transfer.FileId == "" {
+			data := &store.GetWebDLData{
+				Id:      transfer.Id,
+				Name:    transfer.Name,
+				Status:  getMagnetStatsForTransfer(transfer),
+				Files:   []store.WebDLFile{},
+				AddedAt: transfer.GetAddedAt(),
+			}
+			return data, nil
+		}
+		id = transfer.FileId
+	}
+
+	item, err := c.getWebDLItem(params.Ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GetWebDLData{
+		Id:      item.Id,
+		Name:    item.Name,
+		Size:    item.Size,
+		Status:  store.MagnetStatusDownloaded,
+		Files:   []store.WebDLFile{item.toStoreWebDLFile()},
+		AddedAt: item.GetCreatedAt(),
+	}
+	return data, nil
+}
+
+func (c *StoreClient) ListWebDLs(params *store.ListWebDLsParams) (*store.ListWebDLsData, error) {
+	res, err := c.client.ListItems(&ListItemsParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []ListItemsDataFile{}
+	for _, f := range res.Data.Files {
+		// files inside magnet folders are not web downloads
+		if strings.HasPrefix(f.Path, "stremthru/") {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	totalItems := len(files)
+	startIdx := min(params.Offset, totalItems)
+	endIdx := min(startIdx+params.Limit, totalItems)
+
+	data := &store.ListWebDLsData{
+		Items:      []store.ListWebDLsDataItem{},
+		TotalItems: totalItems,
+	}
+	for _, f := range files[startIdx:endIdx] {
+		data.Items = append(data.Items, store.ListWebDLsDataItem{
+			Id:     f.Id,
+			Name:   f.Name,
+			Size:   f.Size,
+			Status: store.MagnetStatusDownloaded,
+			Files: []store.WebDLFile{
+				{
+					Idx:  0,
+					Link: f.Id,
+					Name: f.Name,
+					Path: f.Path,
+					Size: f.Size,
+				},
+			},
+			AddedAt: f.GetCreatedAt(),
+		})
+	}
+	return data, nil
+}
+
+func (c *StoreClient) RemoveWebDL(params *store.RemoveWebDLParams) (*store.RemoveWebDLData, error) {
+	apiKey := params.GetAPIKey(c.client.apiKey)
+	transfer, err := getTransferById(c, apiKey, params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer != nil {
+		dt_params := &DeleteTransferParams{Id: transfer.Id}
+		dt_params.APIKey = apiKey
+		if _, err := c.client.DeleteTransfer(dt_params); err != nil {
+			return nil, err
+		}
+	} else {
+		_, err := c.client.DeleteItem(&DeleteItemParams{
+			Ctx: params.Ctx,
+			Id:  params.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	data := &store.RemoveWebDLData{Id: params.Id}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateWebDLLink(params *store.GenerateWebDLLinkParams) (*store.GenerateLinkData, error) {
+	item, err := c.getWebDLItem(params.Ctx, params.Link)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GenerateLinkData{Link: item.Link}
+	return data, nil
+}
@@ -0,0 +1,25 @@
+package qbittorrent
+
+type GetVersionParams struct {
+	Ctx
+}
+
+func (c APIClient) GetVersion(params *GetVersionParams) (APIResponse[string], error) {
+	response := &Response[string]{}
+	res, err := c.Request("GET", "/api/v2/app/version", params, response)
+	return newAPIResponse(res, response.Data), err
+}
+
+type Preferences struct {
+	SavePath string `json:"save_path"`
+}
+
+type GetPreferencesParams struct {
+	Ctx
+}
+
+func (c APIClient) GetPreferences(params *GetPreferencesParams) (APIResponse[Preferences], error) {
+	response := &Response[Preferences]{}
+	res, err := c.Request("GET", "/api/v2/app/preferences", params, response)
+	return newAPIResponse(res, response.Data), err
+}
@@ -0,0 +1,96 @@
+package qbittorrent
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/request"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+const SESSION_COOKIE_NAME = "SID"
+
+type credential struct {
+	baseUrl  *url.URL
+	username string
+	password string
+}
+
+func invalidCredentialError(msg string) error {
+	err := core.NewStoreError(msg)
+	err.StoreName = string(store.StoreNameQBittorrent)
+	err.Code = core.ErrorCodeUnauthorized
+	return err
+}
+
+// parseCredential parses the token in `username:password` format. The Web UI
+// URL with credentials is also accepted, as long as it points to `baseUrl`.
+func parseCredential(baseUrl *url.URL, token string) (*credential, error) {
+	if baseUrl == nil {
+		err := core.NewStoreError("web ui url not configured")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.Code = core.ErrorCodeNotImplemented
+		return nil, err
+	}
+	cred := &credential{baseUrl: baseUrl}
+	if strings.HasPrefix(token, "http://") || strings.HasPrefix(token, "https://") {
+		u, err := url.Parse(token)
+		if err != nil || u.Host != baseUrl.Host {
+			return nil, invalidCredentialError("invalid token, web ui url does not match")
+		}
+		if u.User != nil {
+			cred.username = u.User.Username()
+			cred.password, _ = u.User.Password()
+		}
+		return cred, nil
+	}
+	username, password, ok := strings.Cut(token, ":")
+	if !ok && token != "" {
+		return nil, invalidCredentialError("invalid token, expected username:password")
+	}
+	cred.username = username
+	cred.password = password
+	return cred, nil
+}
+
+func (c APIClient) getSessionId(params request.Context, token string, cred *credential) (string, error) {
+	sid := ""
+	if c.sessionCache.Get(token, &sid) {
+		return sid, nil
+	}
+
+	ctx := &Ctx{
+		Context: params.GetContext(),
+		Form: &url.Values{
+			"username": []string{cred.username},
+			"password": []string{cred.password},
+		},
+	}
+	req, err := c.newRequest(cred.baseUrl, http.MethodPost, "/api/v2/auth/login", ctx)
+	if err != nil {
+		return "", err
+	}
+	response := &Response[string]{}
+	res, err := c.doRequest(req, response)
+	if err != nil {
+		return "", err
+	}
+	if response.Data != "Ok." {
+		err := core.NewStoreError("failed to login")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.Code = core.ErrorCodeUnauthorized
+		return "", err
+	}
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == SESSION_COOKIE_NAME {
+			sid = cookie.Value
+		}
+	}
+	// sid is empty if authentication is bypassed for the client
+	if err := c.sessionCache.Add(token, sid); err != nil {
+		log.Warn("failed to cache session", "error", err)
+	}
+	return sid, nil
+}
@@ -0,0 +1,133 @@
+package qbittorrent
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/cache"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/internal/request"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+var DefaultHTTPClient = config.DefaultHTTPClient
+
+type APIClientConfig struct {
+	BaseURL    *url.URL // Web UI URL, e.g. http://localhost:8080
+	APIKey     string   // e.g. admin:adminadmin
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type APIClient struct {
+	BaseURL    *url.URL
+	HTTPClient *http.Client
+	apiKey     string
+	agent      string
+
+	reqQuery  func(query *url.Values, params request.Context)
+	reqHeader func(query *http.Header, params request.Context)
+
+	sessionCache cache.Cache[string]
+}
+
+func NewAPIClient(conf *APIClientConfig) *APIClient {
+	if conf.UserAgent == "" {
+		conf.UserAgent = "stremthru"
+	}
+
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = DefaultHTTPClient
+	}
+
+	c := &APIClient{}
+
+	c.BaseURL = conf.BaseURL
+	c.HTTPClient = conf.HTTPClient
+	c.apiKey = conf.APIKey
+	c.agent = conf.UserAgent
+
+	c.reqQuery = func(query *url.Values, params request.Context) {
+	}
+
+	c.reqHeader = func(header *http.Header, params request.Context) {
+		header.Add("User-Agent", c.agent)
+	}
+
+	c.sessionCache = cache.NewCache[string](&cache.CacheConfig{
+		Name:     "store:qbittorrent:session",
+		Lifetime: 30 * time.Minute,
+	})
+
+	return c
+}
+
+type Ctx = request.Ctx
+
+func (c APIClient) newRequest(baseUrl *url.URL, method, path string, params request.Context) (*http.Request, error) {
+	req, err := params.NewRequest(baseUrl, method, path, c.reqHeader, c.reqQuery)
+	if err != nil {
+		error := core.NewStoreError("failed to create request")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.Cause = err
+		return nil, error
+	}
+	// required by the Web UI's CSRF protection
+	req.Header.Set("Referer", baseUrl.String())
+	return req, nil
+}
+
+func (c APIClient) doRequest(req *http.Request, v request.ResponseContainer) (*http.Response, error) {
+	res, err := c.HTTPClient.Do(req)
+	err = request.ProcessResponseBody(res, err, v)
+	if err != nil {
+		err := UpstreamErrorWithCause(err)
+		err.InjectReq(req)
+		if res != nil && res.StatusCode >= http.StatusBadRequest {
+			err.StatusCode = res.StatusCode
+		}
+		return res, err
+	}
+	return res, nil
+}
+
+func (c APIClient) Request(method, path string, params request.Context, v request.ResponseContainer) (*http.Response, error) {
+	if params == nil {
+		params = &Ctx{}
+	}
+	token := params.GetAPIKey(c.apiKey)
+	cred, err := parseCredential(c.BaseURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	sid, err := c.getSessionId(params, token, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(cred.baseUrl, method, path, params)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Cookie", SESSION_COOKIE_NAME+"="+sid)
+	res, err := c.doRequest(req, v)
+	if res == nil || res.StatusCode != http.StatusForbidden {
+		return res, err
+	}
+
+	// session expired
+	c.sessionCache.Remove(token)
+	sid, err = c.getSessionId(params, token, cred)
+	if err != nil {
+		return nil, err
+	}
+	req, err = c.newRequest(cred.baseUrl, method, path, params)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Cookie", SESSION_COOKIE_NAME+"="+sid)
+	return c.doRequest(req, v)
+}
@@ -0,0 +1,20 @@
+package qbittorrent
+
+import (
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+func UpstreamErrorWithCause(cause error) *core.UpstreamError {
+	err := core.NewUpstreamError("")
+	err.StoreName = string(store.StoreNameQBittorrent)
+
+	if rerr, ok := cause.(*ResponseError); ok {
+		err.Msg = rerr.Message
+		err.UpstreamCause = rerr
+	} else {
+		err.Cause = cause
+	}
+
+	return err
+}
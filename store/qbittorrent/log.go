@@ -0,0 +1,5 @@
+package qbittorrent
+
+import "github.com/rodezfranco/stremthru/internal/logger"
+
+var log = logger.Scoped("qbittorrent")
@@ -0,0 +1,63 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rodezfranco/stremthru/core"
+)
+
+type ResponseError struct {
+	StatusCode int    `json:"status_code"`
+	Message    string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	ret, _ := json.Marshal(e)
+	return string(ret)
+}
+
+// Response handles both the JSON and the plain text responses of the
+// qBittorrent Web API.
+type Response[T any] struct {
+	Data T
+	text string
+}
+
+func (r *Response[T]) GetError(res *http.Response) error {
+	if res.StatusCode >= http.StatusBadRequest || r.text == "Fails." {
+		return &ResponseError{StatusCode: res.StatusCode, Message: r.text}
+	}
+	return nil
+}
+
+func (r *Response[T]) Unmarshal(res *http.Response, body []byte, v any) error {
+	contentType := res.Header.Get("Content-Type")
+	if strings.Contains(contentType, "application/json") {
+		return core.UnmarshalJSON(res.StatusCode, body, &r.Data)
+	}
+	r.text = strings.TrimSpace(string(body))
+	if text, ok := any(&r.Data).(*string); ok {
+		*text = r.text
+	}
+	return nil
+}
+
+type APIResponse[T any] struct {
+	Header     http.Header
+	StatusCode int
+	Data       T
+}
+
+func newAPIResponse[T any](res *http.Response, data T) APIResponse[T] {
+	apiResponse := APIResponse[T]{
+		StatusCode: 503,
+		Data:       data,
+	}
+	if res != nil {
+		apiResponse.Header = res.Header
+		apiResponse.StatusCode = res.StatusCode
+	}
+	return apiResponse
+}
@@ -0,0 +1,382 @@
+package qbittorrent
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/core"
+	"github.com/rodezfranco/stremthru/internal/config"
+	"github.com/rodezfranco/stremthru/store"
+)
+
+func getMagnetStatus(state TorrentState) store.MagnetStatus {
+	switch state {
+	case TorrentStateError, TorrentStateMissingFiles:
+		return store.MagnetStatusFailed
+	case TorrentStateMetaDL, TorrentStateForcedMetaDL, TorrentStateQueuedDL, TorrentStateAllocating, TorrentStateCheckingResumeData:
+		return store.MagnetStatusQueued
+	case TorrentStateDownloading, TorrentStateForcedDL, TorrentStateStalledDL, TorrentStatePausedDL, TorrentStateStoppedDL, TorrentStateCheckingDL:
+		return store.MagnetStatusDownloading
+	case TorrentStateMoving:
+		return store.MagnetStatusProcessing
+	case TorrentStateUploading, TorrentStatePausedUP, TorrentStateStoppedUP, TorrentStateQueuedUP, TorrentStateStalledUP, TorrentStateCheckingUP, TorrentStateForcedUP:
+		return store.MagnetStatusDownloaded
+	default:
+		return store.MagnetStatusUnknown
+	}
+}
+
+type StoreClientConfig struct {
+	BaseURL    *url.URL
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type StoreClient struct {
+	Name   store.StoreName
+	client *APIClient
+}
+
+func NewStoreClient(config *StoreClientConfig) *StoreClient {
+	c := &StoreClient{}
+	c.client = NewAPIClient(&APIClientConfig{
+		BaseURL:    config.BaseURL,
+		HTTPClient: config.HTTPClient,
+		UserAgent:  config.UserAgent,
+	})
+	c.Name = store.StoreNameQBittorrent
+
+	return c
+}
+
+func (s *StoreClient) GetName() store.StoreName {
+	return s.Name
+}
+
+type LockedFileLink string
+
+const lockedFileLinkPrefix = "stremthru://store/qbittorrent/"
+
+func (l LockedFileLink) encodeData(hash string, fileIdx int) string {
+	return core.Base64Encode(hash + ":" + strconv.Itoa(fileIdx))
+}
+
+func (l LockedFileLink) decodeData(encoded string) (hash string, fileIdx int, err error) {
+	decoded, err := core.Base64Decode(encoded)
+	if err != nil {
+		return "", 0, err
+	}
+	hash, fIdx, found := strings.Cut(decoded, ":")
+	if !found {
+		return "", 0, core.NewError("invalid link")
+	}
+	fileIdx, err = strconv.Atoi(fIdx)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, fileIdx, nil
+}
+
+func IsLockedFileLink(link string) bool {
+	return strings.HasPrefix(link, lockedFileLinkPrefix)
+}
+
+func (l LockedFileLink) create(hash string, fileIdx int) string {
+	return lockedFileLinkPrefix + l.encodeData(hash, fileIdx)
+}
+
+func (l LockedFileLink) parse() (hash string, fileIdx int, err error) {
+	encoded := strings.TrimPrefix(string(l), lockedFileLinkPrefix)
+	return l.decodeData(encoded)
+}
+
+func (s *StoreClient) getTorrent(ctx Ctx, hash string) (*Torrent, error) {
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    ctx,
+		Hashes: []string{hash},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		err := core.NewAPIError("not found")
+		err.StatusCode = http.StatusNotFound
+		err.StoreName = string(store.StoreNameQBittorrent)
+		return nil, err
+	}
+	return &res.Data[0], nil
+}
+
+func (s *StoreClient) getFiles(ctx Ctx, t *Torrent) ([]store.MagnetFile, error) {
+	files := []store.MagnetFile{}
+	res, err := s.client.GetTorrentFiles(&GetTorrentFilesParams{
+		Ctx:  ctx,
+		Hash: t.Hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Data {
+		f := &res.Data[i]
+		file := store.MagnetFile{
+			Idx:  f.Index,
+			Name: f.GetName(),
+			Path: f.GetPath(),
+			Size: f.Size,
+		}
+		if f.Progress == 1 {
+			file.Link = LockedFileLink("").create(t.Hash, f.Index)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error) {
+	cred, err := parseCredential(s.client.BaseURL, params.GetAPIKey(s.client.apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.GetVersion(&GetVersionParams{Ctx: params.Ctx}); err != nil {
+		return nil, err
+	}
+	data := &store.User{
+		Id:                 cred.username + "@" + cred.baseUrl.Host,
+		SubscriptionStatus: store.UserSubscriptionStatusPremium,
+	}
+	return data, nil
+}
+
+// CheckMagnet reports the magnets already downloaded by the client as cached.
+func (s *StoreClient) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	magnets := make([]core.MagnetLink, len(params.Magnets))
+	hashes := make([]string, len(params.Magnets))
+	for i, m := range params.Magnets {
+		magnet, err := core.ParseMagnetLink(m)
+		if err != nil {
+			return nil, err
+		}
+		magnets[i] = magnet
+		hashes[i] = magnet.Hash
+	}
+
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    params.Ctx,
+		Hashes: hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	torrentByHash := make(map[string]*Torrent, len(res.Data))
+	for i := range res.Data {
+		t := &res.Data[i]
+		torrentByHash[strings.ToLower(t.Hash)] = t
+	}
+
+	data := &store.CheckMagnetData{
+		Items: []store.CheckMagnetDataItem{},
+	}
+	for _, magnet := range magnets {
+		item := store.CheckMagnetDataItem{
+			Hash:   magnet.Hash,
+			Magnet: magnet.Link,
+			Status: store.MagnetStatusUnknown,
+			Files:  []store.MagnetFile{},
+		}
+		if t, ok := torrentByHash[magnet.Hash]; ok && getMagnetStatus(t.State) == store.MagnetStatusDownloaded {
+			files, err := s.getFiles(params.Ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			item.Status = store.MagnetStatusCached
+			for _, f := range files {
+				item.Files = append(item.Files, store.MagnetFile{
+					Idx:  f.Idx,
+					Name: f.Name,
+					Size: f.Size,
+				})
+			}
+		}
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+func (s *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	magnet, err := core.ParseMagnetLink(params.Magnet)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.AddMagnetData{
+		Id:      magnet.Hash,
+		Hash:    magnet.Hash,
+		Magnet:  magnet.Link,
+		Name:    magnet.Name,
+		Status:  store.MagnetStatusQueued,
+		Files:   []store.MagnetFile{},
+		AddedAt: time.Now().UTC(),
+	}
+
+	t, err := s.getTorrent(params.Ctx, magnet.Hash)
+	if err != nil {
+		// torrents are added asynchronously, the id is the hash so it can
+		// be looked up later with GetMagnet
+		if _, err := s.client.AddTorrent(&AddTorrentParams{
+			Ctx:  params.Ctx,
+			URLs: []string{magnet.Link},
+		}); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	data.Name = t.Name
+	data.Size = t.GetSize()
+	data.Status = getMagnetStatus(t.State)
+	data.AddedAt = t.GetAddedAt()
+	if data.Files, err = s.getFiles(params.Ctx, t); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnetData, error) {
+	t, err := s.getTorrent(params.Ctx, params.Id)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GetMagnetData{
+		Id:      strings.ToLower(t.Hash),
+		Hash:    strings.ToLower(t.Hash),
+		Name:    t.Name,
+		Size:    t.GetSize(),
+		Status:  getMagnetStatus(t.State),
+		AddedAt: t.GetAddedAt(),
+		Seeders: t.NumSeeds,
+	}
+	data.SetProgress(t.Progress*100, t.DLSpeed, t.GetETA())
+	if data.Files, err = s.getFiles(params.Ctx, t); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *StoreClient) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totalItems := len(res.Data)
+	torrents := res.Data[min(params.Offset, totalItems):min(params.Offset+params.Limit, totalItems)]
+
+	data := &store.ListMagnetsData{
+		Items:      []store.ListMagnetsDataItem{},
+		TotalItems: totalItems,
+	}
+	for i := range torrents {
+		t := &torrents[i]
+		data.Items = append(data.Items, store.ListMagnetsDataItem{
+			Id:      strings.ToLower(t.Hash),
+			Hash:    strings.ToLower(t.Hash),
+			Name:    t.Name,
+			Size:    t.GetSize(),
+			Status:  getMagnetStatus(t.State),
+			AddedAt: t.GetAddedAt(),
+		})
+	}
+	return data, nil
+}
+
+func (s *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	_, err := s.client.DeleteTorrents(&DeleteTorrentsParams{
+		Ctx:         params.Ctx,
+		Hashes:      []string{params.Id},
+		DeleteFiles: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveMagnetData{
+		Id: params.Id,
+	}
+	return data, nil
+}
+
+type GetFilePathParams struct {
+	Ctx
+	Link string
+}
+
+// GetFilePath resolves the locked file link to the path of the completed
+// file, within the download directory.
+func (s *StoreClient) GetFilePath(params *GetFilePathParams) (string, error) {
+	hash, fileIdx, err := LockedFileLink(params.Link).parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return "", error
+	}
+
+	if !config.StoreQBittorrent.IsEnabled() {
+		err := core.NewStoreError("download directory not configured")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.Code = core.ErrorCodeNotImplemented
+		return "", err
+	}
+
+	t, err := s.getTorrent(params.Ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	res, err := s.client.GetTorrentFiles(&GetTorrentFilesParams{
+		Ctx:  params.Ctx,
+		Hash: t.Hash,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var file *TorrentFile
+	for i := range res.Data {
+		if res.Data[i].Index == fileIdx {
+			file = &res.Data[i]
+			break
+		}
+	}
+	if file == nil || file.Progress != 1 {
+		err := core.NewAPIError("file not found")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.StatusCode = http.StatusNotFound
+		return "", err
+	}
+
+	relPath, err := config.StoreQBittorrent.GetRelativePath(filepath.Join(t.SavePath, file.Name))
+	if err != nil {
+		error := core.NewStoreError("failed to resolve file path")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.Cause = err
+		return "", error
+	}
+
+	return filepath.Join(config.StoreQBittorrent.GetDownloadDir(), relPath), nil
+}
+
+// GenerateLink returns the locked file link as is, after checking that the
+// file is available. It can only be accessed through the content proxy.
+func (s *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	if _, err := s.GetFilePath(&GetFilePathParams{Ctx: params.Ctx, Link: params.Link}); err != nil {
+		return nil, err
+	}
+	data := &store.GenerateLinkData{Link: params.Link}
+	return data, nil
+}
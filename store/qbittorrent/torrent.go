@@ -0,0 +1,159 @@
+package qbittorrent
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodezfranco/stremthru/internal/util"
+)
+
+type TorrentState string
+
+const (
+	TorrentStateError              TorrentState = "error"
+	TorrentStateMissingFiles       TorrentState = "missingFiles"
+	TorrentStateUploading          TorrentState = "uploading"
+	TorrentStatePausedUP           TorrentState = "pausedUP"
+	TorrentStateStoppedUP          TorrentState = "stoppedUP"
+	TorrentStateQueuedUP           TorrentState = "queuedUP"
+	TorrentStateStalledUP          TorrentState = "stalledUP"
+	TorrentStateCheckingUP         TorrentState = "checkingUP"
+	TorrentStateForcedUP           TorrentState = "forcedUP"
+	TorrentStateAllocating         TorrentState = "allocating"
+	TorrentStateDownloading        TorrentState = "downloading"
+	TorrentStateMetaDL             TorrentState = "metaDL"
+	TorrentStateForcedMetaDL       TorrentState = "forcedMetaDL"
+	TorrentStatePausedDL           TorrentState = "pausedDL"
+	TorrentStateStoppedDL          TorrentState = "stoppedDL"
+	TorrentStateQueuedDL           TorrentState = "queuedDL"
+	TorrentStateStalledDL          TorrentState = "stalledDL"
+	TorrentStateCheckingDL         TorrentState = "checkingDL"
+	TorrentStateForcedDL           TorrentState = "forcedDL"
+	TorrentStateCheckingResumeData TorrentState = "checkingResumeData"
+	TorrentStateMoving             TorrentState = "moving"
+	TorrentStateUnknown            TorrentState = "unknown"
+)
+
+// qBittorrent reports this ETA for torrents that are not downloading.
+const infiniteETA = 8640000
+
+type Torrent struct {
+	Hash        string       `json:"hash"`
+	Name        string       `json:"name"`
+	Size        int64        `json:"size"`
+	TotalSize   int64        `json:"total_size"`
+	Progress    float64      `json:"progress"` // 0 - 1
+	DLSpeed     int64        `json:"dlspeed"`  // bytes/s
+	ETA         int64        `json:"eta"`      // seconds
+	NumSeeds    int          `json:"num_seeds"`
+	State       TorrentState `json:"state"`
+	AddedOn     int64        `json:"added_on"`
+	SavePath    string       `json:"save_path"`
+	ContentPath string       `json:"content_path"`
+	Category    string       `json:"category"`
+}
+
+func (t *Torrent) GetAddedAt() time.Time {
+	return time.Unix(t.AddedOn, 0).UTC()
+}
+
+func (t *Torrent) GetSize() int64 {
+	if t.TotalSize > 0 {
+		return t.TotalSize
+	}
+	return t.Size
+}
+
+func (t *Torrent) GetETA() int64 {
+	if t.ETA >= infiniteETA {
+		return 0
+	}
+	return t.ETA
+}
+
+type AddTorrentParams struct {
+	Ctx
+	URLs []string
+}
+
+func (c APIClient) AddTorrent(params *AddTorrentParams) (APIResponse[string], error) {
+	form := &url.Values{}
+	form.Add("urls", strings.Join(params.URLs, "\n"))
+	params.Form = form
+
+	response := &Response[string]{}
+	res, err := c.Request("POST", "/api/v2/torrents/add", params, response)
+	return newAPIResponse(res, response.Data), err
+}
+
+type ListTorrentsParams struct {
+	Ctx
+	Hashes []string
+}
+
+func (c APIClient) ListTorrents(params *ListTorrentsParams) (APIResponse[[]Torrent], error) {
+	query := &url.Values{}
+	query.Add("sort", "added_on")
+	query.Add("reverse", "true")
+	if len(params.Hashes) > 0 {
+		query.Add("hashes", strings.Join(params.Hashes, "|"))
+	}
+	params.Query = query
+
+	response := &Response[[]Torrent]{}
+	res, err := c.Request("GET", "/api/v2/torrents/info", params, response)
+	return newAPIResponse(res, response.Data), err
+}
+
+type TorrentFile struct {
+	Index    int     `json:"index"`
+	Name     string  `json:"name"` // path relative to the torrent's save path
+	Size     int64   `json:"size"`
+	Progress float64 `json:"progress"`
+	Priority int     `json:"priority"`
+}
+
+func (f *TorrentFile) GetName() string {
+	return filepath.Base(f.Name)
+}
+
+func (f *TorrentFile) GetPath() string {
+	path, _ := util.RemoveRootFolderFromPath(f.Name)
+	if path == "" {
+		path = "/" + f.Name
+	}
+	return path
+}
+
+type GetTorrentFilesParams struct {
+	Ctx
+	Hash string
+}
+
+func (c APIClient) GetTorrentFiles(params *GetTorrentFilesParams) (APIResponse[[]TorrentFile], error) {
+	params.Query = &url.Values{"hash": []string{params.Hash}}
+
+	response := &Response[[]TorrentFile]{}
+	res, err := c.Request("GET", "/api/v2/torrents/files", params, response)
+	return newAPIResponse(res, response.Data), err
+}
+
+type DeleteTorrentsParams struct {
+	Ctx
+	Hashes      []string
+	DeleteFiles bool
+}
+
+func (c APIClient) DeleteTorrents(params *DeleteTorrentsParams) (APIResponse[string], error) {
+	params.Form = &url.Values{
+		"hashes":      []string{strings.Join(params.Hashes, "|")},
+		"deleteFiles": []string{strconv.FormatBool(params.DeleteFiles)},
+	}
+
+	response := &Response[string]{}
+	res, err := c.Request("POST", "/api/v2/torrents/delete", params, response)
+	return newAPIResponse(res, response.Data), err
+}
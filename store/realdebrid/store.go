@@ -385,7 +385,9 @@ func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnet
 		Status:  torrentStatusToMagnetStatus(res.Data.Status),
 		Files:   []store.MagnetFile{},
 		AddedAt: res.Data.GetAddedAt(),
+		Seeders: res.Data.Seeders,
 	}
+	data.SetProgress(float64(res.Data.Progress), res.Data.Speed, 0)
 	totalLinks := len(res.Data.Links)
 	if data.Status == store.MagnetStatusDownloaded {
 		idx := -1
@@ -462,3 +464,148 @@ func (c *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.Rem
 	}
 	return data, nil
 }
+
+func (dl *ListDownloadsDataItem) toStoreWebDLFile() store.WebDLFile {
+	return store.WebDLFile{
+		Idx:  0,
+		Link: dl.Download,
+		Name: dl.Filename,
+		Size: dl.Filesize,
+	}
+}
+
+// downloads generated from torrents are not web downloads
+func isTorrentDownload(dl *ListDownloadsDataItem) bool {
+	return dl.Host == "real-debrid.com"
+}
+
+func (c *StoreClient) AddWebDL(params *store.AddWebDLParams) (*store.AddWebDLData, error) {
+	res, err := c.client.UnrestrictLink(&UnrestrictLinkParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+		IP:       params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.AddWebDLData{
+		Id:     res.Data.Id,
+		Link:   params.Link,
+		Name:   res.Data.Filename,
+		Size:   int64(res.Data.Filesize),
+		Status: store.MagnetStatusDownloaded,
+		Files: []store.WebDLFile{
+			{
+				Idx:  0,
+				Link: res.Data.Download,
+				Name: res.Data.Filename,
+				Size: int64(res.Data.Filesize),
+			},
+		},
+		AddedAt: time.Now().UTC(),
+	}
+	return data, nil
+}
+
+// real-debrid lists the downloads generated from torrents along with the
+// web downloads, and does not have an endpoint to get a single download. So
+// the web downloads are filtered from the listed downloads, up to this many.
+const maxListedDownloads = 2500
+
+func (c *StoreClient) listWebDLs(ctx store.Ctx) ([]ListDownloadsDataItem, error) {
+	items := []ListDownloadsDataItem{}
+	offset, limit := 0, 500
+	for offset < maxListedDownloads {
+		res, err := c.client.ListDownloads(&ListDownloadsParams{
+			Ctx:    ctx,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range res.Data {
+			if !isTorrentDownload(&res.Data[i]) {
+				items = append(items, res.Data[i])
+			}
+		}
+		if len(res.Data) < limit {
+			break
+		}
+		offset += limit
+	}
+	return items, nil
+}
+
+func (c *StoreClient) GetWebDL(params *store.GetWebDLParams) (*store.GetWebDLData, error) {
+	items, err := c.listWebDLs(params.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		dl := &items[i]
+		if dl.Id != params.Id {
+			continue
+		}
+		data := &store.GetWebDLData{
+			Id:      dl.Id,
+			Name:    dl.Filename,
+			Size:    dl.Filesize,
+			Status:  store.MagnetStatusDownloaded,
+			Files:   []store.WebDLFile{dl.toStoreWebDLFile()},
+			AddedAt: dl.Generated.UTC(),
+		}
+		return data, nil
+	}
+	error := core.NewAPIError("not found")
+	error.StatusCode = http.StatusNotFound
+	error.StoreName = string(store.StoreNameRealDebrid)
+	return nil, error
+}
+
+func (c *StoreClient) ListWebDLs(params *store.ListWebDLsParams) (*store.ListWebDLsData, error) {
+	items, err := c.listWebDLs(params.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalItems := len(items)
+	data := &store.ListWebDLsData{
+		Items:      []store.ListWebDLsDataItem{},
+		TotalItems: totalItems,
+	}
+	for _, dl := range items[min(params.Offset, totalItems):min(params.Offset+params.Limit, totalItems)] {
+		data.Items = append(data.Items, store.ListWebDLsDataItem{
+			Id:      dl.Id,
+			Name:    dl.Filename,
+			Size:    dl.Filesize,
+			Status:  store.MagnetStatusDownloaded,
+			Files:   []store.WebDLFile{dl.toStoreWebDLFile()},
+			AddedAt: dl.Generated.UTC(),
+		})
+	}
+	return data, nil
+}
+
+func (c *StoreClient) RemoveWebDL(params *store.RemoveWebDLParams) (*store.RemoveWebDLData, error) {
+	_, err := c.client.DeleteDownload(&DeleteDownloadParams{
+		Ctx: params.Ctx,
+		Id:  params.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveWebDLData{
+		Id: params.Id,
+	}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateWebDLLink(params *store.GenerateWebDLLinkParams) (*store.GenerateLinkData, error) {
+	return c.GenerateLink(&store.GenerateLinkParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		ClientIP: params.ClientIP,
+	})
+}
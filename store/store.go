@@ -12,53 +12,60 @@ type Ctx = request.Ctx
 type StoreName string
 
 const (
-	StoreNameAlldebrid  StoreName = "alldebrid"
-	StoreNameDebrider   StoreName = "debrider"
-	StoreNameDebridLink StoreName = "debridlink"
-	StoreNameEasyDebrid StoreName = "easydebrid"
-	StoreNameOffcloud   StoreName = "offcloud"
-	StoreNamePikPak     StoreName = "pikpak"
-	StoreNamePremiumize StoreName = "premiumize"
-	StoreNameRealDebrid StoreName = "realdebrid"
-	StoreNameTorBox     StoreName = "torbox"
+	StoreNameAlldebrid   StoreName = "alldebrid"
+	StoreNameDebrider    StoreName = "debrider"
+	StoreNameDebridLink  StoreName = "debridlink"
+	StoreNameEasyDebrid  StoreName = "easydebrid"
+	StoreNameOffcloud    StoreName = "offcloud"
+	StoreNamePikPak      StoreName = "pikpak"
+	StoreNamePremiumize  StoreName = "premiumize"
+	StoreNameQBittorrent StoreName = "qbittorrent"
+	StoreNameRealDebrid  StoreName = "realdebrid"
+	StoreNameTorBox      StoreName = "torbox"
+
+	// virtual store, backed by all the stores configured for a user
+	StoreNameMulti StoreName = "multi"
 )
 
 type StoreCode string
 
 const (
-	StoreCodeAllDebrid  StoreCode = "ad"
-	StoreCodeDebrider   StoreCode = "dr"
-	StoreCodeDebridLink StoreCode = "dl"
-	StoreCodeEasyDebrid StoreCode = "ed"
-	StoreCodeOffcloud   StoreCode = "oc"
-	StoreCodePikPak     StoreCode = "pp"
-	StoreCodePremiumize StoreCode = "pm"
-	StoreCodeRealDebrid StoreCode = "rd"
-	StoreCodeTorBox     StoreCode = "tb"
+	StoreCodeAllDebrid   StoreCode = "ad"
+	StoreCodeDebrider    StoreCode = "dr"
+	StoreCodeDebridLink  StoreCode = "dl"
+	StoreCodeEasyDebrid  StoreCode = "ed"
+	StoreCodeOffcloud    StoreCode = "oc"
+	StoreCodePikPak      StoreCode = "pp"
+	StoreCodePremiumize  StoreCode = "pm"
+	StoreCodeQBittorrent StoreCode = "qb"
+	StoreCodeRealDebrid  StoreCode = "rd"
+	StoreCodeTorBox      StoreCode = "tb"
 )
 
 var storeCodeByName = map[StoreName]StoreCode{
-	StoreNameAlldebrid:  StoreCodeAllDebrid,
-	StoreNameDebrider:   StoreCodeDebrider,
-	StoreNameDebridLink: StoreCodeDebridLink,
-	StoreNameEasyDebrid: StoreCodeEasyDebrid,
-	StoreNameOffcloud:   StoreCodeOffcloud,
-	StoreNamePikPak:     StoreCodePikPak,
-	StoreNamePremiumize: StoreCodePremiumize,
-	StoreNameRealDebrid: StoreCodeRealDebrid,
-	StoreNameTorBox:     StoreCodeTorBox,
+	StoreNameAlldebrid:   StoreCodeAllDebrid,
+	StoreNameDebrider:    StoreCodeDebrider,
+	StoreNameDebridLink:  StoreCodeDebridLink,
+	StoreNameEasyDebrid:  StoreCodeEasyDebrid,
+	StoreNameOffcloud:    StoreCodeOffcloud,
+	StoreNamePikPak:      StoreCodePikPak,
+	StoreNamePremiumize:  StoreCodePremiumize,
+	StoreNameQBittorrent: StoreCodeQBittorrent,
+	StoreNameRealDebrid:  StoreCodeRealDebrid,
+	StoreNameTorBox:      StoreCodeTorBox,
 }
 
 var storeNameByCode = map[StoreCode]StoreName{
-	StoreCodeAllDebrid:  StoreNameAlldebrid,
-	StoreCodeDebrider:   StoreNameDebrider,
-	StoreCodeDebridLink: StoreNameDebridLink,
-	StoreCodeEasyDebrid: StoreNameEasyDebrid,
-	StoreCodeOffcloud:   StoreNameOffcloud,
-	StoreCodePikPak:     StoreNamePikPak,
-	StoreCodePremiumize: StoreNamePremiumize,
-	StoreCodeRealDebrid: StoreNameRealDebrid,
-	StoreCodeTorBox:     StoreNameTorBox,
+	StoreCodeAllDebrid:   StoreNameAlldebrid,
+	StoreCodeDebrider:    StoreNameDebrider,
+	StoreCodeDebridLink:  StoreNameDebridLink,
+	StoreCodeEasyDebrid:  StoreNameEasyDebrid,
+	StoreCodeOffcloud:    StoreNameOffcloud,
+	StoreCodePikPak:      StoreNamePikPak,
+	StoreCodePremiumize:  StoreNamePremiumize,
+	StoreCodeQBittorrent: StoreNameQBittorrent,
+	StoreCodeRealDebrid:  StoreNameRealDebrid,
+	StoreCodeTorBox:      StoreNameTorBox,
 }
 
 func (sn StoreName) Code() StoreCode {
@@ -124,6 +131,16 @@ const (
 	MagnetStatusUnknown     MagnetStatus = "unknown"
 )
 
+// IsFinal reports whether the status is not expected to change anymore.
+func (s MagnetStatus) IsFinal() bool {
+	switch s {
+	case MagnetStatusDownloaded, MagnetStatusFailed, MagnetStatusInvalid:
+		return true
+	default:
+		return false
+	}
+}
+
 type CheckMagnetParams struct {
 	Ctx
 	Magnets          []string
@@ -133,11 +150,17 @@ type CheckMagnetParams struct {
 	IsTrustedRequest bool
 }
 
-type CheckMagnetDataItem struct {
-	Hash   string       `json:"hash"`
-	Magnet string       `json:"magnet"`
+type CheckMagnetDataItemStore struct {
+	Code   StoreCode    `json:"code"`
 	Status MagnetStatus `json:"status"`
-	Files  []MagnetFile `json:"files"`
+}
+
+type CheckMagnetDataItem struct {
+	Hash   string                     `json:"hash"`
+	Magnet string                     `json:"magnet"`
+	Status MagnetStatus               `json:"status"`
+	Files  []MagnetFile               `json:"files"`
+	Stores []CheckMagnetDataItemStore `json:"stores,omitempty"` // only for multi store
 }
 
 type CheckMagnetData struct {
@@ -162,13 +185,28 @@ type AddMagnetParams struct {
 }
 
 type GetMagnetData struct {
-	Id      string       `json:"id"`
-	Name    string       `json:"name"`
-	Hash    string       `json:"hash"`
-	Size    int64        `json:"size"`
-	Status  MagnetStatus `json:"status"`
-	Files   []MagnetFile `json:"files"`
-	AddedAt time.Time    `json:"added_at"`
+	Id       string       `json:"id"`
+	Name     string       `json:"name"`
+	Hash     string       `json:"hash"`
+	Size     int64        `json:"size"`
+	Status   MagnetStatus `json:"status"`
+	Files    []MagnetFile `json:"files"`
+	AddedAt  time.Time    `json:"added_at"`
+	Progress float64      `json:"progress,omitempty"` // 0 to 100
+	Speed    int64        `json:"speed,omitempty"`    // bytes per second
+	Seeders  int          `json:"seeders,omitempty"`
+	ETA      int64        `json:"eta,omitempty"` // seconds
+}
+
+// SetProgress sets the download progress, estimating the ETA from the
+// remaining size if the store does not report it.
+func (d *GetMagnetData) SetProgress(progress float64, speed int64, eta int64) {
+	d.Progress = min(max(progress, 0), 100)
+	d.Speed = speed
+	d.ETA = eta
+	if d.ETA == 0 && d.Speed > 0 && d.Size > 0 && d.Progress < 100 {
+		d.ETA = int64(float64(d.Size) * (100 - d.Progress) / 100 / float64(d.Speed))
+	}
 }
 
 type GetMagnetParams struct {
@@ -186,9 +224,17 @@ type ListMagnetsDataItem struct {
 	AddedAt time.Time    `json:"added_at"`
 }
 
+// ListMagnetsDataStoreError is the error from a member store of multi store.
+type ListMagnetsDataStoreError struct {
+	Code      StoreCode      `json:"code"`
+	ErrorCode core.ErrorCode `json:"error_code,omitempty"`
+	Message   string         `json:"message"`
+}
+
 type ListMagnetsData struct {
-	Items      []ListMagnetsDataItem `json:"items"`
-	TotalItems int                   `json:"total_items"`
+	Items      []ListMagnetsDataItem       `json:"items"`
+	TotalItems int                         `json:"total_items"`
+	Errors     []ListMagnetsDataStoreError `json:"errors,omitempty"`
 }
 
 type ListMagnetsParams struct {
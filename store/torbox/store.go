@@ -3,6 +3,7 @@ package torbox
 import (
 	"errors"
 	"net/http"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -405,12 +406,14 @@ func (c *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnet
 		Status:  store.MagnetStatusQueued,
 		Files:   []store.MagnetFile{},
 		AddedAt: res.Data.GetAddedAt(),
+		Seeders: res.Data.Seeds,
 	}
 	if res.Data.DownloadFinished && res.Data.DownloadPresent {
 		data.Status = store.MagnetStatusDownloaded
 	} else if res.Data.Progress > 0 {
 		data.Status = store.MagnetStatusDownloading
 	}
+	data.SetProgress(float64(res.Data.Progress)*100, int64(res.Data.DownloadSpeed), int64(res.Data.ETA))
 	for _, f := range res.Data.Files {
 		file := store.MagnetFile{
 			Idx:       f.Id,
@@ -488,3 +491,375 @@ func (c *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.Rem
 	data := &store.RemoveMagnetData{Id: params.Id}
 	return data, nil
 }
+
+var garbageNewsNameRegex = regexp.MustCompile(`(?i)^\[[a-z0-9]+\]\s*-\s*[a-z0-9]+$`)
+
+func getNewsStatus(und *UsenetDownload) store.NewsStatus {
+	if und.DownloadFinished && und.DownloadPresent {
+		return store.MagnetStatusDownloaded
+	}
+	if und.DownloadState == TorrentDownloadStateDownloading {
+		return store.MagnetStatusDownloading
+	}
+	return store.MagnetStatusUnknown
+}
+
+func getNewsFiles(und *UsenetDownload) (files []store.NewsFile, name string) {
+	name = und.Name
+	hasGarbageName := garbageNewsNameRegex.MatchString(name)
+	maxFileSize := int64(0)
+	files = []store.NewsFile{}
+	for i := range und.Files {
+		f := &und.Files[i]
+		file := store.NewsFile{
+			Idx:  f.Id,
+			Link: LockedFileLink("").Create(und.Id, f.Id),
+			Name: f.ShortName,
+			Path: "/" + f.Name,
+			Size: f.Size,
+		}
+		if hasGarbageName && file.Size > maxFileSize {
+			name = file.Name
+			maxFileSize = file.Size
+		}
+		files = append(files, file)
+	}
+	return files, name
+}
+
+func (c *StoreClient) AddNews(params *store.AddNewsParams) (*store.AddNewsData, error) {
+	res, err := c.client.CreateUsenetDownload(&CreateUsenetDownloadParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Name:     params.Name,
+		Password: params.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.AddNewsData{
+		Id:     strconv.Itoa(res.Data.UsenetDownloadId),
+		Hash:   res.Data.Hash,
+		Name:   params.Name,
+		Status: store.MagnetStatusQueued,
+		Files:  []store.NewsFile{},
+	}
+	und, err := c.client.GetUsenetDownload(&GetUsenetDownloadParams{
+		Ctx:         params.Ctx,
+		Id:          res.Data.UsenetDownloadId,
+		BypassCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if und.Data.Id != 0 {
+		data.Files, data.Name = getNewsFiles(&und.Data)
+		data.Size = und.Data.Size
+		data.AddedAt = und.Data.GetAddedAt()
+		if status := getNewsStatus(&und.Data); status != store.MagnetStatusUnknown {
+			data.Status = status
+		}
+	}
+	return data, nil
+}
+
+func parseNewsId(id string) (int, error) {
+	newsId, err := strconv.Atoi(id)
+	if err != nil {
+		error := core.NewStoreError("invalid id")
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return 0, error
+	}
+	return newsId, nil
+}
+
+func (c *StoreClient) GetNews(params *store.GetNewsParams) (*store.GetNewsData, error) {
+	id, err := parseNewsId(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.GetUsenetDownload(&GetUsenetDownloadParams{
+		Ctx:         params.Ctx,
+		Id:          id,
+		BypassCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Data.Id == 0 {
+		error := core.NewAPIError("not found")
+		error.StatusCode = http.StatusNotFound
+		error.StoreName = string(store.StoreNameTorBox)
+		return nil, error
+	}
+	und := &res.Data
+	data := &store.GetNewsData{
+		Id:      strconv.Itoa(und.Id),
+		Hash:    und.Hash,
+		Size:    und.Size,
+		Status:  getNewsStatus(und),
+		AddedAt: und.GetAddedAt(),
+	}
+	data.Files, data.Name = getNewsFiles(und)
+	return data, nil
+}
+
+func (c *StoreClient) ListNews(params *store.ListNewsParams) (*store.ListNewsData, error) {
+	res, err := c.client.ListUsenetDownload(&ListUsenetDownloadParams{
+		Ctx:         params.Ctx,
+		BypassCache: true,
+		Limit:       params.Limit,
+		Offset:      params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.ListNewsData{
+		Items:      []store.ListNewsDataItem{},
+		TotalItems: 0,
+	}
+	for i := range res.Data {
+		und := &res.Data[i]
+		item := store.ListNewsDataItem{
+			Id:      strconv.Itoa(und.Id),
+			Hash:    und.Hash,
+			Size:    und.Size,
+			Status:  getNewsStatus(und),
+			AddedAt: und.GetAddedAt(),
+		}
+		item.Files, item.Name = getNewsFiles(und)
+		data.Items = append(data.Items, item)
+	}
+	count := len(data.Items)
+	// torbox returns 1 extra item
+	if count > params.Limit {
+		data.Items = data.Items[0:params.Limit]
+		count = params.Limit
+	}
+	data.TotalItems = params.Offset + count
+	if count == params.Limit {
+		data.TotalItems += 1
+	}
+	return data, nil
+}
+
+func (c *StoreClient) RemoveNews(params *store.RemoveNewsParams) (*store.RemoveNewsData, error) {
+	id, err := parseNewsId(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.client.ControlUsenetDownload(&ControlUsenetDownloadParams{
+		Ctx:       params.Ctx,
+		UsenetId:  id,
+		Operation: ControlUsenetDownloadOperationDelete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveNewsData{Id: params.Id}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateNewsLink(params *store.GenerateNewsLinkParams) (*store.GenerateLinkData, error) {
+	id, fileId, err := LockedFileLink(params.Link).Parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return nil, error
+	}
+	cacheKey := params.GetAPIKey(c.client.apiKey) + ":news" + intToStr(id, fileId)
+	v := &store.GenerateLinkData{}
+	if c.generateLinkCache.Get(cacheKey, v) {
+		return v, nil
+	}
+	res, err := c.client.RequestUsenetDownloadLink(&RequestUsenetDownloadLinkParams{
+		Ctx:      params.Ctx,
+		UsenetId: id,
+		FileId:   fileId,
+		UserIP:   params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GenerateLinkData{Link: res.Data.Link}
+	c.generateLinkCache.Add(cacheKey, *data)
+	return data, nil
+}
+
+func getWebDLStatus(wdl *WebDLDownload) store.WebDLStatus {
+	if wdl.DownloadFinished && wdl.DownloadPresent {
+		return store.MagnetStatusDownloaded
+	}
+	if wdl.DownloadState == TorrentDownloadStateDownloading {
+		return store.MagnetStatusDownloading
+	}
+	return store.MagnetStatusUnknown
+}
+
+func getWebDLFiles(wdl *WebDLDownload) []store.WebDLFile {
+	files := []store.WebDLFile{}
+	for i := range wdl.Files {
+		f := &wdl.Files[i]
+		files = append(files, store.WebDLFile{
+			Idx:  f.Id,
+			Link: LockedFileLink("").Create(wdl.Id, f.Id),
+			Name: f.ShortName,
+			Path: "/" + f.Name,
+			Size: f.Size,
+		})
+	}
+	return files
+}
+
+func (c *StoreClient) AddWebDL(params *store.AddWebDLParams) (*store.AddWebDLData, error) {
+	res, err := c.client.CreateWebDLDownload(&CreateWebDLDownloadParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.AddWebDLData{
+		Id:     strconv.Itoa(res.Data.UsenetDownloadId),
+		Hash:   res.Data.Hash,
+		Link:   params.Link,
+		Status: store.MagnetStatusQueued,
+		Files:  []store.WebDLFile{},
+	}
+	wdl, err := c.client.GetWebDLDownload(&GetWebDLDownloadParams{
+		Ctx:         params.Ctx,
+		Id:          res.Data.UsenetDownloadId,
+		BypassCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if wdl.Data.Id != 0 {
+		data.Name = wdl.Data.Name
+		data.Size = wdl.Data.Size
+		data.Files = getWebDLFiles(&wdl.Data)
+		data.AddedAt = wdl.Data.GetAddedAt()
+		if status := getWebDLStatus(&wdl.Data); status != store.MagnetStatusUnknown {
+			data.Status = status
+		}
+	}
+	return data, nil
+}
+
+func (c *StoreClient) GetWebDL(params *store.GetWebDLParams) (*store.GetWebDLData, error) {
+	id, err := strconv.Atoi(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.GetWebDLDownload(&GetWebDLDownloadParams{
+		Ctx:         params.Ctx,
+		Id:          id,
+		BypassCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Data.Id == 0 {
+		error := core.NewAPIError("not found")
+		error.StatusCode = http.StatusNotFound
+		error.StoreName = string(store.StoreNameTorBox)
+		return nil, error
+	}
+	wdl := &res.Data
+	data := &store.GetWebDLData{
+		Id:      strconv.Itoa(wdl.Id),
+		Hash:    wdl.Hash,
+		Name:    wdl.Name,
+		Size:    wdl.Size,
+		Status:  getWebDLStatus(wdl),
+		Files:   getWebDLFiles(wdl),
+		AddedAt: wdl.GetAddedAt(),
+	}
+	return data, nil
+}
+
+func (c *StoreClient) ListWebDLs(params *store.ListWebDLsParams) (*store.ListWebDLsData, error) {
+	res, err := c.client.ListWebDLDownload(&ListWebDLDownloadParams{
+		Ctx:         params.Ctx,
+		BypassCache: true,
+		Limit:       params.Limit,
+		Offset:      params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.ListWebDLsData{
+		Items:      []store.ListWebDLsDataItem{},
+		TotalItems: 0,
+	}
+	for i := range res.Data {
+		wdl := &res.Data[i]
+		data.Items = append(data.Items, store.ListWebDLsDataItem{
+			Id:      strconv.Itoa(wdl.Id),
+			Hash:    wdl.Hash,
+			Name:    wdl.Name,
+			Size:    wdl.Size,
+			Status:  getWebDLStatus(wdl),
+			Files:   getWebDLFiles(wdl),
+			AddedAt: wdl.GetAddedAt(),
+		})
+	}
+	count := len(data.Items)
+	// torbox returns 1 extra item
+	if count > params.Limit {
+		data.Items = data.Items[0:params.Limit]
+		count = params.Limit
+	}
+	data.TotalItems = params.Offset + count
+	if count == params.Limit {
+		data.TotalItems += 1
+	}
+	return data, nil
+}
+
+func (c *StoreClient) RemoveWebDL(params *store.RemoveWebDLParams) (*store.RemoveWebDLData, error) {
+	id, err := strconv.Atoi(params.Id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.client.ControlWebDLDownload(&ControlWebDLDownloadParams{
+		Ctx:       params.Ctx,
+		WebDLId:   id,
+		Operation: ControlWebDLDownloadOperationDelete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveWebDLData{Id: params.Id}
+	return data, nil
+}
+
+func (c *StoreClient) GenerateWebDLLink(params *store.GenerateWebDLLinkParams) (*store.GenerateLinkData, error) {
+	id, fileId, err := LockedFileLink(params.Link).Parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return nil, error
+	}
+	cacheKey := params.GetAPIKey(c.client.apiKey) + ":webdl" + intToStr(id, fileId)
+	v := &store.GenerateLinkData{}
+	if c.generateLinkCache.Get(cacheKey, v) {
+		return v, nil
+	}
+	res, err := c.client.RequestWebDLDownloadLink(&RequestWebDLDownloadLinkParams{
+		Ctx:     params.Ctx,
+		WebDLId: id,
+		FileId:  fileId,
+		UserIP:  params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GenerateLinkData{Link: res.Data.Link}
+	c.generateLinkCache.Add(cacheKey, *data)
+	return data, nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"time"
+)
+
+type WebDLFile struct {
+	Idx       int    `json:"index"`
+	Link      string `json:"link,omitempty"`
+	Name      string `json:"name"`
+	Path      string `json:"path,omitempty"`
+	Size      int64  `json:"size"`
+	VideoHash string `json:"video_hash,omitempty"`
+}
+
+type WebDLStatus = MagnetStatus
+
+type AddWebDLData struct {
+	Id      string      `json:"id"`
+	Hash    string      `json:"hash"`
+	Link    string      `json:"link"`
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Status  WebDLStatus `json:"status"`
+	Files   []WebDLFile `json:"files"`
+	AddedAt time.Time   `json:"added_at"`
+}
+
+type AddWebDLParams struct {
+	Ctx
+	Link     string // hoster link
+	Password string
+	ClientIP string
+}
+
+type GetWebDLData struct {
+	Id      string      `json:"id"`
+	Hash    string      `json:"hash"`
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Status  WebDLStatus `json:"status"`
+	Files   []WebDLFile `json:"files"`
+	AddedAt time.Time   `json:"added_at"`
+}
+
+type GetWebDLParams struct {
+	Ctx
+	Id       string
+	ClientIP string
+}
+
+type ListWebDLsDataItem struct {
+	Id      string      `json:"id"`
+	Hash    string      `json:"hash"`
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Status  WebDLStatus `json:"status"`
+	Files   []WebDLFile `json:"files,omitempty"`
+	AddedAt time.Time   `json:"added_at"`
+}
+
+type ListWebDLsData struct {
+	Items      []ListWebDLsDataItem `json:"items"`
+	TotalItems int                  `json:"total_items"`
+}
+
+type ListWebDLsParams struct {
+	Ctx
+	Limit    int // min 1, max 500, default 100
+	Offset   int // default 0
+	ClientIP string
+}
+
+type RemoveWebDLData struct {
+	Id string `json:"id"`
+}
+
+type RemoveWebDLParams struct {
+	Ctx
+	Id string
+}
+
+type GenerateWebDLLinkParams struct {
+	Ctx
+	Link     string
+	ClientIP string
+}
+
+// WebDLStore is implemented by stores that can unrestrict hoster links.
+type WebDLStore interface {
+	Store
+	AddWebDL(params *AddWebDLParams) (*AddWebDLData, error)
+	GetWebDL(params *GetWebDLParams) (*GetWebDLData, error)
+	ListWebDLs(params *ListWebDLsParams) (*ListWebDLsData, error)
+	RemoveWebDL(params *RemoveWebDLParams) (*RemoveWebDLData, error)
+	GenerateWebDLLink(params *GenerateWebDLLinkParams) (*GenerateLinkData, error)
+}